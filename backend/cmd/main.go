@@ -2,11 +2,14 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"go-moneyku/internal/app"
 	"go-moneyku/internal/config"
 	"go-moneyku/internal/database"
+	"go-moneyku/internal/domain"
 	"go-moneyku/internal/handler"
+	"go-moneyku/internal/rates"
 	"go-moneyku/internal/repository"
 	"go-moneyku/internal/service"
 	"go-moneyku/internal/utils"
@@ -21,6 +24,7 @@ func main() {
 
 	// Set JWT secret
 	utils.SetJWTSecret(cfg.JWT.Secret)
+	utils.SetAccessTokenTTL(cfg.JWT.AccessTTL)
 
 	// Connect to database
 	db, err := database.Connect(cfg.GetDSN())
@@ -29,17 +33,65 @@ func main() {
 	}
 	defer database.Close(db)
 
-	// Initialize repositories
-	userRepo := repository.NewUserRepository(db)
-	walletRepo := repository.NewWalletRepository(db)
-	transactionRepo := repository.NewTransactionRepository(db)
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	// Subcommands such as "migrate up" run instead of the server
+	if len(os.Args) > 1 {
+		if os.Args[1] != "migrate" {
+			log.Fatalf("Unknown command %q (use migrate)", os.Args[1])
+		}
+		if err := runMigrate(migrator, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	if err := checkMigrations(migrator, cfg.Database.AutoMigrate); err != nil {
+		log.Fatalf("Database schema is not up to date: %v", err)
+	}
+
+	// Initialize repositories; every query is bounded by DB_QUERY_TIMEOUT
+	queryDB := repository.WithQueryTimeout(db, cfg.Database.QueryTimeout)
+	userRepo := repository.NewUserRepository(queryDB)
+	walletRepo := repository.NewWalletRepository(queryDB)
+	transactionRepo := repository.NewTransactionRepository(queryDB)
+	ledgerRepo := repository.NewLedgerRepository(queryDB)
+	recurringRepo := repository.NewRecurringRuleRepository(queryDB)
+	categoryRepo := repository.NewCategoryRepository(queryDB)
+	budgetRepo := repository.NewBudgetRepository(queryDB)
+	goalRepo := repository.NewGoalRepository(queryDB)
+	importRepo := repository.NewImportRepository(queryDB)
+	exchangeRateRepo := repository.NewExchangeRateRepository(queryDB)
+	walletTypeRepo := repository.NewWalletTypeRepository(queryDB)
+	installmentRepo := repository.NewInstallmentRepository(queryDB)
+	sessionRepo := repository.NewSessionRepository(queryDB)
+	unitOfWork := repository.NewUnitOfWork(db, cfg.Database.QueryTimeout)
+
+	// Exchange rates are synced from a file when RATES_FILE is set
+	var rateProvider domain.RateProvider
+	if cfg.Rates.File != "" {
+		rateProvider = rates.NewFileProvider(cfg.Rates.File)
+	}
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo)
-	walletService := service.NewWalletService(walletRepo, transactionRepo)
-	transactionService := service.NewTransactionService(transactionRepo, walletRepo)
-	dashboardService := service.NewDashboardService(walletRepo, transactionRepo)
-	reportService := service.NewReportService(transactionRepo, walletRepo)
+	authService := service.NewAuthService(userRepo, sessionRepo, unitOfWork, cfg.JWT.RefreshTTL)
+	exchangeRateService := service.NewExchangeRateService(exchangeRateRepo, userRepo, rateProvider)
+	walletTypeService := service.NewWalletTypeService(walletTypeRepo)
+	walletService := service.NewWalletService(walletRepo, transactionRepo, exchangeRateService, walletTypeService, unitOfWork)
+	budgetService := service.NewBudgetService(budgetRepo, transactionRepo, categoryRepo, exchangeRateService)
+	transactionService := service.NewTransactionService(transactionRepo, walletRepo, budgetService, exchangeRateService, unitOfWork)
+	dashboardService := service.NewDashboardService(walletRepo, transactionRepo, categoryRepo, exchangeRateService, walletTypeService)
+	reportService := service.NewReportService(transactionRepo, walletRepo, categoryRepo, ledgerRepo, exchangeRateService, cfg.Database.ExportTimeout)
+	ledgerService := service.NewLedgerService(ledgerRepo)
+	recurringService := service.NewRecurringService(recurringRepo, walletRepo, transactionService, unitOfWork)
+	categoryService := service.NewCategoryService(categoryRepo)
+	goalService := service.NewGoalService(goalRepo, walletRepo, ledgerRepo, unitOfWork)
+	importService := service.NewImportService(walletRepo, ledgerRepo, importRepo, transactionService, unitOfWork)
+	creditCardService := service.NewCreditCardService(walletRepo, ledgerRepo, transactionService)
+	installmentService := service.NewInstallmentService(installmentRepo, walletRepo, transactionService, unitOfWork)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService)
@@ -47,18 +99,42 @@ func main() {
 	transactionHandler := handler.NewTransactionHandler(transactionService)
 	dashboardHandler := handler.NewDashboardHandler(dashboardService)
 	reportHandler := handler.NewReportHandler(reportService)
+	ledgerHandler := handler.NewLedgerHandler(ledgerService)
+	recurringHandler := handler.NewRecurringHandler(recurringService)
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+	budgetHandler := handler.NewBudgetHandler(budgetService)
+	goalHandler := handler.NewGoalHandler(goalService)
+	importHandler := handler.NewImportHandler(importService)
+	exchangeRateHandler := handler.NewExchangeRateHandler(exchangeRateService)
+	walletTypeHandler := handler.NewWalletTypeHandler(walletTypeService)
+	creditCardHandler := handler.NewCreditCardHandler(creditCardService)
+	installmentHandler := handler.NewInstallmentHandler(installmentService)
 
 	// Setup router
 	router := app.NewRouter(
+		authService,
 		authHandler,
 		walletHandler,
 		transactionHandler,
 		dashboardHandler,
 		reportHandler,
+		ledgerHandler,
+		recurringHandler,
+		categoryHandler,
+		budgetHandler,
+		goalHandler,
+		importHandler,
+		exchangeRateHandler,
+		walletTypeHandler,
+		creditCardHandler,
+		installmentHandler,
 	)
 
+	// Background jobs
+	scheduler := service.NewScheduler(cfg.Scheduler.Interval, recurringService, installmentService, exchangeRateService, authService)
+
 	// Create and start server
-	server := app.NewServer(router.Setup(), cfg.Server.Port)
+	server := app.NewServer(router.Setup(), cfg.Server.Port, scheduler)
 	if err := server.Start(); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"go-moneyku/internal/database"
+)
+
+// runMigrate handles "migrate up", "migrate down [steps]" and "migrate status"
+func runMigrate(migrator *database.Migrator, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up | down [steps] | status")
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied %d migrations", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("steps must be a positive number")
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("Reverted %d migrations", reverted)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, applied)
+		}
+
+	default:
+		return fmt.Errorf("unknown migrate command %q (use up, down or status)", args[0])
+	}
+
+	return nil
+}
+
+// checkMigrations applies pending migrations when auto-migrate is on, and
+// otherwise fails if the schema is behind the binary
+func checkMigrations(migrator *database.Migrator, autoMigrate bool) error {
+	ctx := context.Background()
+	if autoMigrate {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			log.Printf("Applied %d migrations", applied)
+		}
+		return nil
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return fmt.Errorf("database has %d pending migrations, run \"migrate up\" or set DB_AUTO_MIGRATE=true", pending)
+	}
+	return nil
+}
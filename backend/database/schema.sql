@@ -1,58 +0,0 @@
--- Money Management Database Schema
--- PostgreSQL
-
--- Create database (run this separately if needed)
--- CREATE DATABASE db_moneyku;
-
--- Users table
-CREATE TABLE IF NOT EXISTS users (
-    id SERIAL PRIMARY KEY,
-    username VARCHAR(255) UNIQUE NOT NULL,
-    password VARCHAR(255) NOT NULL,
-    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-);
-
--- Wallets table
-CREATE TABLE IF NOT EXISTS wallets (
-    id SERIAL PRIMARY KEY,
-    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-    name VARCHAR(255) NOT NULL,
-    balance DECIMAL(15, 2) NOT NULL DEFAULT 0,
-    currency VARCHAR(10) NOT NULL DEFAULT 'IDR',
-    type VARCHAR(50),
-    icon VARCHAR(50),
-    color VARCHAR(50),
-    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-);
-
--- Transactions table
-CREATE TABLE IF NOT EXISTS transactions (
-    id SERIAL PRIMARY KEY,
-    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-    wallet_id INTEGER NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
-    type VARCHAR(20) NOT NULL CHECK (type IN ('income', 'expense', 'transfer')),
-    amount DECIMAL(15, 2) NOT NULL,
-    category VARCHAR(100),
-    description TEXT,
-    date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    to_wallet_id INTEGER REFERENCES wallets(id) ON DELETE SET NULL,
-    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-);
-
--- Indexes for better query performance
-CREATE INDEX IF NOT EXISTS idx_wallets_user_id ON wallets(user_id);
-CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
-CREATE INDEX IF NOT EXISTS idx_transactions_wallet_id ON transactions(wallet_id);
-CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(date);
-CREATE INDEX IF NOT EXISTS idx_transactions_type ON transactions(type);
-
--- Comments for documentation
-COMMENT ON TABLE users IS 'Stores user account information';
-COMMENT ON TABLE wallets IS 'Stores user wallets/accounts';
-COMMENT ON TABLE transactions IS 'Stores all financial transactions';
-
-COMMENT ON COLUMN transactions.type IS 'Type of transaction: income, expense, or transfer';
-COMMENT ON COLUMN transactions.to_wallet_id IS 'Destination wallet for transfer transactions';
@@ -8,26 +8,59 @@ import (
 )
 
 type Router struct {
-	authHandler        *handler.AuthHandler
-	walletHandler      *handler.WalletHandler
-	transactionHandler *handler.TransactionHandler
-	dashboardHandler   *handler.DashboardHandler
-	reportHandler      *handler.ReportHandler
+	sessions            middleware.SessionChecker
+	authHandler         *handler.AuthHandler
+	walletHandler       *handler.WalletHandler
+	transactionHandler  *handler.TransactionHandler
+	dashboardHandler    *handler.DashboardHandler
+	reportHandler       *handler.ReportHandler
+	ledgerHandler       *handler.LedgerHandler
+	recurringHandler    *handler.RecurringHandler
+	categoryHandler     *handler.CategoryHandler
+	budgetHandler       *handler.BudgetHandler
+	goalHandler         *handler.GoalHandler
+	importHandler       *handler.ImportHandler
+	exchangeRateHandler *handler.ExchangeRateHandler
+	walletTypeHandler   *handler.WalletTypeHandler
+	creditCardHandler   *handler.CreditCardHandler
+	installmentHandler  *handler.InstallmentHandler
 }
 
 func NewRouter(
+	sessions middleware.SessionChecker,
 	authHandler *handler.AuthHandler,
 	walletHandler *handler.WalletHandler,
 	transactionHandler *handler.TransactionHandler,
 	dashboardHandler *handler.DashboardHandler,
 	reportHandler *handler.ReportHandler,
+	ledgerHandler *handler.LedgerHandler,
+	recurringHandler *handler.RecurringHandler,
+	categoryHandler *handler.CategoryHandler,
+	budgetHandler *handler.BudgetHandler,
+	goalHandler *handler.GoalHandler,
+	importHandler *handler.ImportHandler,
+	exchangeRateHandler *handler.ExchangeRateHandler,
+	walletTypeHandler *handler.WalletTypeHandler,
+	creditCardHandler *handler.CreditCardHandler,
+	installmentHandler *handler.InstallmentHandler,
 ) *Router {
 	return &Router{
-		authHandler:        authHandler,
-		walletHandler:      walletHandler,
-		transactionHandler: transactionHandler,
-		dashboardHandler:   dashboardHandler,
-		reportHandler:      reportHandler,
+		sessions:            sessions,
+		authHandler:         authHandler,
+		walletHandler:       walletHandler,
+		transactionHandler:  transactionHandler,
+		dashboardHandler:    dashboardHandler,
+		reportHandler:       reportHandler,
+		ledgerHandler:       ledgerHandler,
+		recurringHandler:    recurringHandler,
+		categoryHandler:     categoryHandler,
+		budgetHandler:       budgetHandler,
+		goalHandler:         goalHandler,
+		importHandler:       importHandler,
+		exchangeRateHandler: exchangeRateHandler,
+		walletTypeHandler:   walletTypeHandler,
+		creditCardHandler:   creditCardHandler,
+		installmentHandler:  installmentHandler,
 	}
 }
 
@@ -45,14 +78,18 @@ func (r *Router) Setup() *gin.Engine {
 		{
 			auth.POST("/login", r.authHandler.Login)
 			auth.POST("/signup", r.authHandler.Signup)
+			auth.POST("/refresh", r.authHandler.Refresh)
 		}
 
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(r.sessions))
 		{
 			// Auth routes (protected)
 			protected.GET("/auth/me", r.authHandler.GetCurrentUser)
+			protected.PUT("/auth/me", r.authHandler.UpdateCurrentUser)
+			protected.POST("/auth/logout", r.authHandler.Logout)
+			protected.POST("/auth/logout-all", r.authHandler.LogoutAll)
 
 			// Wallet routes
 			wallets := protected.Group("/wallets")
@@ -62,6 +99,17 @@ func (r *Router) Setup() *gin.Engine {
 				wallets.GET("/:id", r.walletHandler.GetWallet)
 				wallets.PUT("/:id", r.walletHandler.UpdateWallet)
 				wallets.DELETE("/:id", r.walletHandler.DeleteWallet)
+				wallets.GET("/:id/billing-cycle", r.creditCardHandler.GetBillingCycle)
+				wallets.POST("/:id/pay", r.creditCardHandler.PayCard)
+			}
+
+			// Wallet type routes
+			walletTypes := protected.Group("/wallet-types")
+			{
+				walletTypes.POST("", r.walletTypeHandler.CreateWalletType)
+				walletTypes.GET("", r.walletTypeHandler.GetWalletTypes)
+				walletTypes.PUT("/:id", r.walletTypeHandler.UpdateWalletType)
+				walletTypes.DELETE("/:id", r.walletTypeHandler.DeleteWalletType)
 			}
 
 			// Transaction routes
@@ -69,9 +117,81 @@ func (r *Router) Setup() *gin.Engine {
 			{
 				transactions.POST("", r.transactionHandler.CreateTransaction)
 				transactions.GET("", r.transactionHandler.GetTransactions)
+				transactions.PUT("/:id", r.transactionHandler.UpdateTransaction)
+				transactions.PATCH("/:id", r.transactionHandler.UpdateTransaction)
 				transactions.DELETE("/:id", r.transactionHandler.DeleteTransaction)
 			}
 
+			// Import routes
+			imports := protected.Group("/import")
+			{
+				imports.POST("/csv", r.importHandler.ImportCSV)
+				imports.POST("/:format", r.importHandler.ImportStatement)
+			}
+
+			// Category routes
+			categories := protected.Group("/categories")
+			{
+				categories.POST("", r.categoryHandler.CreateCategory)
+				categories.GET("", r.categoryHandler.GetCategories)
+				categories.GET("/:id", r.categoryHandler.GetCategory)
+				categories.PUT("/:id", r.categoryHandler.UpdateCategory)
+				categories.DELETE("/:id", r.categoryHandler.DeleteCategory)
+			}
+
+			// Budget routes
+			budgets := protected.Group("/budgets")
+			{
+				budgets.POST("", r.budgetHandler.CreateBudget)
+				budgets.GET("", r.budgetHandler.GetBudgets)
+				budgets.GET("/:id", r.budgetHandler.GetBudget)
+				budgets.PUT("/:id", r.budgetHandler.UpdateBudget)
+				budgets.DELETE("/:id", r.budgetHandler.DeleteBudget)
+			}
+
+			// Savings goal routes
+			goals := protected.Group("/goals")
+			{
+				goals.POST("", r.goalHandler.CreateGoal)
+				goals.GET("", r.goalHandler.GetGoals)
+				goals.GET("/:id", r.goalHandler.GetGoal)
+				goals.PUT("/:id", r.goalHandler.UpdateGoal)
+				goals.DELETE("/:id", r.goalHandler.DeleteGoal)
+				goals.GET("/:id/contributions", r.goalHandler.GetContributions)
+				goals.POST("/:id/contributions", r.goalHandler.AddContribution)
+				goals.DELETE("/:id/contributions/:contributionId", r.goalHandler.DeleteContribution)
+			}
+
+			// Recurring transaction routes
+			recurring := protected.Group("/recurring")
+			{
+				recurring.POST("", r.recurringHandler.CreateRule)
+				recurring.GET("", r.recurringHandler.GetRules)
+				recurring.GET("/:id", r.recurringHandler.GetRule)
+				recurring.GET("/:id/occurrences", r.recurringHandler.GetOccurrences)
+				recurring.PUT("/:id", r.recurringHandler.UpdateRule)
+				recurring.DELETE("/:id", r.recurringHandler.DeleteRule)
+			}
+
+			// Installment routes
+			installments := protected.Group("/installments")
+			{
+				installments.POST("", r.installmentHandler.CreatePlan)
+				installments.GET("", r.installmentHandler.GetPlans)
+				installments.GET("/:id", r.installmentHandler.GetPlan)
+				installments.POST("/:id/payoff", r.installmentHandler.PayOff)
+				installments.POST("/:id/resume", r.installmentHandler.Resume)
+				installments.DELETE("/:id", r.installmentHandler.DeletePlan)
+			}
+
+			// Exchange rate routes
+			exchangeRates := protected.Group("/exchange-rates")
+			{
+				exchangeRates.POST("", r.exchangeRateHandler.CreateRate)
+				exchangeRates.GET("", r.exchangeRateHandler.GetRates)
+				exchangeRates.DELETE("/:id", r.exchangeRateHandler.DeleteRate)
+			}
+
 			// Dashboard routes
 			dashboard := protected.Group("/dashboard")
 			{
@@ -84,6 +204,13 @@ func (r *Router) Setup() *gin.Engine {
 			{
 				reports.GET("/transactions", r.reportHandler.GetTransactionReport)
 				reports.GET("/export", r.reportHandler.ExportTransactions)
+				reports.GET("/statement", r.reportHandler.GetWalletStatement)
+			}
+
+			// Ledger routes
+			ledger := protected.Group("/ledger")
+			{
+				ledger.GET("/reconcile", r.ledgerHandler.Reconcile)
 			}
 		}
 	}
@@ -4,31 +4,62 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Worker is a background task that runs alongside the HTTP server until its
+// context is cancelled on shutdown
+type Worker interface {
+	Run(ctx context.Context)
+}
+
 type Server struct {
-	router *gin.Engine
-	port   string
+	router  *gin.Engine
+	port    string
+	workers []Worker
 }
 
-func NewServer(router *gin.Engine, port string) *Server {
+func NewServer(router *gin.Engine, port string, workers ...Worker) *Server {
 	return &Server{
-		router: router,
-		port:   port,
+		router:  router,
+		port:    port,
+		workers: workers,
 	}
 }
 
 func (s *Server) Start() error {
+	// Every request context derives from baseCtx, so cancelling it aborts the
+	// queries of requests still running when shutdown gives up on them
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%s", s.port),
 		Handler: s.router,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
+	}
+
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var wg sync.WaitGroup
+	for _, worker := range s.workers {
+		wg.Add(1)
+		go func(worker Worker) {
+			defer wg.Done()
+			worker.Run(workerCtx)
+		}(worker)
 	}
 
 	// Start server in a goroutine
@@ -46,12 +77,20 @@ func (s *Server) Start() error {
 
 	log.Println("Shutting down server...")
 
-	// Give the server 5 seconds to finish ongoing requests
+	// Stop background workers; cancelling their context aborts the current run
+	stopWorkers()
+
+	// Give the server 5 seconds to finish ongoing requests, then cancel the
+	// ones that are left
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		return fmt.Errorf("server forced to shutdown: %w", err)
+	shutdownErr := srv.Shutdown(ctx)
+	cancelRequests()
+	wg.Wait()
+
+	if shutdownErr != nil {
+		return fmt.Errorf("server forced to shutdown: %w", shutdownErr)
 	}
 
 	log.Println("Server exited")
@@ -3,16 +3,20 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	JWT      JWTConfig
-	RawDSN   string // If provided via DB_URL or DATABASE_URL
+	Database  DatabaseConfig
+	Server    ServerConfig
+	JWT       JWTConfig
+	Scheduler SchedulerConfig
+	Rates     RatesConfig
+	RawDSN    string // If provided via DB_URL or DATABASE_URL
 }
 
 type DatabaseConfig struct {
@@ -21,6 +25,14 @@ type DatabaseConfig struct {
 	User     string
 	Password string
 	DBName   string
+	// AutoMigrate applies pending migrations on startup. Without it the
+	// server refuses to start until "migrate up" has been run.
+	AutoMigrate bool
+	// QueryTimeout bounds every query; zero leaves only the request deadline
+	QueryTimeout time.Duration
+	// ExportTimeout bounds a whole export download, which streams rows for
+	// longer than QueryTimeout allows; zero leaves only the request deadline
+	ExportTimeout time.Duration
 }
 
 type ServerConfig struct {
@@ -28,7 +40,17 @@ type ServerConfig struct {
 }
 
 type JWTConfig struct {
-	Secret string
+	Secret     string
+	AccessTTL  time.Duration // How long an access token is valid
+	RefreshTTL time.Duration // How long a session can go without a refresh
+}
+
+type SchedulerConfig struct {
+	Interval time.Duration
+}
+
+type RatesConfig struct {
+	File string // CSV file of exchange rates synced by the scheduler; empty disables the sync
 }
 
 // Load loads configuration from environment variables
@@ -44,20 +66,61 @@ func Load() (*Config, error) {
 		rawDSN = strings.TrimSpace(os.Getenv("DB_URL"))
 	}
 
+	schedulerInterval, err := time.ParseDuration(getEnv("SCHEDULER_INTERVAL", "1h"))
+	if err != nil || schedulerInterval <= 0 {
+		return nil, fmt.Errorf("invalid SCHEDULER_INTERVAL: must be a positive duration such as 1h or 30m")
+	}
+
+	autoMigrate, err := strconv.ParseBool(getEnv("DB_AUTO_MIGRATE", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_AUTO_MIGRATE: must be true or false")
+	}
+
+	queryTimeout, err := time.ParseDuration(getEnv("DB_QUERY_TIMEOUT", "30s"))
+	if err != nil || queryTimeout < 0 {
+		return nil, fmt.Errorf("invalid DB_QUERY_TIMEOUT: must be a duration such as 30s, or 0 to disable")
+	}
+
+	accessTTL, err := time.ParseDuration(getEnv("JWT_ACCESS_TTL", "15m"))
+	if err != nil || accessTTL <= 0 {
+		return nil, fmt.Errorf("invalid JWT_ACCESS_TTL: must be a positive duration such as 15m")
+	}
+
+	refreshTTL, err := time.ParseDuration(getEnv("JWT_REFRESH_TTL", "720h"))
+	if err != nil || refreshTTL <= accessTTL {
+		return nil, fmt.Errorf("invalid JWT_REFRESH_TTL: must be a duration longer than JWT_ACCESS_TTL, such as 720h")
+	}
+
+	exportTimeout, err := time.ParseDuration(getEnv("DB_EXPORT_TIMEOUT", "10m"))
+	if err != nil || exportTimeout < 0 {
+		return nil, fmt.Errorf("invalid DB_EXPORT_TIMEOUT: must be a duration such as 10m, or 0 to disable")
+	}
+
 	config := &Config{
 		RawDSN: rawDSN,
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "127.0.0.1"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", ""),
-			DBName:   getEnv("DB_NAME", "db_moneyku"),
+			Host:          getEnv("DB_HOST", "127.0.0.1"),
+			Port:          getEnv("DB_PORT", "5432"),
+			User:          getEnv("DB_USER", "postgres"),
+			Password:      getEnv("DB_PASSWORD", ""),
+			DBName:        getEnv("DB_NAME", "db_moneyku"),
+			AutoMigrate:   autoMigrate,
+			QueryTimeout:  queryTimeout,
+			ExportTimeout: exportTimeout,
 		},
 		Server: ServerConfig{
 			Port: getEnv("SERVER_PORT", "8080"),
 		},
 		JWT: JWTConfig{
-			Secret: getEnv("JWT_SECRET", "your-secret-key-change-this-in-production"),
+			Secret:     getEnv("JWT_SECRET", "your-secret-key-change-this-in-production"),
+			AccessTTL:  accessTTL,
+			RefreshTTL: refreshTTL,
+		},
+		Scheduler: SchedulerConfig{
+			Interval: schedulerInterval,
+		},
+		Rates: RatesConfig{
+			File: getEnv("RATES_FILE", ""),
 		},
 	}
 
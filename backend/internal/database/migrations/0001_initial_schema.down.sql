@@ -0,0 +1,18 @@
+-- Drops every table of the initial schema, and all data with it
+DROP TABLE IF EXISTS wallet_types;
+DROP TABLE IF EXISTS exchange_rates;
+DROP TABLE IF EXISTS imported_entries;
+DROP TABLE IF EXISTS goal_contributions;
+DROP TABLE IF EXISTS goal_wallets;
+DROP TABLE IF EXISTS goals;
+DROP TABLE IF EXISTS budgets;
+DROP TABLE IF EXISTS installment_charges;
+DROP TABLE IF EXISTS installment_plans;
+DROP TABLE IF EXISTS recurring_occurrences;
+DROP TABLE IF EXISTS recurring_rules;
+DROP TABLE IF EXISTS ledger_postings;
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS wallets;
+DROP TABLE IF EXISTS users;
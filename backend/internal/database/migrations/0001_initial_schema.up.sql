@@ -0,0 +1,381 @@
+-- Money Management Database Schema
+-- PostgreSQL
+
+-- The initial schema. Every statement is safe to run on a database that was
+-- set up by hand from the old database/schema.sql, so such databases can
+-- adopt the migrations by running them.
+
+-- Users table
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(255) UNIQUE NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    base_currency VARCHAR(10) NOT NULL DEFAULT 'IDR',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS base_currency VARCHAR(10) NOT NULL DEFAULT 'IDR';
+
+-- Wallets table
+CREATE TABLE IF NOT EXISTS wallets (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    balance DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    currency VARCHAR(10) NOT NULL DEFAULT 'IDR',
+    type VARCHAR(50),
+    account_class VARCHAR(20) NOT NULL DEFAULT 'asset' CHECK (account_class IN ('asset', 'liability')),
+    credit_limit DECIMAL(15, 2) CHECK (credit_limit >= 0),
+    statement_day SMALLINT CHECK (statement_day BETWEEN 1 AND 31),
+    due_day SMALLINT CHECK (due_day BETWEEN 1 AND 31),
+    icon VARCHAR(50),
+    color VARCHAR(50),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Credit cards, PayLater and loans
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS account_class VARCHAR(20) NOT NULL DEFAULT 'asset' CHECK (account_class IN ('asset', 'liability'));
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS credit_limit DECIMAL(15, 2) CHECK (credit_limit >= 0);
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS statement_day SMALLINT CHECK (statement_day BETWEEN 1 AND 31);
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS due_day SMALLINT CHECK (due_day BETWEEN 1 AND 31);
+
+-- Categories table
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id INTEGER REFERENCES categories(id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('income', 'expense')),
+    icon VARCHAR(50) NOT NULL DEFAULT '',
+    color VARCHAR(20) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Transactions table
+CREATE TABLE IF NOT EXISTS transactions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id INTEGER NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('income', 'expense', 'transfer')),
+    amount DECIMAL(15, 2) NOT NULL,
+    category VARCHAR(100),
+    category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+    description TEXT,
+    date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    to_wallet_id INTEGER REFERENCES wallets(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Databases created before categories existed
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL;
+
+-- Transfers between currencies and the fees charged on transfers
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS to_amount DECIMAL(15, 2) CHECK (to_amount > 0);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_of_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL;
+
+-- Journal entries table (double-entry ledger)
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id INTEGER UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('income', 'expense', 'transfer', 'adjustment')),
+    memo TEXT NOT NULL DEFAULT '',
+    date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Ledger postings table
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id SERIAL PRIMARY KEY,
+    entry_id INTEGER NOT NULL REFERENCES journal_entries(id) ON DELETE CASCADE,
+    wallet_id INTEGER REFERENCES wallets(id) ON DELETE CASCADE,
+    account VARCHAR(150) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL
+);
+
+-- Recurring transaction rules table
+CREATE TABLE IF NOT EXISTS recurring_rules (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id INTEGER NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    to_wallet_id INTEGER REFERENCES wallets(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('income', 'expense', 'transfer')),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    frequency VARCHAR(20) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'cron')),
+    day_of_week INTEGER CHECK (day_of_week BETWEEN 0 AND 6),
+    day_of_month INTEGER CHECK (day_of_month BETWEEN 1 AND 31),
+    cron_expression VARCHAR(100) NOT NULL DEFAULT '',
+    start_date DATE NOT NULL,
+    end_date DATE,
+    max_occurrences INTEGER CHECK (max_occurrences > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Generated occurrences of recurring rules
+CREATE TABLE IF NOT EXISTS recurring_occurrences (
+    id SERIAL PRIMARY KEY,
+    rule_id INTEGER NOT NULL REFERENCES recurring_rules(id) ON DELETE CASCADE,
+    occurrence_date DATE NOT NULL,
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (rule_id, occurrence_date)
+);
+
+-- Installment (cicilan) plans
+CREATE TABLE IF NOT EXISTS installment_plans (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id INTEGER NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    principal DECIMAL(15, 2) NOT NULL CHECK (principal > 0),
+    months INTEGER NOT NULL CHECK (months > 0),
+    interest_rate NUMERIC(24, 10) CHECK (interest_rate > 0),
+    admin_fee DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (admin_fee >= 0),
+    first_billing_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'paid_off')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Instalments that have been charged
+CREATE TABLE IF NOT EXISTS installment_charges (
+    id SERIAL PRIMARY KEY,
+    plan_id INTEGER NOT NULL REFERENCES installment_plans(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL CHECK (number > 0),
+    due_date DATE NOT NULL,
+    principal DECIMAL(15, 2) NOT NULL,
+    interest DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    fee DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    payoff BOOLEAN NOT NULL DEFAULT FALSE,
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (plan_id, number)
+);
+
+-- Budgets table
+CREATE TABLE IF NOT EXISTS budgets (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    period VARCHAR(20) NOT NULL CHECK (period IN ('monthly', 'weekly', 'custom')),
+    start_day INTEGER NOT NULL DEFAULT 1,
+    period_days INTEGER CHECK (period_days > 0),
+    start_date DATE NOT NULL,
+    rollover BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Savings goals table
+CREATE TABLE IF NOT EXISTS goals (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    target_amount DECIMAL(15, 2) NOT NULL CHECK (target_amount > 0),
+    deadline DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Wallets saving toward a goal; a wallet belongs to at most one goal
+CREATE TABLE IF NOT EXISTS goal_wallets (
+    goal_id INTEGER NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    wallet_id INTEGER NOT NULL UNIQUE REFERENCES wallets(id) ON DELETE CASCADE,
+    PRIMARY KEY (goal_id, wallet_id)
+);
+
+-- Manual contributions to goals
+CREATE TABLE IF NOT EXISTS goal_contributions (
+    id SERIAL PRIMARY KEY,
+    goal_id INTEGER NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount <> 0),
+    date DATE NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Bank statement entries already imported into a wallet. Deleting the
+-- transaction releases the entry so it can be imported again.
+CREATE TABLE IF NOT EXISTS imported_entries (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    external_id VARCHAR(255) NOT NULL,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (wallet_id, external_id)
+);
+
+-- Rates without a user come from the configured rate provider and are shared;
+-- a user's own rate wins over a shared one for the same pair and date
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    base_currency VARCHAR(10) NOT NULL,
+    quote_currency VARCHAR(10) NOT NULL,
+    date DATE NOT NULL,
+    rate NUMERIC(24, 10) NOT NULL CHECK (rate > 0),
+    source VARCHAR(50) NOT NULL DEFAULT 'manual',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Wallet types without a user are system types; a user type with the same
+-- key replaces the system one for that user
+CREATE TABLE IF NOT EXISTS wallet_types (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    account_class VARCHAR(20) NOT NULL DEFAULT 'asset' CHECK (account_class IN ('asset', 'liability')),
+    include_in_net_worth BOOLEAN NOT NULL DEFAULT TRUE,
+    display_group VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_wallets_user_id ON wallets(user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_wallet_id ON transactions(wallet_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(date);
+CREATE INDEX IF NOT EXISTS idx_transactions_type ON transactions(type);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_date_id ON transactions(user_id, date DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_user_id ON journal_entries(user_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_entry_id ON ledger_postings(entry_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_wallet_id ON ledger_postings(wallet_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_rules_user_id ON recurring_rules(user_id);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_category_id ON transactions(category_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_fee_of_id ON transactions(fee_of_id);
+CREATE INDEX IF NOT EXISTS idx_budgets_user_id ON budgets(user_id);
+CREATE INDEX IF NOT EXISTS idx_installment_plans_user_id ON installment_plans(user_id);
+CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals(user_id);
+CREATE INDEX IF NOT EXISTS idx_goal_contributions_goal_id ON goal_contributions(goal_id);
+CREATE INDEX IF NOT EXISTS idx_imported_entries_transaction_id ON imported_entries(transaction_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_user_type_name ON categories(user_id, type, LOWER(name));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_rates_pair_date ON exchange_rates(COALESCE(user_id, 0), base_currency, quote_currency, date);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_types_user_key ON wallet_types(COALESCE(user_id, 0), key);
+
+-- Comments for documentation
+COMMENT ON TABLE users IS 'Stores user account information';
+COMMENT ON TABLE wallets IS 'Stores user wallets/accounts';
+COMMENT ON TABLE transactions IS 'Stores all financial transactions';
+COMMENT ON TABLE journal_entries IS 'Balanced ledger bookings, one per transaction or balance adjustment';
+COMMENT ON TABLE categories IS 'Per-user income and expense categories, optionally nested under a parent';
+COMMENT ON TABLE budgets IS 'Spending limits per period for one category tree, or overall when category_id is NULL';
+COMMENT ON TABLE goals IS 'Savings targets; progress is the linked wallet balances plus contributions';
+COMMENT ON TABLE goal_contributions IS 'Money set aside for a goal outside its linked wallets; negative amounts are withdrawals';
+COMMENT ON TABLE recurring_rules IS 'Schedules for transactions that repeat, such as salary or rent';
+COMMENT ON TABLE recurring_occurrences IS 'One row per generated occurrence; the unique key keeps generation idempotent';
+COMMENT ON TABLE installment_plans IS 'Purchases repaid in monthly instalments; each instalment becomes a transaction when due';
+COMMENT ON TABLE installment_charges IS 'One row per charged instalment; the unique key keeps charging idempotent';
+COMMENT ON TABLE imported_entries IS 'Bank references (OFX FITID, QIF check number or content fingerprint) of imported statement entries';
+COMMENT ON TABLE exchange_rates IS 'Historical rates: one base_currency is worth rate quote_currency on date';
+COMMENT ON TABLE wallet_types IS 'Net worth rules per wallet type, matched case-insensitively against wallets.type';
+COMMENT ON TABLE ledger_postings IS 'Postings of a journal entry; the amounts of one entry always sum to zero';
+
+COMMENT ON COLUMN transactions.type IS 'Type of transaction: income, expense, or transfer';
+COMMENT ON COLUMN transactions.to_wallet_id IS 'Destination wallet for transfer transactions';
+COMMENT ON COLUMN transactions.to_amount IS 'Amount received by the destination of a transfer between currencies, in its currency';
+COMMENT ON COLUMN transactions.fee_of_id IS 'Transfer that this expense is the fee of';
+COMMENT ON COLUMN transactions.category IS 'Category name at the time of the transaction, kept for display and history';
+COMMENT ON COLUMN installment_plans.interest_rate IS 'Flat monthly interest as a fraction of the principal, e.g. 0.0175; NULL for 0% plans';
+COMMENT ON COLUMN installment_charges.payoff IS 'Charge for the remaining principal when the plan was paid off early';
+COMMENT ON COLUMN budgets.start_day IS 'Day of month (monthly) or weekday, 0 = Sunday (weekly) on which a period starts';
+COMMENT ON COLUMN users.base_currency IS 'Currency that dashboard and report totals are converted to';
+COMMENT ON COLUMN wallets.balance IS 'Cached balance, always equal to the sum of the wallet ledger postings';
+COMMENT ON COLUMN wallets.account_class IS 'asset for money held, liability for money owed (the balance is negative while owing)';
+COMMENT ON COLUMN wallets.credit_limit IS 'How far the balance may go below zero; NULL means zero for assets and no limit for liabilities';
+COMMENT ON COLUMN wallets.statement_day IS 'Day of month on which a credit card statement closes; later days fall on the last day of shorter months';
+COMMENT ON COLUMN wallets.due_day IS 'Day of month on which payment of the last statement is due';
+COMMENT ON COLUMN wallet_types.account_class IS 'Default class of new wallets of this type';
+COMMENT ON COLUMN wallet_types.display_group IS 'Dashboard group that the balances of this type are totalled under';
+
+-- System wallet types. Safe to run repeatedly.
+INSERT INTO wallet_types (user_id, key, name, account_class, include_in_net_worth, display_group)
+VALUES (NULL, 'tabungan', 'Tabungan', 'asset', TRUE, 'Tabungan'),
+       (NULL, 'transaksi', 'Transaksi', 'asset', TRUE, 'Kas & Bank'),
+       (NULL, 'tunai', 'Tunai', 'asset', TRUE, 'Kas & Bank'),
+       (NULL, 'e-wallet', 'E-Wallet', 'asset', TRUE, 'Kas & Bank'),
+       (NULL, 'investasi', 'Investasi', 'asset', TRUE, 'Investasi'),
+       (NULL, 'kartu-kredit', 'Kartu Kredit', 'liability', TRUE, 'Utang'),
+       (NULL, 'paylater', 'PayLater', 'liability', TRUE, 'Utang'),
+       (NULL, 'pinjaman', 'Pinjaman', 'liability', TRUE, 'Utang')
+ON CONFLICT DO NOTHING;
+
+-- Backfill the ledger for data recorded before it existed. Safe to run repeatedly.
+INSERT INTO journal_entries (user_id, transaction_id, kind, memo, date, created_at)
+SELECT t.user_id, t.id, t.type, COALESCE(t.description, ''), t.date, t.created_at
+FROM transactions t
+WHERE NOT EXISTS (SELECT 1 FROM journal_entries j WHERE j.transaction_id = t.id);
+
+INSERT INTO ledger_postings (entry_id, wallet_id, account, amount)
+SELECT j.id, p.wallet_id, p.account, p.amount
+FROM journal_entries j
+JOIN transactions t ON t.id = j.transaction_id
+CROSS JOIN LATERAL (
+    SELECT t.wallet_id AS wallet_id,
+           'wallet' AS account,
+           CASE WHEN t.type = 'income' THEN t.amount ELSE -t.amount END AS amount
+    UNION ALL
+    SELECT CASE WHEN t.type = 'transfer' THEN t.to_wallet_id END,
+           CASE
+               WHEN t.type = 'income' THEN 'income:' || COALESCE(NULLIF(t.category, ''), 'Uncategorized')
+               WHEN t.type = 'expense' THEN 'expense:' || COALESCE(NULLIF(t.category, ''), 'Uncategorized')
+               WHEN t.to_wallet_id IS NULL THEN 'equity:removed-wallet'
+               ELSE 'wallet'
+           END,
+           CASE WHEN t.type = 'income' THEN -t.amount ELSE t.amount END
+) p
+WHERE NOT EXISTS (SELECT 1 FROM ledger_postings lp WHERE lp.entry_id = j.id);
+
+-- Whatever the postings do not explain becomes the wallet's opening balance
+DO $$
+DECLARE
+    w RECORD;
+    new_entry_id INTEGER;
+BEGIN
+    FOR w IN
+        SELECT wallets.id, wallets.user_id, wallets.created_at,
+               wallets.balance - COALESCE(SUM(ledger_postings.amount), 0) AS difference
+        FROM wallets
+        LEFT JOIN ledger_postings ON ledger_postings.wallet_id = wallets.id
+        GROUP BY wallets.id
+        HAVING wallets.balance - COALESCE(SUM(ledger_postings.amount), 0) <> 0
+    LOOP
+        INSERT INTO journal_entries (user_id, kind, memo, date, created_at)
+        VALUES (w.user_id, 'adjustment', 'Opening balance', w.created_at, CURRENT_TIMESTAMP)
+        RETURNING id INTO new_entry_id;
+
+        INSERT INTO ledger_postings (entry_id, wallet_id, account, amount)
+        VALUES (new_entry_id, w.id, 'wallet', w.difference),
+               (new_entry_id, NULL, 'equity:opening-balance', -w.difference);
+    END LOOP;
+END $$;
+
+-- Migrate free-text categories. Names that differ only in case or surrounding
+-- spaces become one category. Safe to run repeatedly.
+INSERT INTO categories (user_id, name, type)
+SELECT user_id, MIN(TRIM(category)), type
+FROM transactions
+WHERE type IN ('income', 'expense') AND TRIM(COALESCE(category, '')) <> ''
+GROUP BY user_id, type, LOWER(TRIM(category))
+ON CONFLICT DO NOTHING;
+
+UPDATE transactions t
+SET category_id = c.id
+FROM categories c
+WHERE t.category_id IS NULL
+  AND c.user_id = t.user_id
+  AND c.type = t.type
+  AND LOWER(c.name) = LOWER(TRIM(t.category));
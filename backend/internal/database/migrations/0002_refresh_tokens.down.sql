@@ -0,0 +1,3 @@
+-- Logs out every user; access tokens issued afterwards carry no session
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS sessions;
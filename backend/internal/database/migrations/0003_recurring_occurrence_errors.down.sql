@@ -0,0 +1,2 @@
+-- Skipped occurrences stay claimed but lose their reason
+ALTER TABLE recurring_occurrences DROP COLUMN IF EXISTS error;
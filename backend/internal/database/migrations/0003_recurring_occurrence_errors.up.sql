@@ -0,0 +1,6 @@
+-- An occurrence that cannot be generated, for example because the wallet
+-- lacks the balance, is recorded with the reason and skipped, so the rule
+-- moves on to its next date instead of retrying the same one forever
+ALTER TABLE recurring_occurrences ADD COLUMN IF NOT EXISTS error TEXT;
+
+COMMENT ON COLUMN recurring_occurrences.error IS 'Why the occurrence was skipped; NULL when its transaction was generated';
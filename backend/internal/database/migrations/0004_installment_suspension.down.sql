@@ -0,0 +1,8 @@
+-- Suspended plans become active again and are retried by the scheduler
+UPDATE installment_plans SET status = 'active' WHERE status = 'suspended';
+
+ALTER TABLE installment_plans DROP CONSTRAINT IF EXISTS installment_plans_status_check;
+ALTER TABLE installment_plans ADD CONSTRAINT installment_plans_status_check
+    CHECK (status IN ('active', 'completed', 'paid_off'));
+
+ALTER TABLE installment_plans DROP COLUMN IF EXISTS suspended_reason;
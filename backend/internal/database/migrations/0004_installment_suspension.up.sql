@@ -0,0 +1,10 @@
+-- A plan whose instalment cannot be charged, for example because the card is
+-- at its credit limit, is suspended with the reason instead of being retried
+-- on every scheduler run. Resuming it charges the overdue instalments.
+ALTER TABLE installment_plans ADD COLUMN IF NOT EXISTS suspended_reason TEXT;
+
+ALTER TABLE installment_plans DROP CONSTRAINT IF EXISTS installment_plans_status_check;
+ALTER TABLE installment_plans ADD CONSTRAINT installment_plans_status_check
+    CHECK (status IN ('active', 'suspended', 'completed', 'paid_off'));
+
+COMMENT ON COLUMN installment_plans.suspended_reason IS 'Why charging the plan stopped; NULL unless the plan is suspended';
@@ -0,0 +1,16 @@
+-- A wallet counts toward net worth with the class of its wallet type, which can
+-- change after the wallet is created, unless a class was chosen for the wallet
+-- itself. Existing wallets whose class differs from their type keep it.
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS account_class_override BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE wallets w
+SET account_class_override = TRUE
+WHERE w.account_class <> COALESCE((
+    SELECT t.account_class
+    FROM wallet_types t
+    WHERE t.key = LOWER(TRIM(w.type)) AND (t.user_id = w.user_id OR t.user_id IS NULL)
+    ORDER BY t.user_id NULLS LAST
+    LIMIT 1
+), w.account_class);
+
+COMMENT ON COLUMN wallets.account_class_override IS 'TRUE when account_class was chosen for the wallet instead of following its wallet type';
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type BudgetPeriod string
+
+const (
+	BudgetPeriodMonthly BudgetPeriod = "monthly"
+	BudgetPeriodWeekly  BudgetPeriod = "weekly"
+	BudgetPeriodCustom  BudgetPeriod = "custom"
+)
+
+// Budget limits the expenses of one category, including its subcategories, or
+// of all categories when CategoryID is nil. The limit resets every period.
+type Budget struct {
+	ID         int          `json:"id"`
+	UserID     int          `json:"user_id"`
+	CategoryID *int         `json:"category_id,omitempty"`
+	Name       string       `json:"name"`
+	Amount     Money        `json:"amount"`
+	Period     BudgetPeriod `json:"period"`
+	// StartDay is the day of the month (monthly) or weekday, 0 = Sunday (weekly)
+	// on which a period starts, e.g. 25 for a pay period starting on payday
+	StartDay int `json:"start_day"`
+	// PeriodDays is the length of a custom period, counted from StartDate
+	PeriodDays *int      `json:"period_days,omitempty"`
+	StartDate  time.Time `json:"start_date"`
+	// Rollover adds the unused amount of the previous period to the current one
+	Rollover  bool      `json:"rollover"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type BudgetRepository interface {
+	Create(ctx context.Context, budget *Budget) error
+	FindByUserID(ctx context.Context, userID int) ([]Budget, error)
+	FindByID(ctx context.Context, id int) (*Budget, error)
+	Update(ctx context.Context, budget *Budget) error
+	Delete(ctx context.Context, id int) error
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type CategoryType string
+
+const (
+	CategoryTypeIncome  CategoryType = "income"
+	CategoryTypeExpense CategoryType = "expense"
+)
+
+// Category groups income or expense transactions. Categories belong to a
+// user and can be nested, e.g. "Makan" > "Kopi".
+type Category struct {
+	ID        int          `json:"id"`
+	UserID    int          `json:"user_id"`
+	ParentID  *int         `json:"parent_id,omitempty"`
+	Name      string       `json:"name"`
+	Type      CategoryType `json:"type"`
+	Icon      string       `json:"icon"`
+	Color     string       `json:"color"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+type CategoryRepository interface {
+	// Create fails with a conflict when the user already has a category of
+	// the same type and name
+	Create(ctx context.Context, category *Category) error
+	// FindOrCreate stores the category unless one of the same type and name
+	// exists, and fills category with the stored one
+	FindOrCreate(ctx context.Context, category *Category) error
+	FindByUserID(ctx context.Context, userID int) ([]Category, error)
+	FindByID(ctx context.Context, id int) (*Category, error)
+	// FindByName looks a category up by name, ignoring case and surrounding spaces
+	FindByName(ctx context.Context, userID int, categoryType CategoryType, name string) (*Category, error)
+	Update(ctx context.Context, category *Category) error
+	Delete(ctx context.Context, id int) error
+	CountChildren(ctx context.Context, id int) (int, error)
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of errors caused by the request rather than by the server. Every
+// *Error matches its kind with errors.Is, and the handlers pick the HTTP
+// status from the kind.
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrForbidden         = errors.New("forbidden")
+	ErrConflict          = errors.New("conflict")
+	ErrValidation        = errors.New("validation failed")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrUnauthenticated   = errors.New("unauthenticated")
+)
+
+// Error is an error that can be shown to the client. Code is a stable,
+// machine-readable identifier such as "wallet_not_found".
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  []FieldError // The invalid fields of a request, when known
+}
+
+// FieldError is one invalid field of a request, named as in the JSON body
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"` // Such as "required" or "too_long"
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NotFound reports a missing resource, such as "wallet" or "installment plan"
+func NotFound(resource string) *Error {
+	return &Error{Kind: ErrNotFound, Code: errorCode(resource) + "_not_found", Message: resource + " not found"}
+}
+
+// Forbidden reports a resource that belongs to another user
+func Forbidden(resource string) *Error {
+	return &Error{Kind: ErrForbidden, Code: errorCode(resource) + "_forbidden", Message: "unauthorized access to " + resource}
+}
+
+// Conflict reports a request that clashes with data that already exists
+func Conflict(code string, format string, args ...any) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Invalid reports a request that breaks a rule, such as a missing field or a
+// malformed date
+func Invalid(format string, args ...any) *Error {
+	return &Error{Kind: ErrValidation, Code: "validation_failed", Message: fmt.Sprintf(format, args...)}
+}
+
+// Unauthenticated reports missing or wrong credentials
+func Unauthenticated(code string, message string) *Error {
+	return &Error{Kind: ErrUnauthenticated, Code: code, Message: message}
+}
+
+func errorCode(resource string) string {
+	return strings.ReplaceAll(resource, " ", "_")
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ExchangeRate says that on Date one unit of Base is worth Rate units of Quote
+type ExchangeRate struct {
+	ID        int       `json:"id"`
+	UserID    *int      `json:"user_id,omitempty"` // Nil for rates shared by all users, such as provider rates
+	Base      string    `json:"base_currency"`
+	Quote     string    `json:"quote_currency"`
+	Date      time.Time `json:"date"`
+	Rate      Rate      `json:"rate"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExchangeRateRepository interface {
+	// Upsert stores the rate, replacing the rate of the same owner for the
+	// same pair and date
+	Upsert(ctx context.Context, rate *ExchangeRate) error
+	// FindRate returns the most recent rate for the pair dated on or before
+	// date, preferring the user's own rate on the same date. It returns nil
+	// when there is none.
+	FindRate(ctx context.Context, userID int, base, quote string, date time.Time) (*ExchangeRate, error)
+	// FindByUserID returns the user's own rates and the shared ones, newest
+	// first. Empty base or quote match every currency.
+	FindByUserID(ctx context.Context, userID int, base, quote string) ([]ExchangeRate, error)
+	FindByID(ctx context.Context, id int) (*ExchangeRate, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// RateProvider is a source of shared exchange rates, such as a rates file or
+// a central bank feed
+type RateProvider interface {
+	Name() string
+	FetchRates(ctx context.Context) ([]ExchangeRate, error)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Goal is a savings target such as a laptop or an emergency fund. Progress is
+// the balance of the linked wallets plus manually recorded contributions.
+type Goal struct {
+	ID           int        `json:"id"`
+	UserID       int        `json:"user_id"`
+	Name         string     `json:"name"`
+	TargetAmount Money      `json:"target_amount"`
+	Deadline     *time.Time `json:"deadline,omitempty"`
+	WalletIDs    []int      `json:"wallet_ids"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// GoalContribution is money set aside for a goal outside its linked wallets.
+// A negative amount is a withdrawal.
+type GoalContribution struct {
+	ID        int       `json:"id"`
+	GoalID    int       `json:"goal_id"`
+	Amount    Money     `json:"amount"`
+	Date      time.Time `json:"date"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GoalRepository interface {
+	Create(ctx context.Context, goal *Goal) error
+	FindByUserID(ctx context.Context, userID int) ([]Goal, error)
+	FindByID(ctx context.Context, id int) (*Goal, error)
+	// FindByWalletID returns the goal the wallet is linked to
+	FindByWalletID(ctx context.Context, walletID int) (*Goal, error)
+	Update(ctx context.Context, goal *Goal) error
+	Delete(ctx context.Context, id int) error
+	// SetWallets replaces the wallets linked to the goal
+	SetWallets(ctx context.Context, goalID int, walletIDs []int) error
+	AddContribution(ctx context.Context, contribution *GoalContribution) error
+	FindContributions(ctx context.Context, goalID int) ([]GoalContribution, error)
+	FindContributionByID(ctx context.Context, id int) (*GoalContribution, error)
+	DeleteContribution(ctx context.Context, id int) error
+}
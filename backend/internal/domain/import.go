@@ -0,0 +1,14 @@
+package domain
+
+import "context"
+
+// ImportRepository remembers which bank statement entries were imported into
+// a wallet, keyed by the reference the bank gave them (such as an OFX FITID)
+type ImportRepository interface {
+	// ClaimExternalID links an external ID to the transaction created for it.
+	// It returns false when the ID was already imported into the wallet.
+	ClaimExternalID(ctx context.Context, walletID int, externalID string, transactionID int) (bool, error)
+	// FindImportedExternalIDs returns those of externalIDs already imported
+	// into the wallet
+	FindImportedExternalIDs(ctx context.Context, walletID int, externalIDs []string) ([]string, error)
+}
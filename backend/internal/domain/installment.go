@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type InstallmentStatus string
+
+const (
+	InstallmentActive    InstallmentStatus = "active"
+	InstallmentSuspended InstallmentStatus = "suspended" // An instalment could not be charged, see SuspendedReason
+	InstallmentCompleted InstallmentStatus = "completed"
+	InstallmentPaidOff   InstallmentStatus = "paid_off" // Settled early
+)
+
+// InstallmentPlan spreads one purchase over monthly charges to a wallet,
+// usually a credit card. Each instalment repays an equal part of the
+// principal plus flat interest on the full principal; the admin fee is added
+// to the first instalment.
+type InstallmentPlan struct {
+	ID          int    `json:"id"`
+	UserID      int    `json:"user_id"`
+	WalletID    int    `json:"wallet_id"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	Principal   Money  `json:"principal"`
+	Months      int    `json:"months"`
+	// InterestRate is the flat monthly rate, e.g. 0.0175 for 1.75% a month.
+	// Nil for 0% plans.
+	InterestRate     *Rate             `json:"interest_rate,omitempty"`
+	AdminFee         Money             `json:"admin_fee"`
+	FirstBillingDate time.Time         `json:"first_billing_date"`
+	Status           InstallmentStatus `json:"status"`
+	SuspendedReason  string            `json:"suspended_reason,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// IsOpen reports whether the plan still has instalments to charge
+func (p *InstallmentPlan) IsOpen() bool {
+	return p.Status == InstallmentActive || p.Status == InstallmentSuspended
+}
+
+// InstallmentCharge is an instalment that has become a transaction. A plan
+// paid off early ends with a charge for the remaining principal.
+type InstallmentCharge struct {
+	PlanID        int       `json:"plan_id"`
+	Number        int       `json:"number"` // 1-based
+	DueDate       time.Time `json:"due_date"`
+	Principal     Money     `json:"principal"`
+	Interest      Money     `json:"interest"`
+	Fee           Money     `json:"fee"`
+	Payoff        bool      `json:"payoff"`
+	TransactionID *int      `json:"transaction_id,omitempty"`
+}
+
+// Amount is the total charged for the instalment
+func (c *InstallmentCharge) Amount() Money {
+	return c.Principal.Add(c.Interest).Add(c.Fee)
+}
+
+type InstallmentRepository interface {
+	Create(ctx context.Context, plan *InstallmentPlan) error
+	FindByUserID(ctx context.Context, userID int) ([]InstallmentPlan, error)
+	FindByID(ctx context.Context, id int) (*InstallmentPlan, error)
+	// FindByIDForUpdate locks the plan until the end of the transaction, so
+	// that charging it and paying it off never overlap
+	FindByIDForUpdate(ctx context.Context, id int) (*InstallmentPlan, error)
+	FindActive(ctx context.Context) ([]InstallmentPlan, error)
+	// UpdateStatus sets the status and clears the reason of a suspension
+	UpdateStatus(ctx context.Context, id int, status InstallmentStatus) error
+	Suspend(ctx context.Context, id int, reason string) error
+	Delete(ctx context.Context, id int) error
+	// FindCharges lists the charges of the plan by number
+	FindCharges(ctx context.Context, planID int) ([]InstallmentCharge, error)
+	// ClaimCharge records the charge before its transaction is created. It
+	// returns false when the instalment was already charged.
+	ClaimCharge(ctx context.Context, charge *InstallmentCharge) (bool, error)
+	SetChargeTransaction(ctx context.Context, planID int, number int, transactionID int) error
+}
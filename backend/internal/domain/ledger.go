@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type JournalEntryKind string
+
+const (
+	JournalEntryIncome     JournalEntryKind = "income"
+	JournalEntryExpense    JournalEntryKind = "expense"
+	JournalEntryTransfer   JournalEntryKind = "transfer"
+	JournalEntryAdjustment JournalEntryKind = "adjustment"
+)
+
+// Ledger accounts that postings can be booked against.
+// Wallet postings always use AccountWallet together with a WalletID.
+const (
+	AccountWallet         = "wallet"
+	AccountIncomePrefix   = "income:"
+	AccountExpensePrefix  = "expense:"
+	AccountOpeningBalance = "equity:opening-balance"
+	AccountAdjustment     = "equity:adjustment"
+	AccountRemovedWallet  = "equity:removed-wallet"
+	// AccountExchangePrefix is followed by a currency code. A transfer between
+	// currencies sells one currency and buys the other through these accounts,
+	// which keeps each currency balanced on its own.
+	AccountExchangePrefix = "exchange:"
+)
+
+// JournalEntry is one balanced booking in the ledger. The amounts of its
+// postings always sum to zero, so every change to a wallet is matched by
+// an income, expense, equity or other wallet account.
+type JournalEntry struct {
+	ID            int              `json:"id"`
+	UserID        int              `json:"user_id"`
+	TransactionID *int             `json:"transaction_id,omitempty"`
+	Kind          JournalEntryKind `json:"kind"`
+	Memo          string           `json:"memo"`
+	Date          time.Time        `json:"date"`
+	Postings      []Posting        `json:"postings"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// Posting moves an amount into (positive) or out of (negative) one account
+type Posting struct {
+	ID       int    `json:"id"`
+	EntryID  int    `json:"entry_id"`
+	WalletID *int   `json:"wallet_id,omitempty"`
+	Account  string `json:"account"`
+	Amount   Money  `json:"amount"`
+}
+
+// IsBalanced reports whether the postings of the entry sum to zero
+func (e *JournalEntry) IsBalanced() bool {
+	var total Money
+	for _, posting := range e.Postings {
+		total = total.Add(posting.Amount)
+	}
+	return total.IsZero()
+}
+
+// WalletLedgerBalance compares the cached balance column of a wallet
+// with the balance derived from its postings
+type WalletLedgerBalance struct {
+	WalletID      int    `json:"wallet_id"`
+	WalletName    string `json:"wallet_name"`
+	CachedBalance Money  `json:"cached_balance"`
+	LedgerBalance Money  `json:"ledger_balance"`
+}
+
+// WalletPosting is one posting to a wallet together with its journal entry,
+// as listed on a wallet statement
+type WalletPosting struct {
+	EntryID       int
+	TransactionID *int
+	Kind          JournalEntryKind
+	Memo          string
+	Category      string // Category of the transaction, empty for other entries
+	OtherWallet   string // The other wallet of a transfer
+	Date          time.Time
+	Amount        Money
+}
+
+type LedgerRepository interface {
+	CreateEntry(ctx context.Context, entry *JournalEntry) error
+	FindByTransactionID(ctx context.Context, transactionID int) (*JournalEntry, error)
+	DeleteByTransactionID(ctx context.Context, transactionID int) error
+	DeleteByWalletID(ctx context.Context, walletID int) error
+	GetWalletBalances(ctx context.Context, userID int) ([]WalletLedgerBalance, error)
+	// SumWalletFlows totals the postings to the wallets dated in [start, end),
+	// leaving out opening balances
+	SumWalletFlows(ctx context.Context, walletIDs []int, start, end time.Time) (Money, error)
+	// WalletBalanceAt is the balance of the wallet from its postings dated
+	// before end
+	WalletBalanceAt(ctx context.Context, walletID int, end time.Time) (Money, error)
+	// FindWalletPostings lists the postings to the wallet dated in
+	// [start, end), oldest first
+	FindWalletPostings(ctx context.Context, walletID int, start, end time.Time) ([]WalletPosting, error)
+	// HasPostings reports whether anything, including an opening balance, has
+	// been posted to the wallet
+	HasPostings(ctx context.Context, walletID int) (bool, error)
+}
@@ -0,0 +1,240 @@
+package domain
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MoneyScale is the number of decimal places kept for every amount.
+// It matches the DECIMAL(15, 2) columns in the database schema.
+const MoneyScale = 2
+
+const minorPerUnit = 100
+
+// Money is an exact monetary amount stored in minor units (1/100 of the
+// currency unit) together with its ISO 4217 currency code.
+// The currency is empty for amounts that are not tied to a single wallet,
+// such as totals that are only meaningful in context.
+type Money struct {
+	minor    int64
+	currency string
+}
+
+// NewMoney creates an amount from minor units
+func NewMoney(minor int64, currency string) Money {
+	return Money{minor: minor, currency: currency}
+}
+
+// ParseMoney parses a plain decimal string such as "1500", "-20.5" or "1250000.00".
+// Amounts with more than MoneyScale decimal places are rejected instead of rounded.
+func ParseMoney(s string) (Money, error) {
+	input := strings.TrimSpace(s)
+	if input == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	s = input
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", input)
+	}
+	if hasFrac && frac == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", input)
+	}
+	if len(frac) > MoneyScale {
+		return Money{}, fmt.Errorf("amount %q has more than %d decimal places", input, MoneyScale)
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("invalid amount %q", input)
+	}
+
+	frac += strings.Repeat("0", MoneyScale-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("amount %q is out of range", input)
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return Money{minor: minor}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Minor returns the amount in minor units
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Currency returns the ISO 4217 currency code, or an empty string if unknown
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// WithCurrency returns the same amount tagged with the given currency
+func (m Money) WithCurrency(currency string) Money {
+	m.currency = currency
+	return m
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) Money {
+	return Money{minor: m.minor + o.minor, currency: combineCurrency(m.currency, o.currency)}
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) Money {
+	return Money{minor: m.minor - o.minor, currency: combineCurrency(m.currency, o.currency)}
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{minor: -m.minor, currency: m.currency}
+}
+
+// Mul returns m multiplied by a whole number
+func (m Money) Mul(n int64) Money {
+	return Money{minor: m.minor * n, currency: m.currency}
+}
+
+// Cmp returns -1, 0 or +1 depending on whether m is less than, equal to or greater than o
+func (m Money) Cmp(o Money) int {
+	switch {
+	case m.minor < o.minor:
+		return -1
+	case m.minor > o.minor:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.minor > 0
+}
+
+// IsNegative reports whether the amount is less than zero
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// String formats the amount as a plain decimal, e.g. "-1250000.50"
+func (m Money) String() string {
+	minor := m.minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+	}
+
+	// Work on the unsigned value so the minimum int64 does not overflow
+	abs := uint64(minor)
+	if minor < 0 {
+		abs = uint64(-(minor + 1)) + 1
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, abs/minorPerUnit, abs%minorPerUnit)
+}
+
+// MarshalJSON encodes the amount as a decimal string so clients never lose precision
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON accepts both a decimal string ("1500.00") and a JSON number (1500)
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return nil
+	}
+
+	raw := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseMoney(raw)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed.WithCurrency(m.currency)
+	return nil
+}
+
+// Scan implements sql.Scanner so amounts can be read from NUMERIC columns
+func (m *Money) Scan(src any) error {
+	var parsed Money
+	var err error
+
+	switch v := src.(type) {
+	case nil:
+		parsed = Money{}
+	case string:
+		parsed, err = ParseMoney(v)
+	case []byte:
+		parsed, err = ParseMoney(string(v))
+	case int64:
+		parsed = Money{minor: v * minorPerUnit}
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	if err != nil {
+		return err
+	}
+
+	*m = parsed.WithCurrency(m.currency)
+	return nil
+}
+
+// Value implements driver.Valuer so amounts are written to NUMERIC columns exactly
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// combineCurrency picks the currency of a result. An amount without a currency
+// takes the other operand's currency, and mixing two different currencies
+// yields an amount without one.
+func combineCurrency(a, b string) string {
+	switch {
+	case a == b || b == "":
+		return a
+	case a == "":
+		return b
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RateScale is the number of decimal places kept for exchange rates, enough
+// for rates such as IDR to USD (0.0000615)
+const RateScale = 10
+
+const rateUnit = 10_000_000_000
+
+// Rate is an exact, positive exchange rate: the price of one unit of a base
+// currency in a quote currency
+type Rate struct {
+	scaled int64
+}
+
+// ParseRate parses a decimal such as "16250" or "0.0000615". Rates with more
+// than RateScale decimal places are rejected instead of rounded.
+func ParseRate(s string) (Rate, error) {
+	input := strings.TrimSpace(s)
+	whole, frac, hasFrac := strings.Cut(input, ".")
+	if whole == "" && frac == "" || hasFrac && frac == "" {
+		return Rate{}, fmt.Errorf("invalid rate %q", s)
+	}
+	if len(frac) > RateScale {
+		return Rate{}, fmt.Errorf("rate %q has more than %d decimal places", input, RateScale)
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return Rate{}, fmt.Errorf("invalid rate %q", s)
+	}
+
+	frac += strings.Repeat("0", RateScale-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+	scaled, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Rate{}, fmt.Errorf("rate %q is out of range", input)
+	}
+	if scaled == 0 {
+		return Rate{}, fmt.Errorf("rate must be greater than zero")
+	}
+
+	return Rate{scaled: scaled}, nil
+}
+
+// IsPositive reports whether the rate is set; the zero Rate is not a rate
+func (r Rate) IsPositive() bool {
+	return r.scaled > 0
+}
+
+// Inverse returns the rate in the opposite direction, rounded to RateScale
+// decimals. It returns the zero Rate when the inverse does not fit.
+func (r Rate) Inverse() Rate {
+	if r.scaled <= 0 {
+		return Rate{}
+	}
+	unitSquared := new(big.Int).Mul(big.NewInt(rateUnit), big.NewInt(rateUnit))
+	inverse := divRound(unitSquared, big.NewInt(r.scaled))
+	if !inverse.IsInt64() {
+		return Rate{}
+	}
+	return Rate{scaled: inverse.Int64()}
+}
+
+// String formats the rate without trailing zeros, e.g. "16250" or "0.0000615"
+func (r Rate) String() string {
+	s := fmt.Sprintf("%d.%010d", r.scaled/rateUnit, r.scaled%rateUnit)
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
+
+// Convert returns m in another currency at the given rate, rounded half away
+// from zero to whole minor units
+func (m Money) Convert(rate Rate, currency string) Money {
+	product := new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(rate.scaled))
+	return Money{minor: divRound(product, big.NewInt(rateUnit)).Int64(), currency: currency}
+}
+
+// divRound divides rounding half away from zero; d must be positive
+func divRound(n, d *big.Int) *big.Int {
+	half := new(big.Int).Quo(d, big.NewInt(2))
+	if n.Sign() < 0 {
+		half.Neg(half)
+	}
+	return new(big.Int).Quo(new(big.Int).Add(n, half), d)
+}
+
+// MarshalJSON encodes the rate as a decimal string, like Money
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON accepts both a decimal string and a JSON number
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	raw := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseRate(raw)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner so rates can be read from NUMERIC columns
+func (r *Rate) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		parsed, err := ParseRate(v)
+		*r = parsed
+		return err
+	case []byte:
+		parsed, err := ParseRate(string(v))
+		*r = parsed
+		return err
+	default:
+		return fmt.Errorf("cannot scan %T into Rate", src)
+	}
+}
+
+// Value implements driver.Valuer so rates are written to NUMERIC columns exactly
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "daily"
+	RecurrenceWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceMonthly RecurrenceFrequency = "monthly"
+	RecurrenceCron    RecurrenceFrequency = "cron"
+)
+
+// RecurringRule describes a transaction that repeats on a schedule, such as a
+// monthly salary or a subscription. Occurrences are generated by the scheduler.
+type RecurringRule struct {
+	ID             int                 `json:"id"`
+	UserID         int                 `json:"user_id"`
+	WalletID       int                 `json:"wallet_id"`
+	ToWalletID     *int                `json:"to_wallet_id,omitempty"` // For transfers
+	Type           TransactionType     `json:"type"`
+	Amount         Money               `json:"amount"`
+	Category       string              `json:"category"`
+	Description    string              `json:"description"`
+	Frequency      RecurrenceFrequency `json:"frequency"`
+	DayOfWeek      *int                `json:"day_of_week,omitempty"`  // 0 = Sunday, for weekly rules
+	DayOfMonth     *int                `json:"day_of_month,omitempty"` // 1-31, for monthly rules
+	CronExpression string              `json:"cron_expression,omitempty"`
+	StartDate      time.Time           `json:"start_date"`
+	EndDate        *time.Time          `json:"end_date,omitempty"`
+	MaxOccurrences *int                `json:"max_occurrences,omitempty"`
+	Active         bool                `json:"active"`
+
+	// Derived from the occurrences. Skipped occurrences are not counted
+	// toward MaxOccurrences.
+	OccurrenceCount int        `json:"occurrence_count"`
+	SkippedCount    int        `json:"skipped_count"`
+	LastOccurrence  *time.Time `json:"last_occurrence,omitempty"`
+	NextOccurrence  *time.Time `json:"next_occurrence,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RecurringOccurrence is one date a rule fired on. An occurrence that could
+// not be generated, such as one the wallet lacked the balance for, is
+// skipped and keeps the reason in Error.
+type RecurringOccurrence struct {
+	Date          time.Time `json:"date"`
+	TransactionID *int      `json:"transaction_id,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type RecurringRuleRepository interface {
+	Create(ctx context.Context, rule *RecurringRule) error
+	FindByUserID(ctx context.Context, userID int) ([]RecurringRule, error)
+	FindByID(ctx context.Context, id int) (*RecurringRule, error)
+	FindActive(ctx context.Context) ([]RecurringRule, error)
+	Update(ctx context.Context, rule *RecurringRule) error
+	Delete(ctx context.Context, id int) error
+	// ClaimOccurrence records that the occurrence on date is being generated.
+	// It returns false when the occurrence was already claimed earlier.
+	ClaimOccurrence(ctx context.Context, ruleID int, date time.Time) (bool, error)
+	SetOccurrenceTransaction(ctx context.Context, ruleID int, date time.Time, transactionID int) error
+	// SkipOccurrence records that the occurrence on date failed for reason, so
+	// that it is not tried again
+	SkipOccurrence(ctx context.Context, ruleID int, date time.Time, reason string) error
+	// FindOccurrences lists the occurrences of a rule, newest first
+	FindOccurrences(ctx context.Context, ruleID int) ([]RecurringOccurrence, error)
+}
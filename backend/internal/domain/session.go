@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Session is one login of a user on one device. Its access tokens are only
+// accepted while it is not revoked, and its refresh tokens renew it.
+type Session struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsRevoked reports whether the session has been logged out
+func (s *Session) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
+
+// RefreshToken renews a session once. Only the hash of the token is stored.
+type RefreshToken struct {
+	ID        int
+	SessionID int
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time // Set when the token has been exchanged for a new one
+	CreatedAt time.Time
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session) error
+	FindByID(ctx context.Context, id int) (*Session, error)
+	Revoke(ctx context.Context, id int) error
+	RevokeByUserID(ctx context.Context, userID int) error
+	CreateRefreshToken(ctx context.Context, token *RefreshToken) error
+	FindRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// UseRefreshToken marks the token as used and reports false when it
+	// already was, so only one of two concurrent refreshes can succeed
+	UseRefreshToken(ctx context.Context, id int, usedAt time.Time) (bool, error)
+	// DeleteExpired removes refresh tokens that expired before the given time
+	// and the sessions left without any, returning how many tokens it removed
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
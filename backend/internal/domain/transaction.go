@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type TransactionType string
 
@@ -15,28 +18,104 @@ type Transaction struct {
 	UserID      int             `json:"user_id"`
 	WalletID    int             `json:"wallet_id"`
 	Type        TransactionType `json:"type"`
-	Amount      float64         `json:"amount"`
-	Category    string          `json:"category"`
+	Amount      Money           `json:"amount"`
+	Category    string          `json:"category"` // Name of the category, kept for display
+	CategoryID  *int            `json:"category_id,omitempty"`
 	Description string          `json:"description"`
 	Date        time.Time       `json:"date"`
 	ToWalletID  *int            `json:"to_wallet_id,omitempty"` // For transfers
+	ToAmount    *Money          `json:"to_amount,omitempty"`    // Received by the destination of a transfer between currencies
+	FeeOfID     *int            `json:"fee_of_id,omitempty"`    // The transfer this expense is the fee of
 	CreatedAt   time.Time       `json:"created_at"`
 	UpdatedAt   time.Time       `json:"updated_at"`
 }
 
+// TransactionExport is a transaction together with the names of the wallets
+// it touches, as written to exported files
+type TransactionExport struct {
+	Transaction
+	WalletName   string  `json:"wallet_name"`
+	ToWalletName *string `json:"to_wallet_name,omitempty"`
+	Currency     string  `json:"currency"`
+	ToCurrency   *string `json:"to_currency,omitempty"`
+}
+
+type TransactionSort string
+
+const (
+	TransactionSortDateDesc   TransactionSort = "date_desc"
+	TransactionSortDateAsc    TransactionSort = "date_asc"
+	TransactionSortAmountDesc TransactionSort = "amount_desc"
+	TransactionSortAmountAsc  TransactionSort = "amount_asc"
+)
+
+// TransactionFilter selects a page of a user's transactions. Empty fields do
+// not filter; all set fields must match.
+type TransactionFilter struct {
+	UserID      int
+	WalletIDs   []int // Matches the source or the destination wallet
+	Types       []TransactionType
+	CategoryIDs []int
+	Category    string // Category name, ignoring case
+	MinAmount   *Money
+	MaxAmount   *Money
+	Search      string // Text contained in the description, ignoring case
+	StartDate   *time.Time
+	EndDate     *time.Time // Exclusive
+	Sort        TransactionSort
+	Limit       int
+	// After continues after this transaction in the sort order (keyset pagination)
+	After *TransactionCursor
+}
+
+// TransactionCursor is the sort key of the last transaction on a page
+type TransactionCursor struct {
+	Date   time.Time `json:"date"`
+	Amount Money     `json:"amount"`
+	ID     int       `json:"id"`
+}
+
 type TransactionRepository interface {
-	Create(transaction *Transaction) error
-	FindByUserID(userID int) ([]Transaction, error)
-	FindByWalletID(walletID int) ([]Transaction, error)
-	FindByDateRange(userID int, startDate, endDate time.Time) ([]Transaction, error)
-	FindByID(id int) (*Transaction, error)
-	Delete(id int) error
-	GetStatsByUserID(userID int) (*TransactionStats, error)
-	GetRecentByUserID(userID int, limit int) ([]Transaction, error)
+	Create(ctx context.Context, transaction *Transaction) error
+	FindByUserID(ctx context.Context, userID int) ([]Transaction, error)
+	FindByWalletID(ctx context.Context, walletID int) ([]Transaction, error)
+	FindByDateRange(ctx context.Context, userID int, startDate, endDate time.Time) ([]Transaction, error)
+	FindByID(ctx context.Context, id int) (*Transaction, error)
+	// FindFees returns the fee expenses booked for a transfer
+	FindFees(ctx context.Context, transferID int) ([]Transaction, error)
+	Update(ctx context.Context, transaction *Transaction) error
+	Delete(ctx context.Context, id int) error
+	// Search returns the transactions matching the filter, at most filter.Limit
+	Search(ctx context.Context, filter TransactionFilter) ([]Transaction, error)
+	// StreamExport calls fn for every transaction matching the filter, oldest
+	// first, as the rows arrive from the database. Sort, Limit and After are
+	// ignored. An error from fn stops the stream and is returned.
+	StreamExport(ctx context.Context, filter TransactionFilter, fn func(TransactionExport) error) error
+	// GetDailyTotalsByUserID sums each type of transaction per day and wallet
+	// currency, so that each day can be converted at its own rate
+	GetDailyTotalsByUserID(ctx context.Context, userID int) ([]DailyTotal, error)
+	// SumExpensesByDay totals the expenses dated in [start, end) per day and
+	// wallet currency. A nil categoryIDs includes every category; otherwise
+	// only the listed categories count.
+	SumExpensesByDay(ctx context.Context, userID int, categoryIDs []int, start, end time.Time) ([]DailyTotal, error)
+	GetRecentByUserID(ctx context.Context, userID int, limit int) ([]Transaction, error)
 }
 
+// TransactionStats totals the transactions of a user in the base currency
 type TransactionStats struct {
-	TotalIncome   float64 `json:"total_income"`
-	TotalExpense  float64 `json:"total_expense"`
-	TotalTransfer float64 `json:"total_transfer"`
+	BaseCurrency  string `json:"base_currency"`
+	TotalIncome   Money  `json:"total_income"`
+	TotalExpense  Money  `json:"total_expense"`
+	TotalTransfer Money  `json:"total_transfer"`
+	// MissingRates lists the currency pairs without a rate; amounts in those
+	// currencies are left out of the totals
+	MissingRates []string `json:"missing_rates,omitempty"`
+}
+
+// DailyTotal is the sum of one type of transaction in one currency on one day
+type DailyTotal struct {
+	Date     time.Time       `json:"date"`
+	Type     TransactionType `json:"type"`
+	Currency string          `json:"currency"`
+	Amount   Money           `json:"amount"`
 }
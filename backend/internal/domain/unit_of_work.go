@@ -0,0 +1,24 @@
+package domain
+
+import "context"
+
+// Repositories groups the repositories that take part in a unit of work.
+// Every repository in the group shares the same underlying database transaction.
+type Repositories struct {
+	Users        UserRepository
+	Wallets      WalletRepository
+	Transactions TransactionRepository
+	Ledger       LedgerRepository
+	Recurring    RecurringRuleRepository
+	Categories   CategoryRepository
+	Goals        GoalRepository
+	Imports      ImportRepository
+	Installments InstallmentRepository
+	Sessions     SessionRepository
+}
+
+// UnitOfWork runs a function inside a single database transaction.
+// The transaction is committed when fn returns nil and rolled back otherwise.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repositories) error) error
+}
@@ -1,17 +1,23 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type User struct {
-	ID        int       `json:"id"`
-	Username  string    `json:"username"`
-	Password  string    `json:"-"` // Never send password in JSON
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Password string `json:"-"` // Never send password in JSON
+	// BaseCurrency is the currency dashboard and report totals are shown in
+	BaseCurrency string    `json:"base_currency"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 type UserRepository interface {
-	Create(user *User) error
-	FindByUsername(username string) (*User, error)
-	FindByID(id int) (*User, error)
+	Create(ctx context.Context, user *User) error
+	FindByUsername(ctx context.Context, username string) (*User, error)
+	FindByID(ctx context.Context, id int) (*User, error)
+	UpdateBaseCurrency(ctx context.Context, id int, currency string) error
 }
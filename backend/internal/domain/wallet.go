@@ -1,25 +1,69 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
+// ErrInsufficientBalance is returned when a balance adjustment would overdraw a wallet
+var ErrInsufficientBalance = &Error{Kind: ErrInsufficientFunds, Code: "insufficient_balance", Message: "insufficient balance"}
+
+// AccountClass tells whether a wallet holds money (asset) or tracks money owed
+// (liability), such as a credit card, PayLater or a loan
+type AccountClass string
+
+const (
+	AccountClassAsset     AccountClass = "asset"
+	AccountClassLiability AccountClass = "liability"
+)
+
+// Wallet is an account of the user. The balance of a liability is negative
+// while money is owed on it.
 type Wallet struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	Name      string    `json:"name"`
-	Balance   float64   `json:"balance"`
-	Currency  string    `json:"currency"`
-	Type      string    `json:"type"`
-	Icon      string    `json:"icon"`
-	Color     string    `json:"color"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           int          `json:"id"`
+	UserID       int          `json:"user_id"`
+	Name         string       `json:"name"`
+	Balance      Money        `json:"balance"`
+	Currency     string       `json:"currency"`
+	Type         string       `json:"type"`
+	AccountClass AccountClass `json:"account_class"`
+	// AccountClassOverride is set when the class was chosen for the wallet;
+	// otherwise the wallet follows the class of its wallet type
+	AccountClassOverride bool `json:"account_class_override"`
+	// CreditLimit is how far the balance may go below zero. Without one an
+	// asset cannot go negative and a liability has no limit.
+	CreditLimit *Money `json:"credit_limit,omitempty"`
+	// StatementDay and DueDay are the days of the month on which the statement
+	// of a credit card closes and its payment is due
+	StatementDay *int      `json:"statement_day,omitempty"`
+	DueDay       *int      `json:"due_day,omitempty"`
+	Icon         string    `json:"icon"`
+	Color        string    `json:"color"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// AllowsBalance reports whether the wallet may hold the given balance
+func (w *Wallet) AllowsBalance(balance Money) bool {
+	if !balance.IsNegative() {
+		return true
+	}
+	if w.CreditLimit == nil {
+		return w.AccountClass == AccountClassLiability
+	}
+	return balance.Neg().Cmp(*w.CreditLimit) <= 0
 }
 
 type WalletRepository interface {
-	Create(wallet *Wallet) error
-	FindByUserID(userID int) ([]Wallet, error)
-	FindByID(id int) (*Wallet, error)
-	Update(wallet *Wallet) error
-	Delete(id int) error
-	UpdateBalance(id int, newBalance float64) error
+	Create(ctx context.Context, wallet *Wallet) error
+	FindByUserID(ctx context.Context, userID int) ([]Wallet, error)
+	FindByID(ctx context.Context, id int) (*Wallet, error)
+	// Update saves the descriptive fields of a wallet. The balance only
+	// changes through AdjustBalance so that it always matches the ledger.
+	Update(ctx context.Context, wallet *Wallet) error
+	Delete(ctx context.Context, id int) error
+	// AdjustBalance atomically adds delta to the wallet balance. A negative
+	// delta is rejected with ErrInsufficientBalance when the balance would go
+	// below what the wallet allows (see Wallet.AllowsBalance).
+	AdjustBalance(ctx context.Context, id int, delta Money) error
 }
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// WalletType describes a kind of wallet, matched by key against Wallet.Type.
+// System types are shared by all users; a user type with the same key
+// replaces the system type for that user.
+type WalletType struct {
+	ID           int          `json:"id"`
+	UserID       *int         `json:"user_id,omitempty"` // Nil for system types
+	Key          string       `json:"key"`
+	Name         string       `json:"name"`
+	AccountClass AccountClass `json:"account_class"`
+	// IncludeInNetWorth leaves wallets of this type out of net worth when false,
+	// for example money kept for someone else
+	IncludeInNetWorth bool      `json:"include_in_net_worth"`
+	Group             string    `json:"group"` // Display group on the dashboard, e.g. "Kas & Bank"
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// IsSystem reports whether the type is defined for all users
+func (t *WalletType) IsSystem() bool {
+	return t.UserID == nil
+}
+
+// WalletTypeKey normalizes a wallet type so that "Tabungan", "tabungan " and
+// "TABUNGAN" match the same type
+func WalletTypeKey(walletType string) string {
+	return strings.ToLower(strings.TrimSpace(walletType))
+}
+
+type WalletTypeRepository interface {
+	Create(ctx context.Context, walletType *WalletType) error
+	// FindByUserID returns the system types and the user's own types
+	FindByUserID(ctx context.Context, userID int) ([]WalletType, error)
+	FindByID(ctx context.Context, id int) (*WalletType, error)
+	Update(ctx context.Context, walletType *WalletType) error
+	Delete(ctx context.Context, id int) error
+}
@@ -0,0 +1,56 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"go-moneyku/internal/domain"
+)
+
+type csvExporter struct{}
+
+func (csvExporter) ContentType() string { return "text/csv; charset=utf-8" }
+func (csvExporter) Extension() string   { return "csv" }
+func (csvExporter) PerWallet() bool     { return false }
+
+func (csvExporter) NewWriter(w io.Writer, statement Statement) (Writer, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	return &csvWriter{writer: writer}, nil
+}
+
+// csvWriter writes amounts as plain positive decimals with "." as separator;
+// the type column gives the direction, as the CSV importer expects
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(transaction domain.TransactionExport) error {
+	received, receivedCurrency := toAmount(transaction)
+	err := w.writer.Write([]string{
+		strconv.Itoa(transaction.ID),
+		transaction.Date.Format("2006-01-02"),
+		string(transaction.Type),
+		transaction.WalletName,
+		toWalletName(transaction),
+		transaction.Category,
+		transaction.Description,
+		transaction.Amount.String(),
+		transaction.Currency,
+		received,
+		receivedCurrency,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
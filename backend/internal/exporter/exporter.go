@@ -0,0 +1,85 @@
+// Package exporter writes transactions to files for spreadsheets, accounting
+// software and banks' OFX importers. Every format is written row by row, so
+// an export never has to be held in memory as a whole.
+package exporter
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// Statement describes the export as a whole, for formats with a header
+type Statement struct {
+	// Wallet is set when the export covers a single wallet
+	Wallet *domain.Wallet
+	// StartDate and EndDate (exclusive) are the requested range, when given
+	StartDate *time.Time
+	EndDate   *time.Time
+	// ClosingBalance is the balance of Wallet at the end of the range
+	ClosingBalance *domain.Money
+	GeneratedAt    time.Time
+}
+
+// Writer writes one exported file. Close finishes the file but does not close
+// the underlying io.Writer.
+type Writer interface {
+	Write(transaction domain.TransactionExport) error
+	Close() error
+}
+
+// Exporter creates files in one format
+type Exporter interface {
+	ContentType() string
+	Extension() string
+	// PerWallet reports whether a file describes a single account, so that
+	// the export must be limited to one wallet
+	PerWallet() bool
+	NewWriter(w io.Writer, statement Statement) (Writer, error)
+}
+
+var exporters = map[string]Exporter{
+	"csv":  csvExporter{},
+	"json": jsonExporter{},
+	"ofx":  ofxExporter{},
+	"xlsx": xlsxExporter{},
+}
+
+// ForFormat returns the exporter for a format such as "csv" or "xlsx"
+func ForFormat(format string) (Exporter, bool) {
+	exporter, ok := exporters[strings.ToLower(format)]
+	return exporter, ok
+}
+
+// Formats lists the supported export formats
+func Formats() []string {
+	formats := make([]string, 0, len(exporters))
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// columns are the headers of the tabular formats
+var columns = []string{"ID", "Date", "Type", "Wallet", "To Wallet", "Category", "Description", "Amount", "Currency", "To Amount", "To Currency"}
+
+// toAmount returns the amount received by the destination of a transfer
+// between currencies and its currency, or two empty strings
+func toAmount(transaction domain.TransactionExport) (string, string) {
+	if transaction.ToAmount == nil || transaction.ToCurrency == nil {
+		return "", ""
+	}
+	return transaction.ToAmount.String(), *transaction.ToCurrency
+}
+
+// toWalletName returns the destination wallet of a transfer, or ""
+func toWalletName(transaction domain.TransactionExport) string {
+	if transaction.ToWalletName == nil {
+		return ""
+	}
+	return *transaction.ToWalletName
+}
@@ -0,0 +1,52 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go-moneyku/internal/domain"
+)
+
+type jsonExporter struct{}
+
+func (jsonExporter) ContentType() string { return "application/json; charset=utf-8" }
+func (jsonExporter) Extension() string   { return "json" }
+func (jsonExporter) PerWallet() bool     { return false }
+
+// NewWriter streams the transactions as the data array of the usual API
+// response envelope, so existing clients of the export keep working
+func (jsonExporter) NewWriter(w io.Writer, statement Statement) (Writer, error) {
+	if _, err := io.WriteString(w, `{"success":true,"message":"Transactions exported successfully","data":[`); err != nil {
+		return nil, fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return &jsonWriter{w: w}, nil
+}
+
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (w *jsonWriter) Write(transaction domain.TransactionExport) error {
+	data, err := json.Marshal(transaction)
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	if w.count > 0 {
+		data = append([]byte{','}, data...)
+	}
+	w.count++
+
+	if _, err := w.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+func (w *jsonWriter) Close() error {
+	if _, err := io.WriteString(w.w, "]}"); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
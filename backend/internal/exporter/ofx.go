@@ -0,0 +1,155 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// ofxExporter writes an OFX 2 bank statement for one wallet. Amounts are
+// signed from the wallet's point of view, so a transfer is a debit in the
+// source wallet and a credit in the destination wallet.
+type ofxExporter struct{}
+
+func (ofxExporter) ContentType() string { return "application/x-ofx" }
+func (ofxExporter) Extension() string   { return "ofx" }
+func (ofxExporter) PerWallet() bool     { return true }
+
+// ofxNameLength is the longest NAME the OFX specification allows
+const ofxNameLength = 32
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+func (ofxExporter) NewWriter(w io.Writer, statement Statement) (Writer, error) {
+	if statement.Wallet == nil {
+		return nil, fmt.Errorf("an OFX export needs a single wallet")
+	}
+
+	writer := &ofxWriter{out: bufio.NewWriter(w), statement: statement}
+	generatedAt := ofxDate(statement.GeneratedAt)
+
+	writer.out.WriteString(ofxHeader)
+	writer.out.WriteString("<OFX>\n")
+	fmt.Fprintf(writer.out, "<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>IND</LANGUAGE></SONRS></SIGNONMSGSRSV1>\n", generatedAt)
+	writer.out.WriteString("<BANKMSGSRSV1><STMTTRNRS><TRNUID>1</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	writer.out.WriteString("<STMTRS>")
+	writer.element("CURDEF", statement.Wallet.Currency)
+	writer.out.WriteString("\n<BANKACCTFROM><BANKID>MONEYKU</BANKID>")
+	writer.element("ACCTID", strconv.Itoa(statement.Wallet.ID))
+	writer.out.WriteString("<ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>\n")
+
+	return writer, nil
+}
+
+type ofxWriter struct {
+	out       *bufio.Writer
+	statement Statement
+	// listStarted is set once BANKTRANLIST is open; its DTSTART is the date of
+	// the first, oldest transaction when the export has no start date
+	listStarted bool
+}
+
+func (w *ofxWriter) Write(transaction domain.TransactionExport) error {
+	if !w.listStarted {
+		w.startList(transaction.Date)
+	}
+
+	amount := transaction.Amount
+	transactionType := "CREDIT"
+	name := transaction.Category
+	switch transaction.Type {
+	case domain.TransactionTypeExpense:
+		amount, transactionType = amount.Neg(), "DEBIT"
+	case domain.TransactionTypeTransfer:
+		transactionType = "XFER"
+		if transaction.WalletID == w.statement.Wallet.ID {
+			amount = amount.Neg()
+			name = "Transfer to " + toWalletName(transaction)
+		} else {
+			name = "Transfer from " + transaction.WalletName
+			if transaction.ToAmount != nil {
+				amount = *transaction.ToAmount
+			}
+		}
+	}
+	if name == "" {
+		name = transaction.Description
+	}
+
+	w.out.WriteString("<STMTTRN>")
+	w.element("TRNTYPE", transactionType)
+	w.element("DTPOSTED", ofxDate(transaction.Date))
+	w.element("TRNAMT", amount.String())
+	w.element("FITID", strconv.Itoa(transaction.ID))
+	w.element("NAME", truncate(name, ofxNameLength))
+	if transaction.Description != "" && transaction.Description != name {
+		w.element("MEMO", transaction.Description)
+	}
+	_, err := w.out.WriteString("</STMTTRN>\n")
+
+	// bufio.Writer keeps the first error, so checking the last write is enough
+	if err != nil {
+		return fmt.Errorf("failed to write OFX: %w", err)
+	}
+	return nil
+}
+
+func (w *ofxWriter) Close() error {
+	if !w.listStarted {
+		w.startList(w.statement.GeneratedAt)
+	}
+
+	endDate := w.statement.GeneratedAt
+	if w.statement.EndDate != nil {
+		endDate = w.statement.EndDate.AddDate(0, 0, -1)
+	}
+
+	fmt.Fprintf(w.out, "<DTEND>%s</DTEND></BANKTRANLIST>\n", ofxDate(endDate))
+	if w.statement.ClosingBalance != nil {
+		w.out.WriteString("<LEDGERBAL>")
+		w.element("BALAMT", w.statement.ClosingBalance.String())
+		w.element("DTASOF", ofxDate(endDate))
+		w.out.WriteString("</LEDGERBAL>\n")
+	}
+	w.out.WriteString("</STMTRS></STMTTRNRS></BANKMSGSRSV1>\n</OFX>\n")
+
+	if err := w.out.Flush(); err != nil {
+		return fmt.Errorf("failed to write OFX: %w", err)
+	}
+	return nil
+}
+
+func (w *ofxWriter) startList(firstDate time.Time) {
+	startDate := firstDate
+	if w.statement.StartDate != nil {
+		startDate = *w.statement.StartDate
+	}
+	fmt.Fprintf(w.out, "<BANKTRANLIST><DTSTART>%s</DTSTART>\n", ofxDate(startDate))
+	w.listStarted = true
+}
+
+func (w *ofxWriter) element(tag, value string) {
+	fmt.Fprintf(w.out, "<%s>", tag)
+	xml.EscapeText(w.out, []byte(value))
+	fmt.Fprintf(w.out, "</%s>", tag)
+}
+
+func ofxDate(date time.Time) string {
+	return date.Format("20060102")
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(strings.TrimSpace(value))
+	if len(runes) <= length {
+		return string(runes)
+	}
+	return string(runes[:length])
+}
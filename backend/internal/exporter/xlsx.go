@@ -0,0 +1,186 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// xlsxExporter writes an Office Open XML workbook with a single sheet. The
+// static parts are written first; the sheet is the last entry of the zip
+// archive, so its rows can be streamed.
+type xlsxExporter struct{}
+
+func (xlsxExporter) ContentType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+func (xlsxExporter) Extension() string { return "xlsx" }
+func (xlsxExporter) PerWallet() bool   { return false }
+
+// Cell styles defined in xlsxStyles
+const (
+	xlsxStyleDate   = 1
+	xlsxStyleAmount = 2
+	xlsxStyleHeader = 3
+)
+
+// xlsxEpoch is day zero of the serial dates spreadsheets use
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Transactions" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="4">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+</cellXfs>
+<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>
+</styleSheet>`
+
+const xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>
+<cols><col min="1" max="1" width="8"/><col min="2" max="3" width="12"/><col min="4" max="6" width="20"/><col min="7" max="7" width="40"/><col min="8" max="8" width="16"/><col min="9" max="9" width="10"/><col min="10" max="10" width="16"/><col min="11" max="11" width="10"/></cols>
+<sheetData>
+`
+
+const xlsxSheetEnd = `</sheetData>
+</worksheet>`
+
+func (xlsxExporter) NewWriter(w io.Writer, statement Statement) (Writer, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write XLSX: %w", err)
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, fmt.Errorf("failed to write XLSX: %w", err)
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write XLSX: %w", err)
+	}
+
+	writer := &xlsxWriter{archive: archive, sheet: bufio.NewWriter(sheet)}
+	writer.sheet.WriteString(xlsxSheetStart)
+	writer.sheet.WriteString("<row>")
+	for _, column := range columns {
+		writer.stringCell(column, xlsxStyleHeader)
+	}
+	writer.sheet.WriteString("</row>\n")
+
+	return writer, nil
+}
+
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+}
+
+func (w *xlsxWriter) Write(transaction domain.TransactionExport) error {
+	w.sheet.WriteString("<row>")
+	w.numberCell(strconv.Itoa(transaction.ID), 0)
+	w.numberCell(strconv.Itoa(xlsxSerialDate(transaction.Date)), xlsxStyleDate)
+	w.stringCell(string(transaction.Type), 0)
+	w.stringCell(transaction.WalletName, 0)
+	w.stringCell(toWalletName(transaction), 0)
+	w.stringCell(transaction.Category, 0)
+	w.stringCell(transaction.Description, 0)
+	w.numberCell(transaction.Amount.String(), xlsxStyleAmount)
+	w.stringCell(transaction.Currency, 0)
+	if received, receivedCurrency := toAmount(transaction); received != "" {
+		w.numberCell(received, xlsxStyleAmount)
+		w.stringCell(receivedCurrency, 0)
+	}
+	_, err := w.sheet.WriteString("</row>\n")
+
+	// bufio.Writer keeps the first error, so checking the last write is enough
+	if err != nil {
+		return fmt.Errorf("failed to write XLSX row: %w", err)
+	}
+	return nil
+}
+
+func (w *xlsxWriter) Close() error {
+	w.sheet.WriteString(xlsxSheetEnd)
+	if err := w.sheet.Flush(); err != nil {
+		return fmt.Errorf("failed to write XLSX: %w", err)
+	}
+	if err := w.archive.Close(); err != nil {
+		return fmt.Errorf("failed to write XLSX: %w", err)
+	}
+	return nil
+}
+
+func (w *xlsxWriter) stringCell(value string, style int) {
+	if value == "" {
+		w.sheet.WriteString("<c/>")
+		return
+	}
+	fmt.Fprintf(w.sheet, `<c%s t="inlineStr"><is><t xml:space="preserve">`, xlsxStyleAttr(style))
+	xml.EscapeText(w.sheet, []byte(value))
+	w.sheet.WriteString("</t></is></c>")
+}
+
+func (w *xlsxWriter) numberCell(value string, style int) {
+	fmt.Fprintf(w.sheet, "<c%s><v>%s</v></c>", xlsxStyleAttr(style), value)
+}
+
+func xlsxStyleAttr(style int) string {
+	if style == 0 {
+		return ""
+	}
+	return fmt.Sprintf(` s="%d"`, style)
+}
+
+// xlsxSerialDate converts a date to the day number spreadsheets store dates as
+func xlsxSerialDate(date time.Time) int {
+	year, month, day := date.Date()
+	return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Sub(xlsxEpoch).Hours() / 24)
+}
@@ -23,13 +23,13 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req service.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	response, err := h.authService.Login(req)
+	response, err := h.authService.Login(c.Request.Context(), req)
 	if err != nil {
-		utils.UnauthorizedResponse(c, err.Error())
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
@@ -39,19 +39,72 @@ func (h *AuthHandler) Login(c *gin.Context) {
 func (h *AuthHandler) Signup(c *gin.Context) {
 	var req service.SignupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	response, err := h.authService.Signup(req)
+	response, err := h.authService.Signup(c.Request.Context(), req)
 	if err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusCreated, "Signup successful", response)
 }
 
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req service.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	response, err := h.authService.Refresh(c.Request.Context(), req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Token refreshed successfully", response)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	sessionID, exists := middleware.GetSessionID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), userID, sessionID); err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Logout successful", nil)
+}
+
+// LogoutAll logs the user out on every device, including this one
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	if err := h.authService.LogoutAll(c.Request.Context(), userID); err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Logged out of all devices", nil)
+}
+
 func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
@@ -59,7 +112,7 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.GetUserByID(userID)
+	user, err := h.authService.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
 		utils.NotFoundResponse(c, "User not found")
 		return
@@ -67,3 +120,25 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, "User retrieved successfully", user)
 }
+
+func (h *AuthHandler) UpdateCurrentUser(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	var req service.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	user, err := h.authService.UpdateUser(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User updated successfully", user)
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-moneyku/internal/middleware"
+	"go-moneyku/internal/service"
+	"go-moneyku/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BudgetHandler struct {
+	budgetService *service.BudgetService
+}
+
+func NewBudgetHandler(budgetService *service.BudgetService) *BudgetHandler {
+	return &BudgetHandler{
+		budgetService: budgetService,
+	}
+}
+
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	var req service.BudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	budget, err := h.budgetService.CreateBudget(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Budget created successfully", budget)
+}
+
+func (h *BudgetHandler) GetBudgets(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	budgets, err := h.budgetService.GetUserBudgets(c.Request.Context(), userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Budgets retrieved successfully", budgets)
+}
+
+func (h *BudgetHandler) GetBudget(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid budget ID")
+		return
+	}
+
+	budget, err := h.budgetService.GetBudget(c.Request.Context(), budgetID, userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Budget retrieved successfully", budget)
+}
+
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid budget ID")
+		return
+	}
+
+	var req service.BudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	budget, err := h.budgetService.UpdateBudget(c.Request.Context(), budgetID, userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Budget updated successfully", budget)
+}
+
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid budget ID")
+		return
+	}
+
+	if err := h.budgetService.DeleteBudget(c.Request.Context(), budgetID, userID); err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Budget deleted successfully", nil)
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-moneyku/internal/middleware"
+	"go-moneyku/internal/service"
+	"go-moneyku/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	categoryService *service.CategoryService
+}
+
+func NewCategoryHandler(categoryService *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	var req service.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Category created successfully", category)
+}
+
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	categories, err := h.categoryService.GetUserCategories(c.Request.Context(), userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved successfully", categories)
+}
+
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid category ID")
+		return
+	}
+
+	category, err := h.categoryService.GetCategoryByID(c.Request.Context(), categoryID, userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category retrieved successfully", category)
+}
+
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid category ID")
+		return
+	}
+
+	var req service.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(c.Request.Context(), categoryID, userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category updated successfully", category)
+}
+
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid category ID")
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(c.Request.Context(), categoryID, userID); err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category deleted successfully", nil)
+}
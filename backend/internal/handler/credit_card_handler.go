@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-moneyku/internal/middleware"
+	"go-moneyku/internal/service"
+	"go-moneyku/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreditCardHandler struct {
+	creditCardService *service.CreditCardService
+}
+
+func NewCreditCardHandler(creditCardService *service.CreditCardService) *CreditCardHandler {
+	return &CreditCardHandler{
+		creditCardService: creditCardService,
+	}
+}
+
+func (h *CreditCardHandler) GetBillingCycle(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	walletID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid wallet ID")
+		return
+	}
+
+	cycle, err := h.creditCardService.GetBillingCycle(c.Request.Context(), walletID, userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Billing cycle retrieved successfully", cycle)
+}
+
+func (h *CreditCardHandler) PayCard(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	walletID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid wallet ID")
+		return
+	}
+
+	var req service.PayCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.creditCardService.PayCard(c.Request.Context(), walletID, userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Card payment created successfully", result)
+}
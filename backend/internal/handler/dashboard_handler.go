@@ -27,9 +27,9 @@ func (h *DashboardHandler) GetSummary(c *gin.Context) {
 		return
 	}
 
-	summary, err := h.dashboardService.GetSummary(userID)
+	summary, err := h.dashboardService.GetSummary(c.Request.Context(), userID)
 	if err != nil {
-		utils.InternalErrorResponse(c, err.Error())
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
@@ -43,9 +43,9 @@ func (h *DashboardHandler) GetSpendingByCategory(c *gin.Context) {
 		return
 	}
 
-	spending, err := h.dashboardService.GetSpendingByCategory(userID)
+	spending, err := h.dashboardService.GetSpendingByCategory(c.Request.Context(), userID)
 	if err != nil {
-		utils.InternalErrorResponse(c, err.Error())
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
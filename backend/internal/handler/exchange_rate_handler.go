@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-moneyku/internal/middleware"
+	"go-moneyku/internal/service"
+	"go-moneyku/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeRateHandler struct {
+	exchangeRateService *service.ExchangeRateService
+}
+
+func NewExchangeRateHandler(exchangeRateService *service.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+func (h *ExchangeRateHandler) CreateRate(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	var req service.ExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	rate, err := h.exchangeRateService.CreateRate(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Exchange rate saved successfully", rate)
+}
+
+func (h *ExchangeRateHandler) GetRates(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	rates, err := h.exchangeRateService.GetRates(c.Request.Context(), userID, c.Query("base_currency"), c.Query("quote_currency"))
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Exchange rates retrieved successfully", rates)
+}
+
+func (h *ExchangeRateHandler) DeleteRate(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	rateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid exchange rate ID")
+		return
+	}
+
+	if err := h.exchangeRateService.DeleteRate(c.Request.Context(), rateID, userID); err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Exchange rate deleted successfully", nil)
+}
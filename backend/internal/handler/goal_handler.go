@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-moneyku/internal/middleware"
+	"go-moneyku/internal/service"
+	"go-moneyku/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GoalHandler struct {
+	goalService *service.GoalService
+}
+
+func NewGoalHandler(goalService *service.GoalService) *GoalHandler {
+	return &GoalHandler{
+		goalService: goalService,
+	}
+}
+
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	var req service.GoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	goal, err := h.goalService.CreateGoal(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Goal created successfully", goal)
+}
+
+func (h *GoalHandler) GetGoals(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	goals, err := h.goalService.GetUserGoals(c.Request.Context(), userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Goals retrieved successfully", goals)
+}
+
+func (h *GoalHandler) GetGoal(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid goal ID")
+		return
+	}
+
+	goal, err := h.goalService.GetGoal(c.Request.Context(), goalID, userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Goal retrieved successfully", goal)
+}
+
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid goal ID")
+		return
+	}
+
+	var req service.GoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	goal, err := h.goalService.UpdateGoal(c.Request.Context(), goalID, userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Goal updated successfully", goal)
+}
+
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid goal ID")
+		return
+	}
+
+	if err := h.goalService.DeleteGoal(c.Request.Context(), goalID, userID); err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Goal deleted successfully", nil)
+}
+
+func (h *GoalHandler) AddContribution(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid goal ID")
+		return
+	}
+
+	var req service.GoalContributionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	contribution, err := h.goalService.AddContribution(c.Request.Context(), goalID, userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Contribution added successfully", contribution)
+}
+
+func (h *GoalHandler) GetContributions(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid goal ID")
+		return
+	}
+
+	contributions, err := h.goalService.GetContributions(c.Request.Context(), goalID, userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Contributions retrieved successfully", contributions)
+}
+
+func (h *GoalHandler) DeleteContribution(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid goal ID")
+		return
+	}
+
+	contributionID, err := strconv.Atoi(c.Param("contributionId"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid contribution ID")
+		return
+	}
+
+	if err := h.goalService.DeleteContribution(c.Request.Context(), goalID, contributionID, userID); err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Contribution deleted successfully", nil)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go-moneyku/internal/importer"
+	"go-moneyku/internal/middleware"
+	"go-moneyku/internal/service"
+	"go-moneyku/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize bounds the size of an uploaded statement file
+const maxImportFileSize = 10 << 20
+
+type ImportHandler struct {
+	importService *service.ImportService
+}
+
+func NewImportHandler(importService *service.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+// ImportCSV expects a multipart form with the file in "file", the column
+// mapping as JSON in "mapping" and optionally "wallet_id", "commit" and
+// "skip_invalid". Without commit=true only a preview is returned.
+func (h *ImportHandler) ImportCSV(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	var mapping importer.CSVMapping
+	if err := json.Unmarshal([]byte(c.PostForm("mapping")), &mapping); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid column mapping")
+		return
+	}
+
+	options, ok := importOptionsFromForm(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ValidationErrorResponse(c, "File is required")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		utils.ValidationErrorResponse(c, "File is too large (max 10 MB)")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to read file")
+		return
+	}
+	defer file.Close()
+
+	result, err := h.importService.ImportCSV(c.Request.Context(), userID, file, mapping, options)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	respondImport(c, result)
+}
+
+// ImportStatement imports a bank statement in the format named by the path,
+// such as "ofx", "qfx" or "qif". The multipart form holds the file in "file",
+// the target "wallet_id", optionally "commit" and "skip_invalid", and for QIF
+// files "date_format" and "decimal_separator".
+func (h *ImportHandler) ImportStatement(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	options, ok := importOptionsFromForm(c)
+	if !ok {
+		return
+	}
+	parseOptions := importer.Options{
+		DateFormat:       c.PostForm("date_format"),
+		DecimalSeparator: c.PostForm("decimal_separator"),
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ValidationErrorResponse(c, "File is required")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		utils.ValidationErrorResponse(c, "File is too large (max 10 MB)")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to read file")
+		return
+	}
+	defer file.Close()
+
+	result, err := h.importService.ImportStatement(c.Request.Context(), userID, c.Param("format"), file, parseOptions, options)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	respondImport(c, result)
+}
+
+// importOptionsFromForm reads the options shared by every import format. It
+// writes the error response itself and reports whether the form was valid.
+func importOptionsFromForm(c *gin.Context) (service.ImportOptions, bool) {
+	var options service.ImportOptions
+
+	if value := c.PostForm("wallet_id"); value != "" {
+		walletID, err := strconv.Atoi(value)
+		if err != nil {
+			utils.ValidationErrorResponse(c, "Invalid wallet ID")
+			return options, false
+		}
+		options.WalletID = walletID
+	}
+	options.Commit = c.PostForm("commit") == "true"
+	options.SkipInvalid = c.PostForm("skip_invalid") == "true"
+
+	return options, true
+}
+
+func respondImport(c *gin.Context, result *service.ImportResult) {
+	if result.Committed {
+		utils.SuccessResponse(c, http.StatusCreated, "Transactions imported successfully", result)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Import preview generated", result)
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-moneyku/internal/middleware"
+	"go-moneyku/internal/service"
+	"go-moneyku/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InstallmentHandler struct {
+	installmentService *service.InstallmentService
+}
+
+func NewInstallmentHandler(installmentService *service.InstallmentService) *InstallmentHandler {
+	return &InstallmentHandler{
+		installmentService: installmentService,
+	}
+}
+
+func (h *InstallmentHandler) CreatePlan(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	var req service.CreateInstallmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	plan, err := h.installmentService.CreatePlan(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Installment plan created successfully", plan)
+}
+
+func (h *InstallmentHandler) GetPlans(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	plans, err := h.installmentService.GetUserPlans(c.Request.Context(), userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Installment plans retrieved successfully", plans)
+}
+
+func (h *InstallmentHandler) GetPlan(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	planID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid installment plan ID")
+		return
+	}
+
+	plan, err := h.installmentService.GetPlan(c.Request.Context(), planID, userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Installment plan retrieved successfully", plan)
+}
+
+func (h *InstallmentHandler) PayOff(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	planID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid installment plan ID")
+		return
+	}
+
+	// The body is optional
+	var req service.PayOffInstallmentRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BindErrorResponse(c, err)
+			return
+		}
+	}
+
+	plan, err := h.installmentService.PayOff(c.Request.Context(), planID, userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Installment plan paid off successfully", plan)
+}
+
+func (h *InstallmentHandler) Resume(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	planID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid installment plan ID")
+		return
+	}
+
+	plan, err := h.installmentService.Resume(c.Request.Context(), planID, userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Installment plan resumed successfully", plan)
+}
+
+func (h *InstallmentHandler) DeletePlan(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	planID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid installment plan ID")
+		return
+	}
+
+	if err := h.installmentService.DeletePlan(c.Request.Context(), planID, userID); err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Installment plan deleted successfully", nil)
+}
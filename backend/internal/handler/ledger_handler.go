@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-moneyku/internal/middleware"
+	"go-moneyku/internal/service"
+	"go-moneyku/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerHandler struct {
+	ledgerService *service.LedgerService
+}
+
+func NewLedgerHandler(ledgerService *service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService: ledgerService,
+	}
+}
+
+func (h *LedgerHandler) Reconcile(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	report, err := h.ledgerService.Reconcile(c.Request.Context(), userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ledger reconciled successfully", report)
+}
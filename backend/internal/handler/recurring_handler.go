@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-moneyku/internal/middleware"
+	"go-moneyku/internal/service"
+	"go-moneyku/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecurringHandler struct {
+	recurringService *service.RecurringService
+}
+
+func NewRecurringHandler(recurringService *service.RecurringService) *RecurringHandler {
+	return &RecurringHandler{
+		recurringService: recurringService,
+	}
+}
+
+func (h *RecurringHandler) CreateRule(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	var req service.RecurringRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	rule, err := h.recurringService.CreateRule(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Recurring rule created successfully", rule)
+}
+
+func (h *RecurringHandler) GetRules(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	rules, err := h.recurringService.GetUserRules(c.Request.Context(), userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recurring rules retrieved successfully", rules)
+}
+
+func (h *RecurringHandler) GetRule(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid recurring rule ID")
+		return
+	}
+
+	rule, err := h.recurringService.GetRule(c.Request.Context(), ruleID, userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recurring rule retrieved successfully", rule)
+}
+
+// GetOccurrences lists the dates the rule fired on, with the reason for any
+// occurrence that was skipped
+func (h *RecurringHandler) GetOccurrences(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid recurring rule ID")
+		return
+	}
+
+	occurrences, err := h.recurringService.GetOccurrences(c.Request.Context(), ruleID, userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recurring occurrences retrieved successfully", occurrences)
+}
+
+func (h *RecurringHandler) UpdateRule(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid recurring rule ID")
+		return
+	}
+
+	var req service.RecurringRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	rule, err := h.recurringService.UpdateRule(c.Request.Context(), ruleID, userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recurring rule updated successfully", rule)
+}
+
+func (h *RecurringHandler) DeleteRule(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid recurring rule ID")
+		return
+	}
+
+	if err := h.recurringService.DeleteRule(c.Request.Context(), ruleID, userID); err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recurring rule deleted successfully", nil)
+}
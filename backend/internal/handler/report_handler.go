@@ -1,7 +1,11 @@
 package handler
 
 import (
+	"bytes"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"go-moneyku/internal/middleware"
@@ -49,15 +53,60 @@ func (h *ReportHandler) GetTransactionReport(c *gin.Context) {
 		return
 	}
 
-	report, err := h.reportService.GetTransactionReport(userID, startDate, endDate)
+	report, err := h.reportService.GetTransactionReport(c.Request.Context(), userID, startDate, endDate)
 	if err != nil {
-		utils.InternalErrorResponse(c, err.Error())
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Report generated successfully", report)
 }
 
+// GetWalletStatement sends the monthly statement of a wallet as a PDF.
+// month is given as YYYY-MM and defaults to the current month.
+func (h *ReportHandler) GetWalletStatement(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	walletID, err := strconv.Atoi(c.Query("wallet_id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "wallet_id is required")
+		return
+	}
+
+	month := time.Now()
+	if value := c.Query("month"); value != "" {
+		month, err = time.Parse("2006-01", value)
+		if err != nil {
+			utils.ValidationErrorResponse(c, "Invalid month format (use YYYY-MM)")
+			return
+		}
+	}
+
+	statement, err := h.reportService.GetWalletStatement(c.Request.Context(), userID, walletID, month)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	var document bytes.Buffer
+	if err := statement.WritePDF(&document); err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("statement-wallet-%d-%s.pdf", walletID, statement.PeriodStart.Format("2006-01"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/pdf", document.Bytes())
+}
+
+// ExportTransactions sends the transactions as a file download. The format
+// is chosen with ?format=csv|xlsx|ofx|json (default json) and the rows are
+// filtered like the transaction list: start_date, end_date, wallet_id,
+// category_id, type, category and q.
 func (h *ReportHandler) ExportTransactions(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
@@ -65,11 +114,31 @@ func (h *ReportHandler) ExportTransactions(c *gin.Context) {
 		return
 	}
 
-	transactions, err := h.reportService.ExportTransactions(userID)
+	filter, err := transactionFilterFromQuery(c)
 	if err != nil {
-		utils.InternalErrorResponse(c, err.Error())
+		utils.ValidationErrorResponse(c, err.Error())
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Transactions exported successfully", transactions)
+	export, err := h.reportService.ExportTransactions(c.Request.Context(), userID, c.DefaultQuery("format", "json"), filter)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename))
+	c.Status(http.StatusOK)
+
+	if err := export.Stream(c.Request.Context(), c.Writer); err != nil {
+		// Once part of the file is sent the status can no longer change, so
+		// the truncated download is all the client gets
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			utils.ServiceErrorResponse(c, err)
+			return
+		}
+		log.Printf("Export: failed for user %d: %v", userID, err)
+	}
 }
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"go-moneyku/internal/domain"
 	"go-moneyku/internal/middleware"
 	"go-moneyku/internal/service"
 	"go-moneyku/internal/utils"
@@ -31,13 +34,13 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 
 	var req service.CreateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	transaction, err := h.transactionService.CreateTransaction(userID, req)
+	transaction, err := h.transactionService.CreateTransaction(c.Request.Context(), userID, req)
 	if err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
@@ -51,59 +54,132 @@ func (h *TransactionHandler) GetTransactions(c *gin.Context) {
 		return
 	}
 
-	// Check for wallet_id filter
-	walletIDStr := c.Query("wallet_id")
-	if walletIDStr != "" {
-		walletID, err := strconv.Atoi(walletIDStr)
+	filter, err := transactionFilterFromQuery(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	page, err := h.transactionService.SearchTransactions(c.Request.Context(), userID, filter, c.Query("cursor"))
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", page)
+}
+
+// transactionFilterFromQuery reads the filters of GET /transactions. List
+// parameters can be repeated or comma separated, e.g. type=income,expense.
+func transactionFilterFromQuery(c *gin.Context) (domain.TransactionFilter, error) {
+	var filter domain.TransactionFilter
+	var err error
+
+	if filter.WalletIDs, err = queryInts(c, "wallet_id"); err != nil {
+		return filter, fmt.Errorf("invalid wallet ID")
+	}
+	if filter.CategoryIDs, err = queryInts(c, "category_id"); err != nil {
+		return filter, fmt.Errorf("invalid category ID")
+	}
+	for _, value := range queryList(c, "type") {
+		filter.Types = append(filter.Types, domain.TransactionType(value))
+	}
+	filter.Category = c.Query("category")
+	filter.Search = strings.TrimSpace(c.Query("q"))
+	filter.Sort = domain.TransactionSort(c.Query("sort"))
+
+	if value := c.Query("min_amount"); value != "" {
+		amount, err := domain.ParseMoney(value)
 		if err != nil {
-			utils.ValidationErrorResponse(c, "Invalid wallet ID")
-			return
+			return filter, fmt.Errorf("invalid min_amount")
 		}
-
-		transactions, err := h.transactionService.GetWalletTransactions(walletID, userID)
+		filter.MinAmount = &amount
+	}
+	if value := c.Query("max_amount"); value != "" {
+		amount, err := domain.ParseMoney(value)
 		if err != nil {
-			utils.InternalErrorResponse(c, err.Error())
-			return
+			return filter, fmt.Errorf("invalid max_amount")
 		}
-
-		utils.SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", transactions)
-		return
+		filter.MaxAmount = &amount
 	}
 
-	// Check for date range filter
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
-	if startDateStr != "" && endDateStr != "" {
-		startDate, err := time.Parse("2006-01-02", startDateStr)
+	if value := c.Query("start_date"); value != "" {
+		startDate, err := time.Parse("2006-01-02", value)
 		if err != nil {
-			utils.ValidationErrorResponse(c, "Invalid start date format (use YYYY-MM-DD)")
-			return
+			return filter, fmt.Errorf("invalid start date format (use YYYY-MM-DD)")
 		}
-
-		endDate, err := time.Parse("2006-01-02", endDateStr)
+		filter.StartDate = &startDate
+	}
+	if value := c.Query("end_date"); value != "" {
+		endDate, err := time.Parse("2006-01-02", value)
 		if err != nil {
-			utils.ValidationErrorResponse(c, "Invalid end date format (use YYYY-MM-DD)")
-			return
+			return filter, fmt.Errorf("invalid end date format (use YYYY-MM-DD)")
 		}
+		// The end date is inclusive, so the range runs until the next midnight
+		endDate = endDate.AddDate(0, 0, 1)
+		filter.EndDate = &endDate
+	}
 
-		transactions, err := h.transactionService.GetTransactionsByDateRange(userID, startDate, endDate)
+	if value := c.Query("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit <= 0 {
+			return filter, fmt.Errorf("invalid limit")
+		}
+	}
+
+	return filter, nil
+}
+
+// queryList returns the values of a repeated or comma separated query parameter
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func queryInts(c *gin.Context, key string) ([]int, error) {
+	var values []int
+	for _, value := range queryList(c, key) {
+		number, err := strconv.Atoi(value)
 		if err != nil {
-			utils.InternalErrorResponse(c, err.Error())
-			return
+			return nil, err
 		}
+		values = append(values, number)
+	}
+	return values, nil
+}
+
+func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	transactionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid transaction ID")
+		return
+	}
 
-		utils.SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", transactions)
+	var req service.UpdateTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	// Get all user transactions
-	transactions, err := h.transactionService.GetUserTransactions(userID)
+	transaction, err := h.transactionService.UpdateTransaction(c.Request.Context(), transactionID, userID, req)
 	if err != nil {
-		utils.InternalErrorResponse(c, err.Error())
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", transactions)
+	utils.SuccessResponse(c, http.StatusOK, "Transaction updated successfully", transaction)
 }
 
 func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
@@ -119,8 +195,8 @@ func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
 		return
 	}
 
-	if err := h.transactionService.DeleteTransaction(transactionID, userID); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+	if err := h.transactionService.DeleteTransaction(c.Request.Context(), transactionID, userID); err != nil {
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
@@ -30,13 +30,13 @@ func (h *WalletHandler) CreateWallet(c *gin.Context) {
 
 	var req service.CreateWalletRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	wallet, err := h.walletService.CreateWallet(userID, req)
+	wallet, err := h.walletService.CreateWallet(c.Request.Context(), userID, req)
 	if err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
@@ -50,9 +50,9 @@ func (h *WalletHandler) GetWallets(c *gin.Context) {
 		return
 	}
 
-	wallets, err := h.walletService.GetUserWallets(userID)
+	wallets, err := h.walletService.GetUserWallets(c.Request.Context(), userID)
 	if err != nil {
-		utils.InternalErrorResponse(c, err.Error())
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
@@ -72,9 +72,9 @@ func (h *WalletHandler) GetWallet(c *gin.Context) {
 		return
 	}
 
-	wallet, err := h.walletService.GetWalletByID(walletID, userID)
+	wallet, err := h.walletService.GetWalletByID(c.Request.Context(), walletID, userID)
 	if err != nil {
-		utils.NotFoundResponse(c, err.Error())
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
@@ -96,13 +96,13 @@ func (h *WalletHandler) UpdateWallet(c *gin.Context) {
 
 	var req service.UpdateWalletRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ValidationErrorResponse(c, "Invalid request body")
+		utils.BindErrorResponse(c, err)
 		return
 	}
 
-	wallet, err := h.walletService.UpdateWallet(walletID, userID, req)
+	wallet, err := h.walletService.UpdateWallet(c.Request.Context(), walletID, userID, req)
 	if err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
@@ -122,8 +122,8 @@ func (h *WalletHandler) DeleteWallet(c *gin.Context) {
 		return
 	}
 
-	if err := h.walletService.DeleteWallet(walletID, userID); err != nil {
-		utils.ValidationErrorResponse(c, err.Error())
+	if err := h.walletService.DeleteWallet(c.Request.Context(), walletID, userID); err != nil {
+		utils.ServiceErrorResponse(c, err)
 		return
 	}
 
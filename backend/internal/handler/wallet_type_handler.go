@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-moneyku/internal/middleware"
+	"go-moneyku/internal/service"
+	"go-moneyku/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WalletTypeHandler struct {
+	walletTypeService *service.WalletTypeService
+}
+
+func NewWalletTypeHandler(walletTypeService *service.WalletTypeService) *WalletTypeHandler {
+	return &WalletTypeHandler{
+		walletTypeService: walletTypeService,
+	}
+}
+
+func (h *WalletTypeHandler) CreateWalletType(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	var req service.CreateWalletTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	walletType, err := h.walletTypeService.CreateWalletType(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Wallet type created successfully", walletType)
+}
+
+func (h *WalletTypeHandler) GetWalletTypes(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	walletTypes, err := h.walletTypeService.GetWalletTypes(c.Request.Context(), userID)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Wallet types retrieved successfully", walletTypes)
+}
+
+func (h *WalletTypeHandler) UpdateWalletType(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	walletTypeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid wallet type ID")
+		return
+	}
+
+	var req service.UpdateWalletTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindErrorResponse(c, err)
+		return
+	}
+
+	walletType, err := h.walletTypeService.UpdateWalletType(c.Request.Context(), walletTypeID, userID, req)
+	if err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Wallet type updated successfully", walletType)
+}
+
+func (h *WalletTypeHandler) DeleteWalletType(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	walletTypeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ValidationErrorResponse(c, "Invalid wallet type ID")
+		return
+	}
+
+	if err := h.walletTypeService.DeleteWalletType(c.Request.Context(), walletTypeID, userID); err != nil {
+		utils.ServiceErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Wallet type deleted successfully", nil)
+}
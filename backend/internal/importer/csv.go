@@ -0,0 +1,249 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-moneyku/internal/domain"
+)
+
+// CSVMapping tells which column holds which transaction field. A column is
+// given by its header name, ignoring case, or by its 1-based position.
+type CSVMapping struct {
+	Date        string `json:"date"`
+	Amount      string `json:"amount"`
+	Debit       string `json:"debit,omitempty"`  // Outflow column, for files without a signed amount
+	Credit      string `json:"credit,omitempty"` // Inflow column, for files without a signed amount
+	Type        string `json:"type,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Description string `json:"description,omitempty"`
+	Wallet      string `json:"wallet,omitempty"`
+
+	DateFormat       string `json:"date_format,omitempty"`       // e.g. "DD/MM/YYYY", defaults to DefaultDateFormat
+	DecimalSeparator string `json:"decimal_separator,omitempty"` // "," (default, as in 1.250.000,00) or "."
+	Delimiter        string `json:"delimiter,omitempty"`         // "," (default), ";" or "\t"
+	NoHeader         bool   `json:"no_header,omitempty"`         // The first row already holds data
+}
+
+// csvColumns are the resolved 0-based column positions; -1 means not mapped
+type csvColumns struct {
+	date, amount, debit, credit, kind, category, description, wallet int
+}
+
+// ParseCSV reads every row of a CSV file. Rows with invalid values are
+// returned with their Errors set; only an unreadable file or an unusable
+// mapping fails the whole parse.
+func ParseCSV(r io.Reader, mapping CSVMapping) ([]Entry, error) {
+	layout, err := DateLayout(mapping.DateFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	// Spreadsheet exports often start with a byte order mark, header or not
+	input := bufio.NewReader(r)
+	if bom, _, err := input.ReadRune(); err != nil || bom != '\ufeff' {
+		_ = input.UnreadRune()
+	}
+
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	if mapping.Delimiter != "" {
+		delimiter := mapping.Delimiter
+		if delimiter == `\t` {
+			delimiter = "\t"
+		}
+		comma, size := utf8.DecodeRuneInString(delimiter)
+		if size != len(delimiter) {
+			return nil, fmt.Errorf("delimiter must be a single character")
+		}
+		reader.Comma = comma
+	}
+
+	var header []string
+	if !mapping.NoHeader {
+		header, err = reader.Read()
+		if err == io.EOF {
+			return nil, fmt.Errorf("file is empty")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV header: %w", err)
+		}
+	}
+
+	columns, err := resolveCSVColumns(mapping, header)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(entries) >= MaxEntries {
+			return nil, fmt.Errorf("file has more than %d rows", MaxEntries)
+		}
+
+		line, _ := reader.FieldPos(0)
+		entries = append(entries, parseCSVRecord(record, line, columns, layout, mapping))
+	}
+
+	return entries, nil
+}
+
+func parseCSVRecord(record []string, line int, columns csvColumns, layout string, mapping CSVMapping) Entry {
+	entry := Entry{Line: line}
+	field := func(column int) string {
+		if column < 0 || column >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[column])
+	}
+
+	if value := field(columns.date); value == "" {
+		entry.addError("date is empty")
+	} else if date, err := time.Parse(layout, value); err != nil {
+		entry.addError("invalid date %q (expected %s)", value, dateFormatOrDefault(mapping.DateFormat))
+	} else {
+		entry.Date = date
+	}
+
+	var amount domain.Money
+	var amountErr error
+	if columns.amount >= 0 {
+		if value := field(columns.amount); value == "" {
+			amountErr = errors.New("amount is empty")
+		} else {
+			amount, amountErr = ParseAmount(value, mapping.DecimalSeparator)
+		}
+	} else {
+		// Separate debit and credit columns: a debit is money going out
+		debit, credit := field(columns.debit), field(columns.credit)
+		switch {
+		case debit != "" && credit != "":
+			amountErr = errors.New("both debit and credit are filled in")
+		case debit != "":
+			amount, amountErr = ParseAmount(debit, mapping.DecimalSeparator)
+			amount = amount.Neg()
+		case credit != "":
+			amount, amountErr = ParseAmount(credit, mapping.DecimalSeparator)
+		default:
+			amountErr = errors.New("amount is empty")
+		}
+	}
+	if amountErr != nil {
+		entry.addError("%v", amountErr)
+	} else if amount.IsZero() {
+		entry.addError("amount must not be zero")
+	}
+
+	// Without a type column the sign of the amount gives the direction
+	if value := field(columns.kind); value != "" {
+		transactionType, err := ParseTransactionType(value)
+		if err != nil {
+			entry.addError("%v", err)
+		}
+		entry.Type = transactionType
+	} else if amount.IsNegative() {
+		entry.Type = domain.TransactionTypeExpense
+	} else {
+		entry.Type = domain.TransactionTypeIncome
+	}
+	if amount.IsNegative() {
+		amount = amount.Neg()
+	}
+	entry.Amount = amount
+
+	entry.Category = field(columns.category)
+	entry.Description = field(columns.description)
+	entry.Wallet = field(columns.wallet)
+
+	return entry
+}
+
+func resolveCSVColumns(mapping CSVMapping, header []string) (csvColumns, error) {
+	byName := make(map[string]int, len(header))
+	for i, name := range header {
+		byName[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	resolve := func(field, column string) (int, error) {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			return -1, nil
+		}
+		if position, err := strconv.Atoi(column); err == nil {
+			if position < 1 {
+				return -1, fmt.Errorf("column for %s must be 1 or greater", field)
+			}
+			return position - 1, nil
+		}
+		if index, ok := byName[strings.ToLower(column)]; ok {
+			return index, nil
+		}
+		return -1, fmt.Errorf("column %q for %s not found in the header", column, field)
+	}
+
+	var columns csvColumns
+	for _, c := range []struct {
+		field  string
+		column string
+		target *int
+	}{
+		{"date", mapping.Date, &columns.date},
+		{"amount", mapping.Amount, &columns.amount},
+		{"debit", mapping.Debit, &columns.debit},
+		{"credit", mapping.Credit, &columns.credit},
+		{"type", mapping.Type, &columns.kind},
+		{"category", mapping.Category, &columns.category},
+		{"description", mapping.Description, &columns.description},
+		{"wallet", mapping.Wallet, &columns.wallet},
+	} {
+		index, err := resolve(c.field, c.column)
+		if err != nil {
+			return columns, err
+		}
+		*c.target = index
+	}
+
+	if columns.date < 0 {
+		return columns, fmt.Errorf("a column for date is required")
+	}
+	if columns.amount < 0 && columns.debit < 0 && columns.credit < 0 {
+		return columns, fmt.Errorf("a column for amount, or for debit and credit, is required")
+	}
+
+	return columns, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func dateFormatOrDefault(format string) string {
+	if format == "" {
+		return DefaultDateFormat
+	}
+	return format
+}
@@ -0,0 +1,158 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		mapping CSVMapping
+		want    []Entry
+	}{
+		{
+			name: "signed amounts by header name",
+			file: "\uFEFFTanggal,Keterangan,Jumlah,Kategori\n" +
+				"01/05/2024,Gaji,\"15.000.000,00\",Gaji\n" +
+				"02/05/2024,Makan siang,\"-45.500\",Makan\n",
+			mapping: CSVMapping{Date: "tanggal", Amount: "Jumlah", Description: "keterangan", Category: "KATEGORI", DateFormat: "DD/MM/YYYY"},
+			want: []Entry{
+				{Line: 2, Date: date("2024-05-01"), Amount: money("15000000"), Type: domain.TransactionTypeIncome, Category: "Gaji", Description: "Gaji"},
+				{Line: 3, Date: date("2024-05-02"), Amount: money("45500"), Type: domain.TransactionTypeExpense, Category: "Makan", Description: "Makan siang"},
+			},
+		},
+		{
+			name: "debit and credit columns by position",
+			file: "date;debit;credit;memo\n" +
+				"2024-05-01;;1.000;Transfer masuk\n" +
+				"2024-05-02;Rp 250;;Admin\n",
+			mapping: CSVMapping{Date: "1", Debit: "2", Credit: "3", Description: "4", Delimiter: ";"},
+			want: []Entry{
+				{Line: 2, Date: date("2024-05-01"), Amount: money("1000"), Type: domain.TransactionTypeIncome, Description: "Transfer masuk"},
+				{Line: 3, Date: date("2024-05-02"), Amount: money("250"), Type: domain.TransactionTypeExpense, Description: "Admin"},
+			},
+		},
+		{
+			name:    "type column over the sign",
+			file:    "date\tamount\ttype\twallet\n2024-05-01\t1,250.50\tpengeluaran\tBCA\n",
+			mapping: CSVMapping{Date: "date", Amount: "amount", Type: "type", Wallet: "wallet", Delimiter: `\t`, DecimalSeparator: "."},
+			want: []Entry{
+				{Line: 2, Date: date("2024-05-01"), Amount: money("1250.50"), Type: domain.TransactionTypeExpense, Wallet: "BCA"},
+			},
+		},
+		{
+			name:    "no header, byte order mark and blank rows",
+			file:    "\uFEFF2024-05-01,100\n\n , \n2024-05-03,(200)\n",
+			mapping: CSVMapping{Date: "1", Amount: "2", NoHeader: true},
+			want: []Entry{
+				{Line: 1, Date: date("2024-05-01"), Amount: money("100"), Type: domain.TransactionTypeIncome},
+				{Line: 4, Date: date("2024-05-03"), Amount: money("200"), Type: domain.TransactionTypeExpense},
+			},
+		},
+		{
+			name: "invalid rows keep their errors",
+			file: "date,amount,debit,credit,type\n" +
+				"31/02/2024,100,,,\n" +
+				",0,,,\n" +
+				"2024-05-01,abc,,,\n" +
+				"2024-05-01,100,,,maybe\n",
+			mapping: CSVMapping{Date: "date", Amount: "amount", Type: "type"},
+			want: []Entry{
+				{Line: 2, Amount: money("100"), Type: domain.TransactionTypeIncome, Errors: []string{`invalid date "31/02/2024" (expected YYYY-MM-DD)`}},
+				{Line: 3, Type: domain.TransactionTypeIncome, Errors: []string{"date is empty", "amount must not be zero"}},
+				{Line: 4, Date: date("2024-05-01"), Type: domain.TransactionTypeIncome, Errors: []string{`invalid amount "abc"`}},
+				{Line: 5, Date: date("2024-05-01"), Amount: money("100"), Errors: []string{`unknown transaction type "maybe" (use income or expense)`}},
+			},
+		},
+		{
+			name:    "debit and credit both filled in",
+			file:    "date,debit,credit\n2024-05-01,10,20\n2024-05-02,,\n",
+			mapping: CSVMapping{Date: "date", Debit: "debit", Credit: "credit"},
+			want: []Entry{
+				{Line: 2, Date: date("2024-05-01"), Type: domain.TransactionTypeIncome, Errors: []string{"both debit and credit are filled in"}},
+				{Line: 3, Date: date("2024-05-02"), Type: domain.TransactionTypeIncome, Errors: []string{"amount is empty"}},
+			},
+		},
+		{
+			name:    "short rows",
+			file:    "date,amount,memo\n2024-05-01\n",
+			mapping: CSVMapping{Date: "date", Amount: "amount", Description: "memo"},
+			want: []Entry{
+				{Line: 2, Date: date("2024-05-01"), Type: domain.TransactionTypeIncome, Errors: []string{"amount is empty"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ParseCSV(strings.NewReader(tt.file), tt.mapping)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(entries, tt.want) {
+				t.Fatalf("got  %+v\nwant %+v", entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCSVRejectsFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		mapping CSVMapping
+		wantErr string
+	}{
+		{"empty file", "", CSVMapping{Date: "date", Amount: "amount"}, "file is empty"},
+		{"no date column", "date,amount\n", CSVMapping{Amount: "amount"}, "a column for date is required"},
+		{"no amount column", "date,amount\n", CSVMapping{Date: "date"}, "a column for amount, or for debit and credit, is required"},
+		{"unknown header", "date,amount\n", CSVMapping{Date: "tanggal", Amount: "amount"}, `column "tanggal" for date not found in the header`},
+		{"position zero", "date,amount\n", CSVMapping{Date: "0", Amount: "amount"}, "column for date must be 1 or greater"},
+		{"long delimiter", "date,amount\n", CSVMapping{Date: "date", Amount: "amount", Delimiter: ";;"}, "delimiter must be a single character"},
+		{"date format without a day", "date,amount\n", CSVMapping{Date: "date", Amount: "amount", DateFormat: "MM/YYYY"}, `date format "MM/YYYY" must contain a year (YYYY), month (MM) and day (DD)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(tt.file), tt.mapping)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseCSVRowLimit(t *testing.T) {
+	var file strings.Builder
+	file.WriteString("date,amount\n")
+	for i := 0; i <= MaxEntries; i++ {
+		file.WriteString("2024-05-01,1\n")
+	}
+
+	_, err := ParseCSV(strings.NewReader(file.String()), CSVMapping{Date: "date", Amount: "amount"})
+	if err == nil {
+		t.Fatalf("got no error for %d rows", MaxEntries+1)
+	}
+}
+
+func date(value string) time.Time {
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func money(value string) domain.Money {
+	amount, err := domain.ParseMoney(value)
+	if err != nil {
+		panic(err)
+	}
+	return amount
+}
@@ -0,0 +1,31 @@
+// Package importer reads transactions from files exported by spreadsheets
+// and banks.
+package importer
+
+import (
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// MaxEntries bounds how many lines are read from a single file
+const MaxEntries = 10000
+
+// Entry is one imported line mapped onto transaction fields. Lines that could
+// not be read keep their Errors and are not imported.
+type Entry struct {
+	Line        int                    `json:"line"`
+	Date        time.Time              `json:"date"`
+	Amount      domain.Money           `json:"amount"` // Always positive; Type gives the direction
+	Type        domain.TransactionType `json:"type"`
+	Category    string                 `json:"category"`
+	Description string                 `json:"description"`
+	Wallet      string                 `json:"wallet,omitempty"`      // Wallet name or ID as written in the file
+	ExternalID  string                 `json:"external_id,omitempty"` // Bank reference used to skip entries imported before
+	Errors      []string               `json:"errors,omitempty"`
+}
+
+func (e *Entry) addError(format string, args ...interface{}) {
+	e.Errors = append(e.Errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"go-moneyku/internal/domain"
+)
+
+// DefaultDateFormat is used when no date format is configured
+const DefaultDateFormat = "YYYY-MM-DD"
+
+// dateTokens translates the date format tokens users know into Go layout
+// elements. Longer tokens come first so that "YYYY" is not read as two "YY".
+var dateTokens = []struct {
+	token  string
+	layout string
+}{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MMMM", "January"},
+	{"MMM", "Jan"},
+	{"MM", "01"},
+	{"M", "1"},
+	{"DD", "02"},
+	{"D", "2"},
+	{"HH", "15"},
+	{"mm", "04"},
+	{"ss", "05"},
+}
+
+// DateLayout converts a format such as "DD/MM/YYYY" or "YYYY-MM-DD HH:mm"
+// into a Go time layout
+func DateLayout(format string) (string, error) {
+	if format == "" {
+		format = DefaultDateFormat
+	}
+
+	var layout strings.Builder
+	hasYear, hasMonth, hasDay := false, false, false
+	for rest := format; rest != ""; {
+		matched := false
+		for _, t := range dateTokens {
+			if strings.HasPrefix(rest, t.token) {
+				layout.WriteString(t.layout)
+				rest = rest[len(t.token):]
+				matched = true
+
+				switch t.token[0] {
+				case 'Y':
+					hasYear = true
+				case 'M':
+					hasMonth = true
+				case 'D':
+					hasDay = true
+				}
+				break
+			}
+		}
+		if !matched {
+			layout.WriteByte(rest[0])
+			rest = rest[1:]
+		}
+	}
+
+	if !hasYear || !hasMonth || !hasDay {
+		return "", fmt.Errorf("date format %q must contain a year (YYYY), month (MM) and day (DD)", format)
+	}
+	return layout.String(), nil
+}
+
+// ParseAmount reads a formatted number such as "1.250.000,00" (decimal
+// separator ","), "1,250,000.00" (decimal separator "."), "Rp 15.000" or
+// "(2.500)". The other separator is taken to group thousands.
+func ParseAmount(value string, decimalSeparator string) (domain.Money, error) {
+	input := strings.TrimSpace(value)
+	s := input
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+
+	// Currency markers and spaces carry no value. One sign may stand on either
+	// side of the marker, as in "-Rp 2.500" and "Rp -2.500".
+	signed := false
+	for i := 0; i < 2; i++ {
+		s = strings.TrimSpace(s)
+		if !signed && strings.HasPrefix(s, "-") {
+			negative, signed = !negative, true
+			s = s[1:]
+		} else if !signed && strings.HasSuffix(s, "-") {
+			negative, signed = !negative, true
+			s = s[:len(s)-1]
+		}
+		for _, marker := range []string{"Rp.", "Rp", "IDR", "rp"} {
+			s = strings.TrimPrefix(strings.TrimSpace(s), marker)
+		}
+	}
+	s = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\u00a0' {
+			return -1
+		}
+		return r
+	}, s)
+
+	thousandsSeparator := "."
+	if decimalSeparator == "." {
+		thousandsSeparator = ","
+	} else {
+		decimalSeparator = ","
+	}
+
+	s = strings.ReplaceAll(s, thousandsSeparator, "")
+	s = strings.Replace(s, decimalSeparator, ".", 1)
+
+	amount, err := domain.ParseMoney(s)
+	if err != nil || strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		return domain.Money{}, fmt.Errorf("invalid amount %q", input)
+	}
+	if negative {
+		amount = amount.Neg()
+	}
+	return amount, nil
+}
+
+// ParseTransactionType reads the direction of a transaction written in
+// English or Indonesian, or as a bank debit/credit marker
+func ParseTransactionType(value string) (domain.TransactionType, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "income", "pemasukan", "masuk", "credit", "cr", "kredit", "k", "in":
+		return domain.TransactionTypeIncome, nil
+	case "expense", "pengeluaran", "keluar", "debit", "db", "d", "out":
+		return domain.TransactionTypeExpense, nil
+	}
+	return "", fmt.Errorf("unknown transaction type %q (use income or expense)", value)
+}
@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value            string
+		decimalSeparator string
+		want             string
+		wantErr          bool
+	}{
+		{value: "1.250.000,00", decimalSeparator: ",", want: "1250000.00"},
+		{value: "1.250.000", decimalSeparator: ",", want: "1250000.00"},
+		{value: "15.000,5", decimalSeparator: ",", want: "15000.50"},
+		{value: "0,75", decimalSeparator: ",", want: "0.75"},
+		{value: "1,250,000.00", decimalSeparator: ".", want: "1250000.00"},
+		{value: "1250000.25", decimalSeparator: ".", want: "1250000.25"},
+		{value: "12.5", decimalSeparator: "", want: "125.00"}, // "," is the default, so "." groups thousands
+		{value: "Rp 15.000", decimalSeparator: ",", want: "15000.00"},
+		{value: "Rp. 15.000", decimalSeparator: ",", want: "15000.00"},
+		{value: "Rp15.000", decimalSeparator: ",", want: "15000.00"},
+		{value: "rp 15.000", decimalSeparator: ",", want: "15000.00"},
+		{value: "IDR 15.000", decimalSeparator: ",", want: "15000.00"},
+		{value: "1 250 000", decimalSeparator: ",", want: "1250000.00"},
+		{value: "1 250 000", decimalSeparator: ",", want: "1250000.00"},
+		{value: "  42  ", decimalSeparator: ",", want: "42.00"},
+		{value: "-2.500", decimalSeparator: ",", want: "-2500.00"},
+		{value: "2.500-", decimalSeparator: ",", want: "-2500.00"},
+		{value: "(2.500)", decimalSeparator: ",", want: "-2500.00"},
+		{value: "( 2.500 )", decimalSeparator: ",", want: "-2500.00"},
+		{value: "(Rp 2.500)", decimalSeparator: ",", want: "-2500.00"},
+		{value: "-Rp 2.500", decimalSeparator: ",", want: "-2500.00"},
+		{value: "Rp -2.500", decimalSeparator: ",", want: "-2500.00"},
+		{value: "(-2.500)", decimalSeparator: ",", want: "2500.00"},
+
+		{value: "", decimalSeparator: ",", wantErr: true},
+		{value: "Rp", decimalSeparator: ",", wantErr: true},
+		{value: "abc", decimalSeparator: ",", wantErr: true},
+		{value: "1,2,3", decimalSeparator: ",", wantErr: true},
+		{value: "--5", decimalSeparator: ",", wantErr: true},
+		{value: "+5", decimalSeparator: ",", wantErr: true},
+		{value: "()", decimalSeparator: ",", wantErr: true},
+		{value: "12,345", decimalSeparator: ",", wantErr: true}, // More decimals than the currency has
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			amount, err := ParseAmount(tt.value, tt.decimalSeparator)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %s, want an error", amount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := amount.String(); got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateLayout(t *testing.T) {
+	tests := []struct {
+		format  string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{format: "", value: "2024-05-01", want: "2024-05-01"},
+		{format: "YYYY-MM-DD", value: "2024-05-01", want: "2024-05-01"},
+		{format: "DD/MM/YYYY", value: "01/05/2024", want: "2024-05-01"},
+		{format: "MM/DD/YYYY", value: "05/01/2024", want: "2024-05-01"},
+		{format: "D/M/YYYY", value: "1/5/2024", want: "2024-05-01"},
+		{format: "DD-MM-YY", value: "01-05-24", want: "2024-05-01"},
+		{format: "DD MMM YYYY", value: "01 May 2024", want: "2024-05-01"},
+		{format: "DD MMMM YYYY", value: "01 May 2024", want: "2024-05-01"},
+		{format: "YYYY-MM-DD HH:mm", value: "2024-05-01 13:45", want: "2024-05-01 13:45"},
+		{format: "YYYY-MM-DD HH:mm:ss", value: "2024-05-01 13:45:30", want: "2024-05-01 13:45"},
+		{format: "YYYYMMDD", value: "20240501", want: "2024-05-01"},
+
+		{format: "MM/YYYY", wantErr: true},
+		{format: "DD/MM", wantErr: true},
+		{format: "DD/YYYY", wantErr: true},
+		{format: "mm:ss", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			layout, err := DateLayout(tt.format)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got layout %q, want an error", layout)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			date, err := time.Parse(layout, tt.value)
+			if err != nil {
+				t.Fatalf("layout %q cannot read %q: %v", layout, tt.value, err)
+			}
+			got := date.Format("2006-01-02")
+			if date.Hour() != 0 || date.Minute() != 0 {
+				got = date.Format("2006-01-02 15:04")
+			}
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package importer
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// Options tunes how statement values are read, for files whose formats are
+// not fully fixed by the standard
+type Options struct {
+	DateFormat       string // Order of day, month and year, e.g. "DD/MM/YYYY"
+	DecimalSeparator string // "." or ","
+}
+
+// Statement is the content of a bank statement file
+type Statement struct {
+	Entries []Entry
+	// ClosingBalance is the balance the bank reports at ClosingDate, when the
+	// format carries one
+	ClosingBalance *domain.Money
+	ClosingDate    *time.Time
+	Currency       string
+}
+
+// Importer parses one statement file format
+type Importer interface {
+	Parse(r io.Reader, options Options) (*Statement, error)
+}
+
+// importers holds the supported statement formats by name. QFX is Quicken's
+// name for OFX.
+var importers = map[string]Importer{
+	"ofx": ofxImporter{},
+	"qfx": ofxImporter{},
+	"qif": qifImporter{},
+}
+
+// ForFormat returns the importer for a format such as "ofx" or "qif"
+func ForFormat(format string) (Importer, bool) {
+	importer, ok := importers[strings.ToLower(format)]
+	return importer, ok
+}
+
+// Formats lists the supported statement formats
+func Formats() []string {
+	formats := make([]string, 0, len(importers))
+	for format := range importers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
@@ -0,0 +1,238 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// ofxImporter reads OFX and QFX statements, both the SGML based OFX 1.x
+// (where leaf elements have no closing tag) and the XML based OFX 2.x
+type ofxImporter struct{}
+
+// ofxElement is an opening or closing tag together with the text after it
+type ofxElement struct {
+	tag     string
+	closing bool
+	value   string
+}
+
+func (ofxImporter) Parse(r io.Reader, options Options) (*Statement, error) {
+	elements, err := tokenizeOFX(r)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &Statement{}
+	var entry *Entry
+	var path []string
+	var balanceAmount, balanceDate string
+	transactionCount := 0
+
+	for _, element := range elements {
+		if element.closing {
+			// Leaf elements in XML close as well; only aggregates are on the path
+			for i := len(path) - 1; i >= 0; i-- {
+				if path[i] == element.tag {
+					path = path[:i]
+					break
+				}
+			}
+			if element.tag == "STMTTRN" && entry != nil {
+				transactionCount++
+				if transactionCount > MaxEntries {
+					return nil, fmt.Errorf("statement has more than %d transactions", MaxEntries)
+				}
+				finishOFXEntry(entry)
+				statement.Entries = append(statement.Entries, *entry)
+				entry = nil
+			}
+			continue
+		}
+
+		if element.value == "" {
+			path = append(path, element.tag)
+			if element.tag == "STMTTRN" {
+				entry = &Entry{Line: transactionCount + 1}
+			}
+			continue
+		}
+
+		switch {
+		case entry != nil:
+			readOFXTransactionField(entry, element, options)
+		case element.tag == "CURDEF":
+			statement.Currency = element.value
+		case inPath(path, "LEDGERBAL") && element.tag == "BALAMT":
+			balanceAmount = element.value
+		case inPath(path, "LEDGERBAL") && element.tag == "DTASOF":
+			balanceDate = element.value
+		}
+	}
+
+	if balanceAmount != "" {
+		amount, err := ParseAmount(balanceAmount, ofxDecimalSeparator(balanceAmount, options))
+		if err != nil {
+			return nil, fmt.Errorf("invalid closing balance: %w", err)
+		}
+		statement.ClosingBalance = &amount
+		if date, err := parseOFXDate(balanceDate); err == nil {
+			statement.ClosingDate = &date
+		}
+	}
+
+	if len(statement.Entries) == 0 && statement.ClosingBalance == nil {
+		return nil, fmt.Errorf("no OFX statement found in file")
+	}
+
+	return statement, nil
+}
+
+func readOFXTransactionField(entry *Entry, element ofxElement, options Options) {
+	switch element.tag {
+	case "DTPOSTED":
+		date, err := parseOFXDate(element.value)
+		if err != nil {
+			entry.addError("%v", err)
+			return
+		}
+		entry.Date = date
+	case "TRNAMT":
+		value := strings.TrimPrefix(element.value, "+")
+		amount, err := ParseAmount(value, ofxDecimalSeparator(value, options))
+		if err != nil {
+			entry.addError("%v", err)
+			return
+		}
+		entry.Amount = amount
+	case "FITID":
+		entry.ExternalID = element.value
+	case "REFNUM", "CHECKNUM":
+		if entry.ExternalID == "" {
+			entry.ExternalID = element.value
+		}
+	case "NAME", "PAYEE":
+		entry.Description = joinDescription(element.value, entry.Description)
+	case "MEMO":
+		entry.Description = joinDescription(entry.Description, element.value)
+	}
+}
+
+// finishOFXEntry derives the transaction type from the sign of the amount and
+// checks the required fields
+func finishOFXEntry(entry *Entry) {
+	if entry.Date.IsZero() && len(entry.Errors) == 0 {
+		entry.addError("transaction has no posting date")
+	}
+	if entry.Amount.IsZero() {
+		entry.addError("amount must not be zero")
+	}
+
+	entry.Type = domain.TransactionTypeIncome
+	if entry.Amount.IsNegative() {
+		entry.Type = domain.TransactionTypeExpense
+		entry.Amount = entry.Amount.Neg()
+	}
+}
+
+// tokenizeOFX splits the body of an OFX file into elements. The header
+// before the <OFX> root is skipped.
+func tokenizeOFX(r io.Reader) ([]ofxElement, error) {
+	reader := bufio.NewReader(r)
+
+	var elements []ofxElement
+	started := false
+	for {
+		text, err := reader.ReadString('<')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read OFX: %w", err)
+		}
+
+		if started && len(elements) > 0 {
+			value := strings.TrimSpace(strings.TrimSuffix(text, "<"))
+			elements[len(elements)-1].value = html.UnescapeString(value)
+		}
+		if err == io.EOF {
+			break
+		}
+
+		tag, err := reader.ReadString('>')
+		if err != nil {
+			return nil, fmt.Errorf("unterminated OFX tag")
+		}
+		tag = strings.TrimSpace(strings.TrimSuffix(tag, ">"))
+
+		// Processing instructions and comments of OFX 2.x carry no data
+		if strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!") {
+			continue
+		}
+		tag = strings.ToUpper(strings.Fields(tag + " ")[0])
+		if tag == "OFX" {
+			started = true
+		}
+		if !started {
+			continue
+		}
+
+		element := ofxElement{tag: strings.TrimPrefix(tag, "/")}
+		element.closing = strings.HasPrefix(tag, "/")
+		elements = append(elements, element)
+	}
+
+	if !started {
+		return nil, fmt.Errorf("file is not an OFX statement")
+	}
+	return elements, nil
+}
+
+// parseOFXDate reads the date part of an OFX timestamp such as
+// "20260131120000.000[+7:WIB]". The time of day is dropped, because banks
+// are inconsistent about the time zone it is given in.
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
+
+// ofxDecimalSeparator uses the configured separator, or guesses it from the
+// value: OFX requires ".", but some banks write ","
+func ofxDecimalSeparator(value string, options Options) string {
+	if options.DecimalSeparator != "" {
+		return options.DecimalSeparator
+	}
+	if strings.Contains(value, ",") && !strings.Contains(value, ".") {
+		return ","
+	}
+	return "."
+}
+
+func inPath(path []string, tag string) bool {
+	for _, element := range path {
+		if element == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func joinDescription(first, second string) string {
+	first, second = strings.TrimSpace(first), strings.TrimSpace(second)
+	switch {
+	case first == "":
+		return second
+	case second == "" || strings.EqualFold(first, second):
+		return first
+	default:
+		return first + " - " + second
+	}
+}
@@ -0,0 +1,181 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-moneyku/internal/domain"
+)
+
+func TestOFXParse(t *testing.T) {
+	closingBalance := money("25000000")
+	closingDate := date("2024-05-31")
+	want := &Statement{
+		Entries: []Entry{
+			{Line: 1, Date: date("2024-05-01"), Amount: money("15000000"), Type: domain.TransactionTypeIncome, Description: "GAJI MEI - PT MAJU JAYA", ExternalID: "240501001"},
+			{Line: 2, Date: date("2024-05-03"), Amount: money("45500"), Type: domain.TransactionTypeExpense, Description: "Toko & Warung", ExternalID: "240503002"},
+			{Line: 3, Date: date("2024-05-10"), Amount: money("6500"), Type: domain.TransactionTypeExpense, Description: "BIAYA ADMIN", ExternalID: "REF-77"},
+			{Line: 4, Date: date("2024-05-15"), Amount: money("250000.50"), Type: domain.TransactionTypeExpense, Description: "PLN", ExternalID: "240515004"},
+			{Line: 5, Amount: money("1000"), Type: domain.TransactionTypeExpense, ExternalID: "240599005", Errors: []string{`invalid date "2024"`}},
+		},
+		ClosingBalance: &closingBalance,
+		ClosingDate:    &closingDate,
+		Currency:       "IDR",
+	}
+
+	// OFX 1.x (SGML) and 2.x (XML) hold the same statement
+	for _, fixture := range []string{"statement_sgml.ofx", "statement_xml.ofx"} {
+		t.Run(fixture, func(t *testing.T) {
+			statement := parseFixture(t, "ofx", fixture, Options{})
+			if !reflect.DeepEqual(statement, want) {
+				t.Fatalf("got  %+v\nwant %+v", statement, want)
+			}
+		})
+	}
+}
+
+func TestOFXParseRejectsFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr string
+	}{
+		{"not OFX", "Date,Amount\n2024-05-01,100\n", "file is not an OFX statement"},
+		{"unterminated tag", "<OFX><STMTTRN", "unterminated OFX tag"},
+		{"no statement", "<OFX><SIGNONMSGSRSV1></SIGNONMSGSRSV1></OFX>", "no OFX statement found in file"},
+		{"invalid closing balance", "<OFX><LEDGERBAL><BALAMT>abc<DTASOF>20240531</LEDGERBAL></OFX>", `invalid closing balance: invalid amount "abc"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ofxImporter{}.Parse(strings.NewReader(tt.file), Options{})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenizeOFX(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []ofxElement
+	}{
+		{
+			name: "SGML leaves without closing tags",
+			file: "OFXHEADER:100\nDATA:OFXSGML\n\n<OFX>\n<STMTTRN>\n<TRNAMT>-10.00\n<NAME>A &amp; B\n</STMTTRN>\n</OFX>\n",
+			want: []ofxElement{
+				{tag: "OFX"},
+				{tag: "STMTTRN"},
+				{tag: "TRNAMT", value: "-10.00"},
+				{tag: "NAME", value: "A & B"},
+				{tag: "STMTTRN", closing: true},
+				{tag: "OFX", closing: true},
+			},
+		},
+		{
+			name: "XML with declarations, comments and lower case tags",
+			file: "<?xml version=\"1.0\"?>\n<?OFX OFXHEADER=\"200\"?>\n<ofx>\n<!-- comment -->\n<trnamt>5</trnamt>\n<Name id=\"1\"> Toko </Name>\n</ofx>",
+			want: []ofxElement{
+				{tag: "OFX"},
+				{tag: "TRNAMT", value: "5"},
+				{tag: "TRNAMT", closing: true},
+				{tag: "NAME", value: "Toko"},
+				{tag: "NAME", closing: true},
+				{tag: "OFX", closing: true},
+			},
+		},
+		{
+			name: "tags before the root are skipped",
+			file: "<HTML><BODY>x</BODY></HTML><OFX>",
+			want: []ofxElement{{tag: "OFX"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elements, err := tokenizeOFX(strings.NewReader(tt.file))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(elements, tt.want) {
+				t.Fatalf("got  %+v\nwant %+v", elements, tt.want)
+			}
+		})
+	}
+}
+
+func TestOFXDecimalSeparator(t *testing.T) {
+	tests := []struct {
+		value   string
+		options Options
+		want    string
+	}{
+		{"-1250.50", Options{}, "."},
+		{"-1250,50", Options{}, ","},
+		{"1,250.50", Options{}, "."},
+		{"1250", Options{}, "."},
+		{"1.250,50", Options{}, "."}, // Ambiguous values keep the OFX standard
+		{"1.250,50", Options{DecimalSeparator: ","}, ","},
+		{"1250,50", Options{DecimalSeparator: "."}, "."},
+	}
+
+	for _, tt := range tests {
+		if got := ofxDecimalSeparator(tt.value, tt.options); got != tt.want {
+			t.Errorf("ofxDecimalSeparator(%q, %+v) = %q, want %q", tt.value, tt.options, got, tt.want)
+		}
+	}
+}
+
+func TestParseOFXDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string // Empty when the date is invalid
+	}{
+		{"20240131", "2024-01-31"},
+		{"20240131120000", "2024-01-31"},
+		{"20240131235959.000[+7:WIB]", "2024-01-31"},
+		{"20240229", "2024-02-29"},
+		{"20230229", ""},
+		{"2024013", ""},
+		{"2024-01-31", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := parseOFXDate(tt.value)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("parseOFXDate(%q) = %v, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(date(tt.want)) {
+			t.Errorf("parseOFXDate(%q) = %v, %v, want %s", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+// parseFixture parses a file in testdata with the importer for format
+func parseFixture(t *testing.T, format, name string, options Options) *Statement {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	statementImporter, ok := ForFormat(format)
+	if !ok {
+		t.Fatalf("no importer for %q", format)
+	}
+	statement, err := statementImporter.Parse(file, options)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return statement
+}
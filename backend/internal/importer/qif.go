@@ -0,0 +1,243 @@
+package importer
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// defaultQIFDateFormat is the US date order Quicken writes by default
+const defaultQIFDateFormat = "MM/DD/YYYY"
+
+// qifTransactionTypes are the account types whose records are transactions;
+// other sections such as category lists and memorized payees are skipped
+var qifTransactionTypes = map[string]bool{
+	"bank":  true,
+	"cash":  true,
+	"ccard": true,
+	"oth a": true,
+	"oth l": true,
+}
+
+// qifImporter reads Quicken Interchange Format files. QIF has no transaction
+// IDs and no closing balance, so entries without a check number get a
+// fingerprint of their content as ExternalID.
+type qifImporter struct{}
+
+// qifRecord collects the fields of one record until its "^" terminator
+type qifRecord struct {
+	line                             int
+	date, amount, payee, memo, label string
+	number                           string
+}
+
+func (qifImporter) Parse(r io.Reader, options Options) (*Statement, error) {
+	order, err := qifDateOrder(options.DateFormat)
+	if err != nil {
+		return nil, err
+	}
+	decimalSeparator := options.DecimalSeparator
+	if decimalSeparator == "" {
+		decimalSeparator = "."
+	}
+
+	scanner := bufio.NewScanner(r)
+	statement := &Statement{}
+	seen := make(map[string]int)
+	inTransactions := false
+	sawHeader := false
+	var record *qifRecord
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			header := strings.ToLower(strings.TrimSpace(line[1:]))
+			if strings.HasPrefix(header, "type:") {
+				sawHeader = true
+				inTransactions = qifTransactionTypes[strings.TrimSpace(strings.TrimPrefix(header, "type:"))]
+			} else if header == "account" {
+				inTransactions = false
+			}
+			record = nil
+			continue
+		}
+		if !inTransactions {
+			continue
+		}
+
+		if record == nil {
+			record = &qifRecord{line: lineNumber}
+		}
+		value := strings.TrimSpace(line[1:])
+		switch line[0] {
+		case 'D':
+			record.date = value
+		case 'T', 'U':
+			record.amount = value
+		case 'P':
+			record.payee = value
+		case 'M':
+			record.memo = value
+		case 'L':
+			record.label = value
+		case 'N':
+			record.number = value
+		case '^':
+			if len(statement.Entries) >= MaxEntries {
+				return nil, fmt.Errorf("file has more than %d transactions", MaxEntries)
+			}
+			entry := record.entry(order, decimalSeparator)
+			entry.ExternalID = qifExternalID(record, entry, seen)
+			statement.Entries = append(statement.Entries, entry)
+			record = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read QIF: %w", err)
+	}
+
+	if !sawHeader {
+		return nil, fmt.Errorf("file is not a QIF file (missing !Type header)")
+	}
+	return statement, nil
+}
+
+func (record *qifRecord) entry(order string, decimalSeparator string) Entry {
+	entry := Entry{Line: record.line}
+
+	if record.date == "" {
+		entry.addError("date is empty")
+	} else if date, err := parseQIFDate(record.date, order); err != nil {
+		entry.addError("%v", err)
+	} else {
+		entry.Date = date
+	}
+
+	amount, err := ParseAmount(record.amount, decimalSeparator)
+	switch {
+	case record.amount == "":
+		entry.addError("amount is empty")
+	case err != nil:
+		entry.addError("%v", err)
+	case amount.IsZero():
+		entry.addError("amount must not be zero")
+	}
+	entry.Type = domain.TransactionTypeIncome
+	if amount.IsNegative() {
+		entry.Type = domain.TransactionTypeExpense
+		amount = amount.Neg()
+	}
+	entry.Amount = amount
+
+	entry.Category = qifCategory(record.label)
+	entry.Description = joinDescription(record.payee, record.memo)
+	return entry
+}
+
+// qifCategory turns "Food:Groceries/Class" into the most specific category
+// name. Transfers between Quicken accounts are written as "[Account]" and
+// carry no category.
+func qifCategory(label string) string {
+	if label == "" || strings.HasPrefix(label, "[") {
+		return ""
+	}
+	if i := strings.Index(label, "/"); i >= 0 {
+		label = label[:i]
+	}
+	parts := strings.Split(label, ":")
+	return strings.TrimSpace(parts[len(parts)-1])
+}
+
+// qifExternalID uses the check number when there is one. Otherwise the
+// entry is identified by its content and by how often the same content came
+// before it in the file, so two identical purchases on one day stay apart.
+func qifExternalID(record *qifRecord, entry Entry, seen map[string]int) string {
+	if _, err := strconv.Atoi(record.number); err == nil {
+		return "N" + record.number
+	}
+
+	content := strings.Join([]string{
+		entry.Date.Format("2006-01-02"),
+		string(entry.Type),
+		entry.Amount.String(),
+		strings.ToLower(record.payee),
+		strings.ToLower(record.memo),
+	}, "|")
+	seen[content]++
+	sum := sha1.Sum([]byte(content + "|" + strconv.Itoa(seen[content])))
+	return "H" + hex.EncodeToString(sum[:10])
+}
+
+// qifDateOrder reduces a format such as "DD/MM/YYYY" to the order of its
+// parts, here "DMY"
+func qifDateOrder(format string) (string, error) {
+	if format == "" {
+		format = defaultQIFDateFormat
+	}
+
+	var order strings.Builder
+	for _, r := range strings.ToUpper(format) {
+		if (r == 'D' || r == 'M' || r == 'Y') && !strings.ContainsRune(order.String(), r) {
+			order.WriteRune(r)
+		}
+	}
+	if order.Len() != 3 {
+		return "", fmt.Errorf("date format %q must contain a year (YYYY), month (MM) and day (DD)", format)
+	}
+	return order.String(), nil
+}
+
+// parseQIFDate reads the loose dates Quicken writes, such as "1/31/2026",
+// " 1/31'26" or "31.01.26". An apostrophe before the year marks the 2000s.
+func parseQIFDate(value string, order string) (time.Time, error) {
+	fields := strings.FieldsFunc(strings.ReplaceAll(value, " ", ""), func(r rune) bool {
+		return r == '/' || r == '-' || r == '.' || r == '\''
+	})
+	if len(fields) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+
+	var day, month, year int
+	for i, part := range order {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", value)
+		}
+		switch part {
+		case 'D':
+			day = n
+		case 'M':
+			month = n
+		case 'Y':
+			year = n
+			if len(fields[i]) <= 2 {
+				year += 1900
+				if n < 70 || strings.Contains(value, "'") {
+					year += 100
+				}
+			}
+		}
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day || int(date.Month()) != month {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
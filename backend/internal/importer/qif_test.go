@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-moneyku/internal/domain"
+)
+
+func TestQIFParse(t *testing.T) {
+	statement := parseFixture(t, "qif", "statement.qif", Options{})
+
+	// Fingerprints are checked by TestQIFExternalIDsAreStable
+	externalIDs := make([]string, len(statement.Entries))
+	for i := range statement.Entries {
+		externalIDs[i] = statement.Entries[i].ExternalID
+		if !strings.HasPrefix(externalIDs[i], "N") {
+			statement.Entries[i].ExternalID = ""
+		}
+	}
+
+	want := []Entry{
+		{Line: 2, Date: date("2024-05-01"), Amount: money("15000000"), Type: domain.TransactionTypeIncome, Category: "Gaji", Description: "Gaji Mei"},
+		{Line: 7, Date: date("2024-05-03"), Amount: money("45500"), Type: domain.TransactionTypeExpense, Category: "Belanja", Description: "Toko Warung - Belanja harian", ExternalID: "N1001"},
+		{Line: 14, Date: date("2024-05-03"), Amount: money("45500"), Type: domain.TransactionTypeExpense, Description: "Toko Warung - Belanja harian"},
+		{Line: 19, Date: date("2024-05-03"), Amount: money("45500"), Type: domain.TransactionTypeExpense, Description: "toko warung - Belanja Harian"},
+		{Line: 24, Date: date("2024-05-10"), Amount: money("6500"), Type: domain.TransactionTypeExpense, Description: "Transfer ke tabungan"},
+		{Line: 30, Amount: money("1"), Type: domain.TransactionTypeExpense, Errors: []string{`invalid date "13/1/2024"`}},
+		{Line: 43, Date: date("2024-05-20"), Amount: money("120000"), Type: domain.TransactionTypeExpense, Description: "Restoran - Makan malam"},
+	}
+	if !reflect.DeepEqual(statement.Entries, want) {
+		t.Fatalf("got  %+v\nwant %+v", statement.Entries, want)
+	}
+	if statement.ClosingBalance != nil || statement.ClosingDate != nil {
+		t.Errorf("QIF has no closing balance, got %v at %v", statement.ClosingBalance, statement.ClosingDate)
+	}
+
+	// Identical purchases on one day, written with different case, must not
+	// collapse into one entry
+	seen := make(map[string]bool)
+	for i, externalID := range externalIDs {
+		if externalID == "" || seen[externalID] {
+			t.Errorf("entry %d has external ID %q, want a unique one", i, externalID)
+		}
+		seen[externalID] = true
+	}
+}
+
+func TestQIFExternalIDsAreStable(t *testing.T) {
+	first := parseFixture(t, "qif", "statement.qif", Options{})
+	second := parseFixture(t, "qif", "statement.qif", Options{})
+
+	// Importing the same file again yields the same IDs, so every entry is
+	// recognized as imported before
+	for i := range first.Entries {
+		if first.Entries[i].ExternalID != second.Entries[i].ExternalID {
+			t.Errorf("entry %d: external ID %q changed to %q", i, first.Entries[i].ExternalID, second.Entries[i].ExternalID)
+		}
+	}
+}
+
+func TestQIFExternalID(t *testing.T) {
+	purchase := Entry{Date: date("2024-05-03"), Amount: money("45500"), Type: domain.TransactionTypeExpense}
+	record := func(number, payee, memo string) *qifRecord {
+		return &qifRecord{number: number, payee: payee, memo: memo}
+	}
+
+	seen := make(map[string]int)
+	first := qifExternalID(record("", "Toko", "Belanja"), purchase, seen)
+	second := qifExternalID(record("", "TOKO", "belanja"), purchase, seen)
+	withText := qifExternalID(record("ATM", "Toko", "Belanja"), purchase, seen)
+	other := qifExternalID(record("", "Toko", "Belanja"), Entry{Date: purchase.Date, Amount: money("45501"), Type: purchase.Type}, seen)
+
+	if got := qifExternalID(record("1001", "Toko", "Belanja"), purchase, seen); got != "N1001" {
+		t.Errorf("check number: got %q, want N1001", got)
+	}
+	if !strings.HasPrefix(first, "H") || len(first) != 21 {
+		t.Errorf("fingerprint %q is not H and 20 hex digits", first)
+	}
+	if first == second || second == withText || first == withText || first == other {
+		t.Errorf("repeated entries share an ID: %q, %q, %q, %q", first, second, withText, other)
+	}
+
+	// The count restarts with every file
+	if again := qifExternalID(record("", "toko", "BELANJA"), purchase, make(map[string]int)); again != first {
+		t.Errorf("fingerprint of a new file = %q, want %q", again, first)
+	}
+}
+
+func TestParseQIFDate(t *testing.T) {
+	tests := []struct {
+		value string
+		order string
+		want  string // Empty when the date is invalid
+	}{
+		{"1/31/2026", "MDY", "2026-01-31"},
+		{"01/31/2026", "MDY", "2026-01-31"},
+		{"1/31'26", "MDY", "2026-01-31"},
+		{" 1/31' 6", "MDY", "2006-01-31"},
+		{"1/31'99", "MDY", "2099-01-31"}, // The apostrophe marks the 2000s
+		{"1/31/99", "MDY", "1999-01-31"},
+		{"1/31/69", "MDY", "2069-01-31"},
+		{"1/31/70", "MDY", "1970-01-31"},
+		{"31.01.26", "DMY", "2026-01-31"},
+		{"31-1-2026", "DMY", "2026-01-31"},
+		{"2026/01/31", "YMD", "2026-01-31"},
+		{"2/29/24", "MDY", "2024-02-29"},
+		{"2/29/23", "MDY", ""},
+		{"31/1/2026", "MDY", ""},
+		{"1/31", "MDY", ""},
+		{"1/31/2026/1", "MDY", ""},
+		{"Jan/31/2026", "MDY", ""},
+		{"", "MDY", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := parseQIFDate(tt.value, tt.order)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("parseQIFDate(%q, %s) = %v, want an error", tt.value, tt.order, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(date(tt.want)) {
+			t.Errorf("parseQIFDate(%q, %s) = %v, %v, want %s", tt.value, tt.order, got, err, tt.want)
+		}
+	}
+}
+
+func TestQIFDateOrder(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{"", "MDY", false},
+		{"DD/MM/YYYY", "DMY", false},
+		{"yyyy-mm-dd", "YMD", false},
+		{"MM/YYYY", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := qifDateOrder(tt.format)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("qifDateOrder(%q) = %q, %v, want %q", tt.format, got, err, tt.want)
+		}
+	}
+}
+
+func TestQIFCategory(t *testing.T) {
+	tests := map[string]string{
+		"":                       "",
+		"Gaji":                   "Gaji",
+		"Makanan:Belanja":        "Belanja",
+		"Makanan:Belanja/Rumah":  "Belanja",
+		"Makanan : Restoran ":    "Restoran",
+		"[Tabungan]":             "",
+		"[Tabungan]/Rumah":       "",
+		"Transportasi/Kantor:Ab": "Transportasi",
+	}
+
+	for label, want := range tests {
+		if got := qifCategory(label); got != want {
+			t.Errorf("qifCategory(%q) = %q, want %q", label, got, want)
+		}
+	}
+}
+
+func TestQIFParseRejectsFile(t *testing.T) {
+	if _, err := (qifImporter{}).Parse(strings.NewReader("D1/1/2024\nT-1\n^\n"), Options{}); err == nil {
+		t.Error("got no error for a file without a !Type header")
+	}
+	if _, err := (qifImporter{}).Parse(strings.NewReader("!Type:Bank\n"), Options{DateFormat: "YYYY"}); err == nil {
+		t.Error("got no error for a date format without day and month")
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"strings"
 
 	"go-moneyku/internal/utils"
@@ -8,8 +9,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// SessionChecker returns an error unless the session of an access token is
+// still active, so that logged out tokens are rejected before they expire
+type SessionChecker interface {
+	CheckSession(ctx context.Context, userID int, sessionID int) error
+}
+
 // AuthMiddleware validates JWT token and sets user info in context
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(sessions SessionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -37,9 +44,17 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens of sessions that have been logged out
+		if err := sessions.CheckSession(c.Request.Context(), claims.UserID, claims.SessionID); err != nil {
+			utils.ServiceErrorResponse(c, err)
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("session_id", claims.SessionID)
 
 		c.Next()
 	}
@@ -53,3 +68,12 @@ func GetUserID(c *gin.Context) (int, bool) {
 	}
 	return userID.(int), true
 }
+
+// GetSessionID extracts the session ID of the access token from context
+func GetSessionID(c *gin.Context) (int, bool) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		return 0, false
+	}
+	return sessionID.(int), true
+}
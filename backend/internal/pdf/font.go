@@ -0,0 +1,83 @@
+package pdf
+
+import "strings"
+
+// Advance widths of the printable ASCII characters (32 to 126) in 1/1000 of
+// the font size, from the Adobe font metrics of the standard fonts
+var fontWidths = [][95]int{
+	Helvetica: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	HelveticaBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// defaultWidth is used for characters outside printable ASCII
+const defaultWidth = 556
+
+// winAnsi maps the characters of Windows-1252 that differ from Latin-1
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// encode converts text to WinAnsiEncoding, the encoding the fonts are
+// declared with. Characters it cannot represent become "?".
+func encode(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			out = append(out, ' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			out = append(out, byte(r))
+		default:
+			if b, ok := winAnsi[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+// TextWidth returns the width of text in points
+func TextWidth(font Font, size float64, text string) float64 {
+	total := 0
+	for _, b := range encode(text) {
+		if b >= 32 && b <= 126 {
+			total += fontWidths[font][b-32]
+		} else {
+			total += defaultWidth
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Truncate shortens text with "..." so that it fits in width
+func Truncate(font Font, size float64, text string, width float64) string {
+	if TextWidth(font, size, text) <= width {
+		return text
+	}
+	runes := []rune(strings.TrimSpace(text))
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimSpace(string(runes)) + "..."
+		if TextWidth(font, size, candidate) <= width {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica
+// fonts, lines and filled rectangles. The standard fonts are built into every
+// PDF reader, so no font files are needed and documents can be generated
+// offline.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A4 page size in points (1/72 inch)
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+// fontNames are the PostScript names of the fonts, in resource order
+var fontNames = []string{"Helvetica", "Helvetica-Bold"}
+
+// Document is a PDF document whose pages all have the same size
+type Document struct {
+	width, height float64
+	pages         []*Page
+}
+
+func New(width, height float64) *Document {
+	return &Document{width: width, height: height}
+}
+
+// AddPage appends an empty page and returns it
+func (d *Document) AddPage() *Page {
+	page := &Page{height: d.height}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Pages returns the pages added so far
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// Page collects the drawing operations of one page. Coordinates are in points
+// from the top left corner, with y growing downwards.
+type Page struct {
+	height  float64
+	content bytes.Buffer
+}
+
+// Text draws text with its baseline at y, starting at x
+func (p *Page) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (", font+1, number(size), number(x), number(p.height-y))
+	p.content.Write(escape(encode(text)))
+	p.content.WriteString(") Tj ET\n")
+}
+
+// TextRight draws text that ends at x
+func (p *Page) TextRight(x, y float64, font Font, size float64, text string) {
+	p.Text(x-TextWidth(font, size, text), y, font, size, text)
+}
+
+// Line draws a straight black line
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w 0 G %s %s m %s %s l S\n",
+		number(width), number(x1), number(p.height-y1), number(x2), number(p.height-y2))
+}
+
+// FillRect fills a rectangle with a shade of gray, from 0 (black) to 1 (white)
+func (p *Page) FillRect(x, y, width, height, gray float64) {
+	fmt.Fprintf(&p.content, "%s g %s %s %s %s re f 0 g\n",
+		number(gray), number(x), number(p.height-y-height), number(width), number(height))
+}
+
+// WriteTo writes the document as a PDF file
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 and 2 are the catalog and the page tree, followed by one
+	// object per font and two per page: the page and its content stream
+	firstPage := 3 + len(fontNames)
+	kids := make([]byte, 0, len(d.pages)*8)
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R ", firstPage+2*i)...)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>",
+		bytes.TrimSpace(kids), len(d.pages), number(d.width), number(d.height)))
+
+	var fonts bytes.Buffer
+	for i, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fmt.Fprintf(&fonts, "/F%d %d 0 R ", i+1, 3+i)
+	}
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			fonts.String(), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.WriteTo(w)
+}
+
+// number formats a coordinate with at most two decimals
+func number(value float64) string {
+	// FormatFloat always writes the two decimals, so only those are trimmed
+	s := strconv.FormatFloat(value, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// escape writes text as the body of a PDF string literal
+func escape(text []byte) []byte {
+	var out bytes.Buffer
+	for _, b := range text {
+		switch {
+		case b == '\\' || b == '(' || b == ')':
+			out.WriteByte('\\')
+			out.WriteByte(b)
+		case b < 0x20 || b >= 0x80:
+			fmt.Fprintf(&out, "\\%03o", b)
+		default:
+			out.WriteByte(b)
+		}
+	}
+	return out.Bytes()
+}
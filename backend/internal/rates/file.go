@@ -0,0 +1,91 @@
+// Package rates provides exchange rate sources for the rate sync job
+package rates
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// FileProvider reads rates from a CSV file with the columns
+// date,base,quote,rate, for example "2024-05-01,USD,IDR,16250". Lines starting
+// with "#" and a header line are skipped. The file is read again on every
+// fetch, so rates can be added by editing it.
+type FileProvider struct {
+	path string
+}
+
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+func (p *FileProvider) Name() string {
+	return "file"
+}
+
+// FetchRates reads a local file, which does not wait on anything the context
+// could cancel
+func (p *FileProvider) FetchRates(_ context.Context) ([]domain.ExchangeRate, error) {
+	file, err := os.Open(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rates file: %w", err)
+	}
+	defer file.Close()
+
+	rates, err := parseRates(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p.path, err)
+	}
+	return rates, nil
+}
+
+func parseRates(r io.Reader) ([]domain.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	var rates []domain.ExchangeRate
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if first && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date %q, expected YYYY-MM-DD", line, record[0])
+		}
+		rate, err := domain.ParseRate(record[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		base := strings.ToUpper(strings.TrimSpace(record[1]))
+		quote := strings.ToUpper(strings.TrimSpace(record[2]))
+		if base == "" || quote == "" || base == quote {
+			return nil, fmt.Errorf("line %d: base and quote must be two different currencies", line)
+		}
+
+		rates = append(rates, domain.ExchangeRate{
+			Base:  base,
+			Quote: quote,
+			Date:  date,
+			Rate:  rate,
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+type budgetRepository struct {
+	db DBTX
+}
+
+func NewBudgetRepository(db DBTX) domain.BudgetRepository {
+	return &budgetRepository{db: db}
+}
+
+func (r *budgetRepository) Create(ctx context.Context, budget *domain.Budget) error {
+	query := `
+		INSERT INTO budgets (user_id, category_id, name, amount, period, start_day, period_days, start_date, rollover, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	now := time.Now()
+	budget.CreatedAt = now
+	budget.UpdatedAt = now
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		budget.UserID,
+		budget.CategoryID,
+		budget.Name,
+		budget.Amount,
+		budget.Period,
+		budget.StartDay,
+		budget.PeriodDays,
+		budget.StartDate,
+		budget.Rollover,
+		budget.CreatedAt,
+		budget.UpdatedAt,
+	).Scan(&budget.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return nil
+}
+
+func (r *budgetRepository) FindByUserID(ctx context.Context, userID int) ([]domain.Budget, error) {
+	query := `
+		SELECT id, user_id, category_id, name, amount, period, start_day, period_days, start_date, rollover, created_at, updated_at
+		FROM budgets
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var budgets []domain.Budget
+	for rows.Next() {
+		var budget domain.Budget
+		if err := scanBudget(rows, &budget); err != nil {
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+		budgets = append(budgets, budget)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch budgets: %w", err)
+	}
+
+	return budgets, nil
+}
+
+func (r *budgetRepository) FindByID(ctx context.Context, id int) (*domain.Budget, error) {
+	query := `
+		SELECT id, user_id, category_id, name, amount, period, start_day, period_days, start_date, rollover, created_at, updated_at
+		FROM budgets
+		WHERE id = $1
+	`
+
+	budget := &domain.Budget{}
+	if err := scanBudget(r.db.QueryRow(ctx, query, id), budget); err != nil {
+		return nil, notFound("budget", err)
+	}
+
+	return budget, nil
+}
+
+func (r *budgetRepository) Update(ctx context.Context, budget *domain.Budget) error {
+	query := `
+		UPDATE budgets
+		SET category_id = $1, name = $2, amount = $3, period = $4, start_day = $5, period_days = $6,
+			start_date = $7, rollover = $8, updated_at = $9
+		WHERE id = $10
+	`
+
+	budget.UpdatedAt = time.Now()
+
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		budget.CategoryID,
+		budget.Name,
+		budget.Amount,
+		budget.Period,
+		budget.StartDay,
+		budget.PeriodDays,
+		budget.StartDate,
+		budget.Rollover,
+		budget.UpdatedAt,
+		budget.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	return nil
+}
+
+func (r *budgetRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM budgets WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	return nil
+}
+
+func scanBudget(row interface {
+	Scan(dest ...interface{}) error
+}, budget *domain.Budget) error {
+	return row.Scan(
+		&budget.ID,
+		&budget.UserID,
+		&budget.CategoryID,
+		&budget.Name,
+		&budget.Amount,
+		&budget.Period,
+		&budget.StartDay,
+		&budget.PeriodDays,
+		&budget.StartDate,
+		&budget.Rollover,
+		&budget.CreatedAt,
+		&budget.UpdatedAt,
+	)
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// categoryNameIndex keeps category names unique per user and type, ignoring case
+const categoryNameIndex = "idx_categories_user_type_name"
+
+var errCategoryExists = domain.Conflict("category_exists", "category already exists")
+
+type categoryRepository struct {
+	db DBTX
+}
+
+func NewCategoryRepository(db DBTX) domain.CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	query := `
+		INSERT INTO categories (user_id, parent_id, name, type, icon, color, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	now := time.Now()
+	category.CreatedAt = now
+	category.UpdatedAt = now
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		category.UserID,
+		category.ParentID,
+		category.Name,
+		category.Type,
+		category.Icon,
+		category.Color,
+		category.CreatedAt,
+		category.UpdatedAt,
+	).Scan(&category.ID)
+
+	if isUniqueViolation(err, categoryNameIndex) {
+		return errCategoryExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return nil
+}
+
+func (r *categoryRepository) FindOrCreate(ctx context.Context, category *domain.Category) error {
+	query := `
+		INSERT INTO categories (user_id, parent_id, name, type, icon, color, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, type, LOWER(name)) DO NOTHING
+		RETURNING id
+	`
+
+	now := time.Now()
+	category.CreatedAt = now
+	category.UpdatedAt = now
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		category.UserID,
+		category.ParentID,
+		category.Name,
+		category.Type,
+		category.Icon,
+		category.Color,
+		category.CreatedAt,
+		category.UpdatedAt,
+	).Scan(&category.ID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The category exists, possibly just committed by a concurrent
+		// transaction that the insert waited for
+		existing, err := r.FindByName(ctx, category.UserID, category.Type, category.Name)
+		if err != nil {
+			return err
+		}
+		*category = *existing
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return nil
+}
+
+func (r *categoryRepository) FindByUserID(ctx context.Context, userID int) ([]domain.Category, error) {
+	query := `
+		SELECT id, user_id, parent_id, name, type, icon, color, created_at, updated_at
+		FROM categories
+		WHERE user_id = $1
+		ORDER BY type, name
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []domain.Category
+	for rows.Next() {
+		var category domain.Category
+		err := rows.Scan(
+			&category.ID,
+			&category.UserID,
+			&category.ParentID,
+			&category.Name,
+			&category.Type,
+			&category.Icon,
+			&category.Color,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (r *categoryRepository) FindByID(ctx context.Context, id int) (*domain.Category, error) {
+	query := `
+		SELECT id, user_id, parent_id, name, type, icon, color, created_at, updated_at
+		FROM categories
+		WHERE id = $1
+	`
+
+	category := &domain.Category{}
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&category.ID,
+		&category.UserID,
+		&category.ParentID,
+		&category.Name,
+		&category.Type,
+		&category.Icon,
+		&category.Color,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, notFound("category", err)
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepository) FindByName(ctx context.Context, userID int, categoryType domain.CategoryType, name string) (*domain.Category, error) {
+	query := `
+		SELECT id, user_id, parent_id, name, type, icon, color, created_at, updated_at
+		FROM categories
+		WHERE user_id = $1 AND type = $2 AND LOWER(name) = LOWER(TRIM($3))
+	`
+
+	category := &domain.Category{}
+	err := r.db.QueryRow(ctx, query, userID, categoryType, name).Scan(
+		&category.ID,
+		&category.UserID,
+		&category.ParentID,
+		&category.Name,
+		&category.Type,
+		&category.Icon,
+		&category.Color,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, notFound("category", err)
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	query := `
+		UPDATE categories
+		SET parent_id = $1, name = $2, icon = $3, color = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	category.UpdatedAt = time.Now()
+
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		category.ParentID,
+		category.Name,
+		category.Icon,
+		category.Color,
+		category.UpdatedAt,
+		category.ID,
+	)
+
+	if isUniqueViolation(err, categoryNameIndex) {
+		return errCategoryExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+
+	return nil
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM categories WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	return nil
+}
+
+func (r *categoryRepository) CountChildren(ctx context.Context, id int) (int, error) {
+	query := `SELECT COUNT(*) FROM categories WHERE parent_id = $1`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, id).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count child categories: %w", err)
+	}
+
+	return count, nil
+}
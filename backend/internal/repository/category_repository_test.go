@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go-moneyku/internal/domain"
+)
+
+func TestCategoryFindOrCreateConcurrent(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	uow := NewUnitOfWork(db, 0)
+	userID := testWallet(t, db, domain.NewMoney(0, "IDR")).UserID
+
+	// Every transaction misses the name and then creates it, as
+	// resolveTransactionCategory does
+	names := []string{"Makan", "makan", "MAKAN", "Makan", "mAkAn", "makan", "Makan", "MAKAN"}
+	start := make(chan struct{})
+	ids := make(chan int, len(names))
+	errs := make(chan error, len(names))
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			<-start
+			errs <- uow.Do(ctx, func(repos domain.Repositories) error {
+				if _, err := repos.Categories.FindByName(ctx, userID, domain.CategoryTypeExpense, name); !errors.Is(err, domain.ErrNotFound) {
+					return err
+				}
+				category := &domain.Category{UserID: userID, Name: name, Type: domain.CategoryTypeExpense}
+				if err := repos.Categories.FindOrCreate(ctx, category); err != nil {
+					return err
+				}
+				ids <- category.ID
+				return nil
+			})
+		}(name)
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+	close(ids)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	seen := make(map[int]bool)
+	for id := range ids {
+		seen[id] = true
+	}
+	if len(seen) != 1 {
+		t.Errorf("got categories %v, want a single one", seen)
+	}
+
+	categories, err := NewCategoryRepository(db).FindByUserID(ctx, userID)
+	if err != nil {
+		t.Fatalf("find categories: %v", err)
+	}
+	if len(categories) != 1 {
+		t.Errorf("got %d categories, want 1", len(categories))
+	}
+}
+
+func TestCategoryCreateDuplicateName(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewCategoryRepository(db)
+	userID := testWallet(t, db, domain.NewMoney(0, "IDR")).UserID
+
+	if err := repo.Create(ctx, &domain.Category{UserID: userID, Name: "Gaji", Type: domain.CategoryTypeIncome}); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	// The same name is free for the other type
+	if err := repo.Create(ctx, &domain.Category{UserID: userID, Name: "Gaji", Type: domain.CategoryTypeExpense}); err != nil {
+		t.Fatalf("create expense category: %v", err)
+	}
+
+	err := repo.Create(ctx, &domain.Category{UserID: userID, Name: "GAJI", Type: domain.CategoryTypeIncome})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("got error %v, want a conflict", err)
+	}
+}
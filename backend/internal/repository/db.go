@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DBTX is the subset of pgx used by the repositories.
+// It is satisfied by both *pgxpool.Pool and pgx.Tx, so the same repository
+// code can run either directly on the pool or inside a unit of work.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// WithQueryTimeout bounds every statement run through db by timeout, on top
+// of any deadline the caller's context already has. A zero timeout leaves
+// db unchanged.
+func WithQueryTimeout(db DBTX, timeout time.Duration) DBTX {
+	if timeout <= 0 {
+		return db
+	}
+	return &timeoutDB{db: db, timeout: timeout}
+}
+
+type timeoutDB struct {
+	db      DBTX
+	timeout time.Duration
+}
+
+func (t *timeoutDB) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	return t.db.Exec(ctx, sql, arguments...)
+}
+
+func (t *timeoutDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	rows, err := t.db.Query(ctx, sql, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &timeoutRows{Rows: rows, cancel: cancel}, nil
+}
+
+func (t *timeoutDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	return &timeoutRow{row: t.db.QueryRow(ctx, sql, args...), cancel: cancel}
+}
+
+// withoutQueryTimeout returns db without the per-statement timeout, for a
+// query whose rows are read while the caller writes them to a slow client.
+// Such a query is bounded by its context alone.
+func withoutQueryTimeout(db DBTX) DBTX {
+	if t, ok := db.(*timeoutDB); ok {
+		return t.db
+	}
+	return db
+}
+
+// timeoutRows keeps the deadline alive until the rows have been read
+type timeoutRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.cancel()
+	return false
+}
+
+func (r *timeoutRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+// timeoutRow keeps the deadline alive until the row has been scanned
+type timeoutRow struct {
+	row    pgx.Row
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRow) Scan(dest ...any) error {
+	defer r.cancel()
+	return r.row.Scan(dest...)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go-moneyku/internal/database"
+	"go-moneyku/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testDB connects to the database in TEST_DATABASE_URL and applies the
+// migrations. Tests that need a database are skipped without it.
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.Connect(dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return db
+}
+
+// testWallet creates a wallet owned by a new user, both removed after the test
+func testWallet(t *testing.T, db *pgxpool.Pool, balance domain.Money) *domain.Wallet {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &domain.User{
+		Username:     fmt.Sprintf("test-%s-%d", t.Name(), time.Now().UnixNano()),
+		Password:     "x",
+		BaseCurrency: "IDR",
+	}
+	if err := NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() {
+		// Wallets and everything else of the user go with it
+		db.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, user.ID)
+	})
+
+	wallet := &domain.Wallet{
+		UserID:       user.ID,
+		Name:         "Test",
+		Balance:      balance,
+		Currency:     "IDR",
+		AccountClass: domain.AccountClassAsset,
+	}
+	if err := NewWalletRepository(db).Create(ctx, wallet); err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+
+	return wallet
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-moneyku/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// slowDB serves one row at once and the next only after the query's context
+// ends, as a server stuck mid-result would
+type slowDB struct {
+	DBTX
+}
+
+func (slowDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return &slowRows{ctx: ctx}, nil
+}
+
+type slowRows struct {
+	pgx.Rows
+	ctx  context.Context
+	read int
+	err  error
+}
+
+func (r *slowRows) Next() bool {
+	r.read++
+	if r.read == 1 {
+		return true
+	}
+	<-r.ctx.Done()
+	r.err = r.ctx.Err()
+	return false
+}
+
+func (r *slowRows) Scan(dest ...any) error { return nil }
+func (r *slowRows) Err() error             { return r.err }
+func (r *slowRows) Close()                 {}
+
+func (r *slowRows) CommandTag() pgconn.CommandTag { return pgconn.CommandTag{} }
+
+func TestQueryTimeoutMidReadIsAnError(t *testing.T) {
+	db := WithQueryTimeout(slowDB{}, time.Millisecond)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		read func() error
+	}{
+		{"wallets", func() error {
+			_, err := NewWalletRepository(db).FindByUserID(ctx, 1)
+			return err
+		}},
+		{"categories", func() error {
+			_, err := NewCategoryRepository(db).FindByUserID(ctx, 1)
+			return err
+		}},
+		{"transactions", func() error {
+			_, err := NewTransactionRepository(db).Search(ctx, domain.TransactionFilter{UserID: 1, Limit: 50})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A result cut off by the timeout must not pass for a short one
+			if err := tt.read(); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+			}
+		})
+	}
+}
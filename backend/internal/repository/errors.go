@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"go-moneyku/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// notFound reports a missing row as domain.NotFound and wraps any other
+// error, so a failing query is not mistaken for a missing resource
+func notFound(resource string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.NotFound(resource)
+	}
+	return fmt.Errorf("failed to fetch %s: %w", resource, err)
+}
+
+// isUniqueViolation reports whether err was raised by the named unique index,
+// typically because a concurrent transaction inserted the same key first
+func isUniqueViolation(err error, index string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == index
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type exchangeRateRepository struct {
+	db DBTX
+}
+
+func NewExchangeRateRepository(db DBTX) domain.ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+const exchangeRateColumns = `id, user_id, base_currency, quote_currency, date, rate, source, created_at`
+
+func (r *exchangeRateRepository) Upsert(ctx context.Context, rate *domain.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (user_id, base_currency, quote_currency, date, rate, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (COALESCE(user_id, 0), base_currency, quote_currency, date)
+		DO UPDATE SET rate = EXCLUDED.rate, source = EXCLUDED.source, created_at = EXCLUDED.created_at
+		RETURNING id
+	`
+
+	rate.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		rate.UserID,
+		rate.Base,
+		rate.Quote,
+		rate.Date,
+		rate.Rate,
+		rate.Source,
+		rate.CreatedAt,
+	).Scan(&rate.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+
+	return nil
+}
+
+func (r *exchangeRateRepository) FindRate(ctx context.Context, userID int, base, quote string, date time.Time) (*domain.ExchangeRate, error) {
+	query := `
+		SELECT ` + exchangeRateColumns + `
+		FROM exchange_rates
+		WHERE (user_id = $1 OR user_id IS NULL)
+			AND base_currency = $2 AND quote_currency = $3 AND date <= $4
+		ORDER BY date DESC, user_id IS NULL
+		LIMIT 1
+	`
+
+	rate := &domain.ExchangeRate{}
+	err := scanExchangeRate(r.db.QueryRow(ctx, query, userID, base, quote, date), rate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find exchange rate: %w", err)
+	}
+
+	return rate, nil
+}
+
+func (r *exchangeRateRepository) FindByUserID(ctx context.Context, userID int, base, quote string) ([]domain.ExchangeRate, error) {
+	query := `
+		SELECT ` + exchangeRateColumns + `
+		FROM exchange_rates
+		WHERE (user_id = $1 OR user_id IS NULL)
+			AND ($2 = '' OR base_currency = $2)
+			AND ($3 = '' OR quote_currency = $3)
+		ORDER BY date DESC, base_currency, quote_currency, user_id IS NULL
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, base, quote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []domain.ExchangeRate
+	for rows.Next() {
+		var rate domain.ExchangeRate
+		if err := scanExchangeRate(rows, &rate); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+
+	return rates, nil
+}
+
+func (r *exchangeRateRepository) FindByID(ctx context.Context, id int) (*domain.ExchangeRate, error) {
+	query := `
+		SELECT ` + exchangeRateColumns + `
+		FROM exchange_rates
+		WHERE id = $1
+	`
+
+	rate := &domain.ExchangeRate{}
+	if err := scanExchangeRate(r.db.QueryRow(ctx, query, id), rate); err != nil {
+		return nil, notFound("exchange rate", err)
+	}
+
+	return rate, nil
+}
+
+func (r *exchangeRateRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM exchange_rates WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete exchange rate: %w", err)
+	}
+
+	return nil
+}
+
+func scanExchangeRate(row interface {
+	Scan(dest ...interface{}) error
+}, rate *domain.ExchangeRate) error {
+	return row.Scan(
+		&rate.ID,
+		&rate.UserID,
+		&rate.Base,
+		&rate.Quote,
+		&rate.Date,
+		&rate.Rate,
+		&rate.Source,
+		&rate.CreatedAt,
+	)
+}
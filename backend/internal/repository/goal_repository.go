@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+type goalRepository struct {
+	db DBTX
+}
+
+func NewGoalRepository(db DBTX) domain.GoalRepository {
+	return &goalRepository{db: db}
+}
+
+const goalColumns = `
+	g.id, g.user_id, g.name, g.target_amount, g.deadline,
+	ARRAY(SELECT gw.wallet_id FROM goal_wallets gw WHERE gw.goal_id = g.id ORDER BY gw.wallet_id),
+	g.created_at, g.updated_at
+`
+
+func (r *goalRepository) Create(ctx context.Context, goal *domain.Goal) error {
+	query := `
+		INSERT INTO goals (user_id, name, target_amount, deadline, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	now := time.Now()
+	goal.CreatedAt = now
+	goal.UpdatedAt = now
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		goal.UserID,
+		goal.Name,
+		goal.TargetAmount,
+		goal.Deadline,
+		goal.CreatedAt,
+		goal.UpdatedAt,
+	).Scan(&goal.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create goal: %w", err)
+	}
+
+	return nil
+}
+
+func (r *goalRepository) FindByUserID(ctx context.Context, userID int) ([]domain.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM goals g
+		WHERE g.user_id = $1
+		ORDER BY g.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []domain.Goal
+	for rows.Next() {
+		var goal domain.Goal
+		if err := scanGoal(rows, &goal); err != nil {
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		goals = append(goals, goal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch goals: %w", err)
+	}
+
+	return goals, nil
+}
+
+func (r *goalRepository) FindByID(ctx context.Context, id int) (*domain.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM goals g
+		WHERE g.id = $1
+	`
+
+	goal := &domain.Goal{}
+	if err := scanGoal(r.db.QueryRow(ctx, query, id), goal); err != nil {
+		return nil, notFound("goal", err)
+	}
+
+	return goal, nil
+}
+
+func (r *goalRepository) FindByWalletID(ctx context.Context, walletID int) (*domain.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM goals g
+		JOIN goal_wallets link ON link.goal_id = g.id
+		WHERE link.wallet_id = $1
+	`
+
+	goal := &domain.Goal{}
+	if err := scanGoal(r.db.QueryRow(ctx, query, walletID), goal); err != nil {
+		return nil, notFound("goal", err)
+	}
+
+	return goal, nil
+}
+
+func (r *goalRepository) Update(ctx context.Context, goal *domain.Goal) error {
+	query := `
+		UPDATE goals
+		SET name = $1, target_amount = $2, deadline = $3, updated_at = $4
+		WHERE id = $5
+	`
+
+	goal.UpdatedAt = time.Now()
+
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		goal.Name,
+		goal.TargetAmount,
+		goal.Deadline,
+		goal.UpdatedAt,
+		goal.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update goal: %w", err)
+	}
+
+	return nil
+}
+
+func (r *goalRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM goals WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete goal: %w", err)
+	}
+
+	return nil
+}
+
+func (r *goalRepository) SetWallets(ctx context.Context, goalID int, walletIDs []int) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM goal_wallets WHERE goal_id = $1`, goalID)
+	if err != nil {
+		return fmt.Errorf("failed to unlink goal wallets: %w", err)
+	}
+
+	query := `
+		INSERT INTO goal_wallets (goal_id, wallet_id)
+		SELECT $1, UNNEST($2::int[])
+	`
+
+	if _, err := r.db.Exec(ctx, query, goalID, walletIDs); err != nil {
+		return fmt.Errorf("failed to link goal wallets: %w", err)
+	}
+
+	return nil
+}
+
+func (r *goalRepository) AddContribution(ctx context.Context, contribution *domain.GoalContribution) error {
+	query := `
+		INSERT INTO goal_contributions (goal_id, amount, date, note, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	contribution.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		contribution.GoalID,
+		contribution.Amount,
+		contribution.Date,
+		contribution.Note,
+		contribution.CreatedAt,
+	).Scan(&contribution.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create goal contribution: %w", err)
+	}
+
+	return nil
+}
+
+func (r *goalRepository) FindContributions(ctx context.Context, goalID int) ([]domain.GoalContribution, error) {
+	query := `
+		SELECT id, goal_id, amount, date, note, created_at
+		FROM goal_contributions
+		WHERE goal_id = $1
+		ORDER BY date DESC, id DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, goalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch goal contributions: %w", err)
+	}
+	defer rows.Close()
+
+	var contributions []domain.GoalContribution
+	for rows.Next() {
+		var contribution domain.GoalContribution
+		if err := scanContribution(rows, &contribution); err != nil {
+			return nil, fmt.Errorf("failed to scan goal contribution: %w", err)
+		}
+		contributions = append(contributions, contribution)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch goal contributions: %w", err)
+	}
+
+	return contributions, nil
+}
+
+func (r *goalRepository) FindContributionByID(ctx context.Context, id int) (*domain.GoalContribution, error) {
+	query := `
+		SELECT id, goal_id, amount, date, note, created_at
+		FROM goal_contributions
+		WHERE id = $1
+	`
+
+	contribution := &domain.GoalContribution{}
+	if err := scanContribution(r.db.QueryRow(ctx, query, id), contribution); err != nil {
+		return nil, notFound("goal contribution", err)
+	}
+
+	return contribution, nil
+}
+
+func (r *goalRepository) DeleteContribution(ctx context.Context, id int) error {
+	query := `DELETE FROM goal_contributions WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete goal contribution: %w", err)
+	}
+
+	return nil
+}
+
+func scanGoal(row interface {
+	Scan(dest ...interface{}) error
+}, goal *domain.Goal) error {
+	return row.Scan(
+		&goal.ID,
+		&goal.UserID,
+		&goal.Name,
+		&goal.TargetAmount,
+		&goal.Deadline,
+		&goal.WalletIDs,
+		&goal.CreatedAt,
+		&goal.UpdatedAt,
+	)
+}
+
+func scanContribution(row interface {
+	Scan(dest ...interface{}) error
+}, contribution *domain.GoalContribution) error {
+	return row.Scan(
+		&contribution.ID,
+		&contribution.GoalID,
+		&contribution.Amount,
+		&contribution.Date,
+		&contribution.Note,
+		&contribution.CreatedAt,
+	)
+}
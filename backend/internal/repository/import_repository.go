@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+type importRepository struct {
+	db DBTX
+}
+
+func NewImportRepository(db DBTX) domain.ImportRepository {
+	return &importRepository{db: db}
+}
+
+func (r *importRepository) ClaimExternalID(ctx context.Context, walletID int, externalID string, transactionID int) (bool, error) {
+	query := `
+		INSERT INTO imported_entries (wallet_id, external_id, transaction_id, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (wallet_id, external_id) DO NOTHING
+	`
+
+	tag, err := r.db.Exec(ctx, query, walletID, externalID, transactionID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to record imported entry: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *importRepository) FindImportedExternalIDs(ctx context.Context, walletID int, externalIDs []string) ([]string, error) {
+	query := `
+		SELECT external_id
+		FROM imported_entries
+		WHERE wallet_id = $1 AND external_id = ANY($2)
+	`
+
+	rows, err := r.db.Query(ctx, query, walletID, externalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch imported entries: %w", err)
+	}
+	defer rows.Close()
+
+	var imported []string
+	for rows.Next() {
+		var externalID string
+		if err := rows.Scan(&externalID); err != nil {
+			return nil, fmt.Errorf("failed to scan imported entry: %w", err)
+		}
+		imported = append(imported, externalID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch imported entries: %w", err)
+	}
+
+	return imported, nil
+}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+type installmentRepository struct {
+	db DBTX
+}
+
+func NewInstallmentRepository(db DBTX) domain.InstallmentRepository {
+	return &installmentRepository{db: db}
+}
+
+const installmentPlanColumns = `
+	p.id, p.user_id, p.wallet_id, p.category, p.description, p.principal, p.months,
+	p.interest_rate, p.admin_fee, p.first_billing_date, p.status, COALESCE(p.suspended_reason, ''),
+	p.created_at, p.updated_at,
+	w.currency
+`
+
+func (r *installmentRepository) Create(ctx context.Context, plan *domain.InstallmentPlan) error {
+	query := `
+		INSERT INTO installment_plans (
+			user_id, wallet_id, category, description, principal, months,
+			interest_rate, admin_fee, first_billing_date, status, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`
+
+	now := time.Now()
+	plan.CreatedAt = now
+	plan.UpdatedAt = now
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		plan.UserID,
+		plan.WalletID,
+		plan.Category,
+		plan.Description,
+		plan.Principal,
+		plan.Months,
+		plan.InterestRate,
+		plan.AdminFee,
+		plan.FirstBillingDate,
+		plan.Status,
+		plan.CreatedAt,
+		plan.UpdatedAt,
+	).Scan(&plan.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create installment plan: %w", err)
+	}
+
+	return nil
+}
+
+func (r *installmentRepository) FindByUserID(ctx context.Context, userID int) ([]domain.InstallmentPlan, error) {
+	query := `
+		SELECT ` + installmentPlanColumns + `
+		FROM installment_plans p
+		JOIN wallets w ON w.id = p.wallet_id
+		WHERE p.user_id = $1
+		ORDER BY p.first_billing_date DESC, p.id DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch installment plans: %w", err)
+	}
+	defer rows.Close()
+
+	return scanInstallmentPlans(rows)
+}
+
+func (r *installmentRepository) FindByID(ctx context.Context, id int) (*domain.InstallmentPlan, error) {
+	query := `
+		SELECT ` + installmentPlanColumns + `
+		FROM installment_plans p
+		JOIN wallets w ON w.id = p.wallet_id
+		WHERE p.id = $1
+	`
+
+	plan := &domain.InstallmentPlan{}
+	if err := scanInstallmentPlan(r.db.QueryRow(ctx, query, id), plan); err != nil {
+		return nil, notFound("installment plan", err)
+	}
+
+	return plan, nil
+}
+
+func (r *installmentRepository) FindByIDForUpdate(ctx context.Context, id int) (*domain.InstallmentPlan, error) {
+	query := `
+		SELECT ` + installmentPlanColumns + `
+		FROM installment_plans p
+		JOIN wallets w ON w.id = p.wallet_id
+		WHERE p.id = $1
+		FOR UPDATE OF p
+	`
+
+	plan := &domain.InstallmentPlan{}
+	if err := scanInstallmentPlan(r.db.QueryRow(ctx, query, id), plan); err != nil {
+		return nil, notFound("installment plan", err)
+	}
+
+	return plan, nil
+}
+
+func (r *installmentRepository) FindActive(ctx context.Context) ([]domain.InstallmentPlan, error) {
+	query := `
+		SELECT ` + installmentPlanColumns + `
+		FROM installment_plans p
+		JOIN wallets w ON w.id = p.wallet_id
+		WHERE p.status = 'active'
+		ORDER BY p.id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch installment plans: %w", err)
+	}
+	defer rows.Close()
+
+	return scanInstallmentPlans(rows)
+}
+
+func (r *installmentRepository) UpdateStatus(ctx context.Context, id int, status domain.InstallmentStatus) error {
+	query := `UPDATE installment_plans SET status = $1, suspended_reason = NULL, updated_at = $2 WHERE id = $3`
+
+	_, err := r.db.Exec(ctx, query, status, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update installment plan: %w", err)
+	}
+
+	return nil
+}
+
+func (r *installmentRepository) Suspend(ctx context.Context, id int, reason string) error {
+	// Only an active plan is suspended; one paid off meanwhile stays paid off
+	query := `
+		UPDATE installment_plans
+		SET status = $1, suspended_reason = $2, updated_at = $3
+		WHERE id = $4 AND status = $5
+	`
+
+	_, err := r.db.Exec(ctx, query, domain.InstallmentSuspended, reason, time.Now(), id, domain.InstallmentActive)
+	if err != nil {
+		return fmt.Errorf("failed to suspend installment plan: %w", err)
+	}
+
+	return nil
+}
+
+func (r *installmentRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM installment_plans WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete installment plan: %w", err)
+	}
+
+	return nil
+}
+
+func (r *installmentRepository) FindCharges(ctx context.Context, planID int) ([]domain.InstallmentCharge, error) {
+	query := `
+		SELECT c.plan_id, c.number, c.due_date, c.principal, c.interest, c.fee, c.payoff, c.transaction_id, w.currency
+		FROM installment_charges c
+		JOIN installment_plans p ON p.id = c.plan_id
+		JOIN wallets w ON w.id = p.wallet_id
+		WHERE c.plan_id = $1
+		ORDER BY c.number
+	`
+
+	rows, err := r.db.Query(ctx, query, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch installment charges: %w", err)
+	}
+	defer rows.Close()
+
+	var charges []domain.InstallmentCharge
+	for rows.Next() {
+		var charge domain.InstallmentCharge
+		var currency string
+		err := rows.Scan(
+			&charge.PlanID,
+			&charge.Number,
+			&charge.DueDate,
+			&charge.Principal,
+			&charge.Interest,
+			&charge.Fee,
+			&charge.Payoff,
+			&charge.TransactionID,
+			&currency,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan installment charge: %w", err)
+		}
+		charge.Principal = charge.Principal.WithCurrency(currency)
+		charge.Interest = charge.Interest.WithCurrency(currency)
+		charge.Fee = charge.Fee.WithCurrency(currency)
+		charges = append(charges, charge)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch installment charges: %w", err)
+	}
+
+	return charges, nil
+}
+
+func (r *installmentRepository) ClaimCharge(ctx context.Context, charge *domain.InstallmentCharge) (bool, error) {
+	// The unique (plan_id, number) constraint makes the claim idempotent
+	// across restarts and across several running instances
+	query := `
+		INSERT INTO installment_charges (plan_id, number, due_date, principal, interest, fee, payoff, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (plan_id, number) DO NOTHING
+	`
+
+	tag, err := r.db.Exec(
+		ctx,
+		query,
+		charge.PlanID,
+		charge.Number,
+		charge.DueDate,
+		charge.Principal,
+		charge.Interest,
+		charge.Fee,
+		charge.Payoff,
+		time.Now(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim installment charge: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *installmentRepository) SetChargeTransaction(ctx context.Context, planID int, number int, transactionID int) error {
+	query := `
+		UPDATE installment_charges
+		SET transaction_id = $1
+		WHERE plan_id = $2 AND number = $3
+	`
+
+	_, err := r.db.Exec(ctx, query, transactionID, planID, number)
+	if err != nil {
+		return fmt.Errorf("failed to update installment charge: %w", err)
+	}
+
+	return nil
+}
+
+func scanInstallmentPlans(rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}) ([]domain.InstallmentPlan, error) {
+	var plans []domain.InstallmentPlan
+	for rows.Next() {
+		var plan domain.InstallmentPlan
+		if err := scanInstallmentPlan(rows, &plan); err != nil {
+			return nil, fmt.Errorf("failed to scan installment plan: %w", err)
+		}
+		plans = append(plans, plan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read installment plans: %w", err)
+	}
+
+	return plans, nil
+}
+
+func scanInstallmentPlan(row interface {
+	Scan(dest ...interface{}) error
+}, plan *domain.InstallmentPlan) error {
+	var currency string
+	err := row.Scan(
+		&plan.ID,
+		&plan.UserID,
+		&plan.WalletID,
+		&plan.Category,
+		&plan.Description,
+		&plan.Principal,
+		&plan.Months,
+		&plan.InterestRate,
+		&plan.AdminFee,
+		&plan.FirstBillingDate,
+		&plan.Status,
+		&plan.SuspendedReason,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+		&currency,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Amounts are in the currency of the wallet they are charged to
+	plan.Principal = plan.Principal.WithCurrency(currency)
+	plan.AdminFee = plan.AdminFee.WithCurrency(currency)
+	return nil
+}
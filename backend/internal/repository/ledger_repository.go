@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+type ledgerRepository struct {
+	db DBTX
+}
+
+func NewLedgerRepository(db DBTX) domain.LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+func (r *ledgerRepository) CreateEntry(ctx context.Context, entry *domain.JournalEntry) error {
+	entryQuery := `
+		INSERT INTO journal_entries (user_id, transaction_id, kind, memo, date, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	entry.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(
+		ctx,
+		entryQuery,
+		entry.UserID,
+		entry.TransactionID,
+		entry.Kind,
+		entry.Memo,
+		entry.Date,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create journal entry: %w", err)
+	}
+
+	postingQuery := `
+		INSERT INTO ledger_postings (entry_id, wallet_id, account, amount)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	for i := range entry.Postings {
+		posting := &entry.Postings[i]
+		posting.EntryID = entry.ID
+
+		err := r.db.QueryRow(
+			ctx,
+			postingQuery,
+			posting.EntryID,
+			posting.WalletID,
+			posting.Account,
+			posting.Amount,
+		).Scan(&posting.ID)
+
+		if err != nil {
+			return fmt.Errorf("failed to create ledger posting: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *ledgerRepository) FindByTransactionID(ctx context.Context, transactionID int) (*domain.JournalEntry, error) {
+	query := `
+		SELECT id, user_id, transaction_id, kind, memo, date, created_at
+		FROM journal_entries
+		WHERE transaction_id = $1
+	`
+
+	entry := &domain.JournalEntry{}
+	err := r.db.QueryRow(ctx, query, transactionID).Scan(
+		&entry.ID,
+		&entry.UserID,
+		&entry.TransactionID,
+		&entry.Kind,
+		&entry.Memo,
+		&entry.Date,
+		&entry.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, notFound("journal entry", err)
+	}
+
+	postingQuery := `
+		SELECT id, entry_id, wallet_id, account, amount
+		FROM ledger_postings
+		WHERE entry_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, postingQuery, entry.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ledger postings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var posting domain.Posting
+		err := rows.Scan(
+			&posting.ID,
+			&posting.EntryID,
+			&posting.WalletID,
+			&posting.Account,
+			&posting.Amount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ledger posting: %w", err)
+		}
+		entry.Postings = append(entry.Postings, posting)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch ledger postings: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (r *ledgerRepository) DeleteByTransactionID(ctx context.Context, transactionID int) error {
+	query := `DELETE FROM journal_entries WHERE transaction_id = $1`
+
+	_, err := r.db.Exec(ctx, query, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete journal entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ledgerRepository) DeleteByWalletID(ctx context.Context, walletID int) error {
+	query := `
+		DELETE FROM journal_entries
+		WHERE id IN (SELECT entry_id FROM ledger_postings WHERE wallet_id = $1)
+	`
+
+	_, err := r.db.Exec(ctx, query, walletID)
+	if err != nil {
+		return fmt.Errorf("failed to delete journal entries: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ledgerRepository) GetWalletBalances(ctx context.Context, userID int) ([]domain.WalletLedgerBalance, error) {
+	query := `
+		SELECT w.id, w.name, w.balance, COALESCE(SUM(p.amount), 0)
+		FROM wallets w
+		LEFT JOIN ledger_postings p ON p.wallet_id = w.id
+		WHERE w.user_id = $1
+		GROUP BY w.id, w.name, w.balance
+		ORDER BY w.id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ledger balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []domain.WalletLedgerBalance
+	for rows.Next() {
+		var balance domain.WalletLedgerBalance
+		err := rows.Scan(
+			&balance.WalletID,
+			&balance.WalletName,
+			&balance.CachedBalance,
+			&balance.LedgerBalance,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ledger balance: %w", err)
+		}
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch ledger balances: %w", err)
+	}
+
+	return balances, nil
+}
+
+func (r *ledgerRepository) SumWalletFlows(ctx context.Context, walletIDs []int, start, end time.Time) (domain.Money, error) {
+	query := `
+		SELECT COALESCE(SUM(p.amount), 0)
+		FROM ledger_postings p
+		JOIN journal_entries j ON j.id = p.entry_id
+		WHERE p.wallet_id = ANY($1) AND j.date >= $2 AND j.date < $3
+			AND NOT EXISTS (
+				SELECT 1 FROM ledger_postings o
+				WHERE o.entry_id = p.entry_id AND o.account = $4
+			)
+	`
+
+	var total domain.Money
+	err := r.db.QueryRow(ctx, query, walletIDs, start, end, domain.AccountOpeningBalance).Scan(&total)
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("failed to sum wallet flows: %w", err)
+	}
+
+	return total, nil
+}
+
+func (r *ledgerRepository) WalletBalanceAt(ctx context.Context, walletID int, end time.Time) (domain.Money, error) {
+	query := `
+		SELECT COALESCE(SUM(p.amount), 0)
+		FROM ledger_postings p
+		JOIN journal_entries j ON j.id = p.entry_id
+		WHERE p.wallet_id = $1 AND j.date < $2
+	`
+
+	var balance domain.Money
+	err := r.db.QueryRow(ctx, query, walletID, end).Scan(&balance)
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("failed to compute wallet balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+func (r *ledgerRepository) FindWalletPostings(ctx context.Context, walletID int, start, end time.Time) ([]domain.WalletPosting, error) {
+	query := `
+		SELECT j.id, j.transaction_id, j.kind, j.memo, COALESCE(t.category, ''), COALESCE(ow.name, ''), j.date, p.amount
+		FROM ledger_postings p
+		JOIN journal_entries j ON j.id = p.entry_id
+		LEFT JOIN transactions t ON t.id = j.transaction_id
+		LEFT JOIN ledger_postings op ON op.entry_id = p.entry_id AND op.wallet_id IS NOT NULL AND op.id <> p.id
+		LEFT JOIN wallets ow ON ow.id = op.wallet_id
+		WHERE p.wallet_id = $1 AND j.date >= $2 AND j.date < $3
+		ORDER BY j.date, j.id
+	`
+
+	rows, err := r.db.Query(ctx, query, walletID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wallet postings: %w", err)
+	}
+	defer rows.Close()
+
+	var postings []domain.WalletPosting
+	for rows.Next() {
+		var posting domain.WalletPosting
+		err := rows.Scan(
+			&posting.EntryID,
+			&posting.TransactionID,
+			&posting.Kind,
+			&posting.Memo,
+			&posting.Category,
+			&posting.OtherWallet,
+			&posting.Date,
+			&posting.Amount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan wallet posting: %w", err)
+		}
+		postings = append(postings, posting)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch wallet postings: %w", err)
+	}
+
+	return postings, nil
+}
+
+func (r *ledgerRepository) HasPostings(ctx context.Context, walletID int) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM ledger_postings WHERE wallet_id = $1)`
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, walletID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check wallet postings: %w", err)
+	}
+
+	return exists, nil
+}
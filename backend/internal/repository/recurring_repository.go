@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+type recurringRuleRepository struct {
+	db DBTX
+}
+
+func NewRecurringRuleRepository(db DBTX) domain.RecurringRuleRepository {
+	return &recurringRuleRepository{db: db}
+}
+
+const recurringRuleColumns = `
+	r.id, r.user_id, r.wallet_id, r.to_wallet_id, r.type, r.amount, r.category, r.description,
+	r.frequency, r.day_of_week, r.day_of_month, r.cron_expression, r.start_date, r.end_date,
+	r.max_occurrences, r.active,
+	(SELECT COUNT(*) FROM recurring_occurrences o WHERE o.rule_id = r.id AND o.error IS NULL),
+	(SELECT COUNT(*) FROM recurring_occurrences o WHERE o.rule_id = r.id AND o.error IS NOT NULL),
+	(SELECT MAX(o.occurrence_date) FROM recurring_occurrences o WHERE o.rule_id = r.id),
+	r.created_at, r.updated_at
+`
+
+func (r *recurringRuleRepository) Create(ctx context.Context, rule *domain.RecurringRule) error {
+	query := `
+		INSERT INTO recurring_rules (
+			user_id, wallet_id, to_wallet_id, type, amount, category, description,
+			frequency, day_of_week, day_of_month, cron_expression, start_date, end_date,
+			max_occurrences, active, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING id
+	`
+
+	now := time.Now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		rule.UserID,
+		rule.WalletID,
+		rule.ToWalletID,
+		rule.Type,
+		rule.Amount,
+		rule.Category,
+		rule.Description,
+		rule.Frequency,
+		rule.DayOfWeek,
+		rule.DayOfMonth,
+		rule.CronExpression,
+		rule.StartDate,
+		rule.EndDate,
+		rule.MaxOccurrences,
+		rule.Active,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	).Scan(&rule.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create recurring rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *recurringRuleRepository) FindByUserID(ctx context.Context, userID int) ([]domain.RecurringRule, error) {
+	query := `
+		SELECT ` + recurringRuleColumns + `
+		FROM recurring_rules r
+		WHERE r.user_id = $1
+		ORDER BY r.created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recurring rules: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanRules(rows)
+}
+
+func (r *recurringRuleRepository) FindByID(ctx context.Context, id int) (*domain.RecurringRule, error) {
+	query := `
+		SELECT ` + recurringRuleColumns + `
+		FROM recurring_rules r
+		WHERE r.id = $1
+	`
+
+	rule := &domain.RecurringRule{}
+	if err := scanRule(r.db.QueryRow(ctx, query, id), rule); err != nil {
+		return nil, notFound("recurring rule", err)
+	}
+
+	return rule, nil
+}
+
+func (r *recurringRuleRepository) FindActive(ctx context.Context) ([]domain.RecurringRule, error) {
+	query := `
+		SELECT ` + recurringRuleColumns + `
+		FROM recurring_rules r
+		WHERE r.active = TRUE
+		ORDER BY r.id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recurring rules: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanRules(rows)
+}
+
+func (r *recurringRuleRepository) Update(ctx context.Context, rule *domain.RecurringRule) error {
+	query := `
+		UPDATE recurring_rules
+		SET wallet_id = $1, to_wallet_id = $2, type = $3, amount = $4, category = $5, description = $6,
+			frequency = $7, day_of_week = $8, day_of_month = $9, cron_expression = $10, start_date = $11,
+			end_date = $12, max_occurrences = $13, active = $14, updated_at = $15
+		WHERE id = $16
+	`
+
+	rule.UpdatedAt = time.Now()
+
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		rule.WalletID,
+		rule.ToWalletID,
+		rule.Type,
+		rule.Amount,
+		rule.Category,
+		rule.Description,
+		rule.Frequency,
+		rule.DayOfWeek,
+		rule.DayOfMonth,
+		rule.CronExpression,
+		rule.StartDate,
+		rule.EndDate,
+		rule.MaxOccurrences,
+		rule.Active,
+		rule.UpdatedAt,
+		rule.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update recurring rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *recurringRuleRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM recurring_rules WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete recurring rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *recurringRuleRepository) ClaimOccurrence(ctx context.Context, ruleID int, date time.Time) (bool, error) {
+	// The unique (rule_id, occurrence_date) constraint makes the claim idempotent
+	// across restarts and across several running instances
+	query := `
+		INSERT INTO recurring_occurrences (rule_id, occurrence_date, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (rule_id, occurrence_date) DO NOTHING
+	`
+
+	tag, err := r.db.Exec(ctx, query, ruleID, date, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim recurring occurrence: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *recurringRuleRepository) SetOccurrenceTransaction(ctx context.Context, ruleID int, date time.Time, transactionID int) error {
+	query := `
+		UPDATE recurring_occurrences
+		SET transaction_id = $1
+		WHERE rule_id = $2 AND occurrence_date = $3
+	`
+
+	_, err := r.db.Exec(ctx, query, transactionID, ruleID, date)
+	if err != nil {
+		return fmt.Errorf("failed to update recurring occurrence: %w", err)
+	}
+
+	return nil
+}
+
+func (r *recurringRuleRepository) SkipOccurrence(ctx context.Context, ruleID int, date time.Time, reason string) error {
+	query := `
+		INSERT INTO recurring_occurrences (rule_id, occurrence_date, error, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (rule_id, occurrence_date) DO NOTHING
+	`
+
+	_, err := r.db.Exec(ctx, query, ruleID, date, reason, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to skip recurring occurrence: %w", err)
+	}
+
+	return nil
+}
+
+func (r *recurringRuleRepository) FindOccurrences(ctx context.Context, ruleID int) ([]domain.RecurringOccurrence, error) {
+	query := `
+		SELECT occurrence_date, transaction_id, COALESCE(error, ''), created_at
+		FROM recurring_occurrences
+		WHERE rule_id = $1
+		ORDER BY occurrence_date DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recurring occurrences: %w", err)
+	}
+	defer rows.Close()
+
+	var occurrences []domain.RecurringOccurrence
+	for rows.Next() {
+		var occurrence domain.RecurringOccurrence
+		if err := rows.Scan(&occurrence.Date, &occurrence.TransactionID, &occurrence.Error, &occurrence.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recurring occurrence: %w", err)
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch recurring occurrences: %w", err)
+	}
+
+	return occurrences, nil
+}
+
+func (r *recurringRuleRepository) scanRules(rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}) ([]domain.RecurringRule, error) {
+	var rules []domain.RecurringRule
+	for rows.Next() {
+		var rule domain.RecurringRule
+		if err := scanRule(rows, &rule); err != nil {
+			return nil, fmt.Errorf("failed to scan recurring rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recurring rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+func scanRule(row interface {
+	Scan(dest ...interface{}) error
+}, rule *domain.RecurringRule) error {
+	return row.Scan(
+		&rule.ID,
+		&rule.UserID,
+		&rule.WalletID,
+		&rule.ToWalletID,
+		&rule.Type,
+		&rule.Amount,
+		&rule.Category,
+		&rule.Description,
+		&rule.Frequency,
+		&rule.DayOfWeek,
+		&rule.DayOfMonth,
+		&rule.CronExpression,
+		&rule.StartDate,
+		&rule.EndDate,
+		&rule.MaxOccurrences,
+		&rule.Active,
+		&rule.OccurrenceCount,
+		&rule.SkippedCount,
+		&rule.LastOccurrence,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+}
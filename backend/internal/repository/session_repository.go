@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+type sessionRepository struct {
+	db DBTX
+}
+
+func NewSessionRepository(db DBTX) domain.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	query := `
+		INSERT INTO sessions (user_id, created_at)
+		VALUES ($1, $2)
+		RETURNING id
+	`
+
+	session.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(ctx, query, session.UserID, session.CreatedAt).Scan(&session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id int) (*domain.Session, error) {
+	query := `
+		SELECT id, user_id, created_at, revoked_at
+		FROM sessions
+		WHERE id = $1
+	`
+
+	session := &domain.Session{}
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.CreatedAt,
+		&session.RevokedAt,
+	)
+
+	if err != nil {
+		return nil, notFound("session", err)
+	}
+
+	return session, nil
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id int) error {
+	query := `UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+
+	_, err := r.db.Exec(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RevokeByUserID(ctx context.Context, userID int) error {
+	query := `UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+
+	_, err := r.db.Exec(ctx, query, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (session_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	token.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		token.SessionID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) FindRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, token_hash, expires_at, used_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+
+	token := &domain.RefreshToken{}
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.SessionID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, notFound("refresh token", err)
+	}
+
+	return token, nil
+}
+
+func (r *sessionRepository) UseRefreshToken(ctx context.Context, id int, usedAt time.Time) (bool, error) {
+	query := `UPDATE refresh_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`
+
+	tag, err := r.db.Exec(ctx, query, usedAt, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to use refresh token: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *sessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM refresh_tokens WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+
+	// A session without refresh tokens cannot be renewed, and its last access
+	// token expired long before its last refresh token did
+	query := `
+		DELETE FROM sessions s
+		WHERE s.created_at < $1
+		AND NOT EXISTS (SELECT 1 FROM refresh_tokens t WHERE t.session_id = s.id)
+	`
+	if _, err := r.db.Exec(ctx, query, before); err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -3,25 +3,24 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"go-moneyku/internal/domain"
-
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type transactionRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewTransactionRepository(db *pgxpool.Pool) domain.TransactionRepository {
+func NewTransactionRepository(db DBTX) domain.TransactionRepository {
 	return &transactionRepository{db: db}
 }
 
-func (r *transactionRepository) Create(transaction *domain.Transaction) error {
+func (r *transactionRepository) Create(ctx context.Context, transaction *domain.Transaction) error {
 	query := `
-		INSERT INTO transactions (user_id, wallet_id, type, amount, category, description, date, to_wallet_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO transactions (user_id, wallet_id, type, amount, category, category_id, description, date, to_wallet_id, to_amount, fee_of_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id
 	`
 
@@ -30,16 +29,19 @@ func (r *transactionRepository) Create(transaction *domain.Transaction) error {
 	transaction.UpdatedAt = now
 
 	err := r.db.QueryRow(
-		context.Background(),
+		ctx,
 		query,
 		transaction.UserID,
 		transaction.WalletID,
 		transaction.Type,
 		transaction.Amount,
 		transaction.Category,
+		transaction.CategoryID,
 		transaction.Description,
 		transaction.Date,
 		transaction.ToWalletID,
+		transaction.ToAmount,
+		transaction.FeeOfID,
 		transaction.CreatedAt,
 		transaction.UpdatedAt,
 	).Scan(&transaction.ID)
@@ -51,15 +53,15 @@ func (r *transactionRepository) Create(transaction *domain.Transaction) error {
 	return nil
 }
 
-func (r *transactionRepository) FindByUserID(userID int) ([]domain.Transaction, error) {
+func (r *transactionRepository) FindByUserID(ctx context.Context, userID int) ([]domain.Transaction, error) {
 	query := `
-		SELECT id, user_id, wallet_id, type, amount, category, description, date, to_wallet_id, created_at, updated_at
+		SELECT id, user_id, wallet_id, type, amount, category, category_id, description, date, to_wallet_id, to_amount, fee_of_id, created_at, updated_at
 		FROM transactions
 		WHERE user_id = $1
 		ORDER BY date DESC, created_at DESC
 	`
 
-	rows, err := r.db.Query(context.Background(), query, userID)
+	rows, err := r.db.Query(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
 	}
@@ -68,15 +70,15 @@ func (r *transactionRepository) FindByUserID(userID int) ([]domain.Transaction,
 	return r.scanTransactions(rows)
 }
 
-func (r *transactionRepository) FindByWalletID(walletID int) ([]domain.Transaction, error) {
+func (r *transactionRepository) FindByWalletID(ctx context.Context, walletID int) ([]domain.Transaction, error) {
 	query := `
-		SELECT id, user_id, wallet_id, type, amount, category, description, date, to_wallet_id, created_at, updated_at
+		SELECT id, user_id, wallet_id, type, amount, category, category_id, description, date, to_wallet_id, to_amount, fee_of_id, created_at, updated_at
 		FROM transactions
 		WHERE wallet_id = $1 OR to_wallet_id = $1
 		ORDER BY date DESC, created_at DESC
 	`
 
-	rows, err := r.db.Query(context.Background(), query, walletID)
+	rows, err := r.db.Query(ctx, query, walletID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
 	}
@@ -85,15 +87,15 @@ func (r *transactionRepository) FindByWalletID(walletID int) ([]domain.Transacti
 	return r.scanTransactions(rows)
 }
 
-func (r *transactionRepository) FindByDateRange(userID int, startDate, endDate time.Time) ([]domain.Transaction, error) {
+func (r *transactionRepository) FindByDateRange(ctx context.Context, userID int, startDate, endDate time.Time) ([]domain.Transaction, error) {
 	query := `
-		SELECT id, user_id, wallet_id, type, amount, category, description, date, to_wallet_id, created_at, updated_at
+		SELECT id, user_id, wallet_id, type, amount, category, category_id, description, date, to_wallet_id, to_amount, fee_of_id, created_at, updated_at
 		FROM transactions
 		WHERE user_id = $1 AND date >= $2 AND date <= $3
 		ORDER BY date DESC, created_at DESC
 	`
 
-	rows, err := r.db.Query(context.Background(), query, userID, startDate, endDate)
+	rows, err := r.db.Query(ctx, query, userID, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
 	}
@@ -102,39 +104,91 @@ func (r *transactionRepository) FindByDateRange(userID int, startDate, endDate t
 	return r.scanTransactions(rows)
 }
 
-func (r *transactionRepository) FindByID(id int) (*domain.Transaction, error) {
+func (r *transactionRepository) FindByID(ctx context.Context, id int) (*domain.Transaction, error) {
 	query := `
-		SELECT id, user_id, wallet_id, type, amount, category, description, date, to_wallet_id, created_at, updated_at
+		SELECT id, user_id, wallet_id, type, amount, category, category_id, description, date, to_wallet_id, to_amount, fee_of_id, created_at, updated_at
 		FROM transactions
 		WHERE id = $1
 	`
 
 	transaction := &domain.Transaction{}
-	err := r.db.QueryRow(context.Background(), query, id).Scan(
+	err := r.db.QueryRow(ctx, query, id).Scan(
 		&transaction.ID,
 		&transaction.UserID,
 		&transaction.WalletID,
 		&transaction.Type,
 		&transaction.Amount,
 		&transaction.Category,
+		&transaction.CategoryID,
 		&transaction.Description,
 		&transaction.Date,
 		&transaction.ToWalletID,
+		&transaction.ToAmount,
+		&transaction.FeeOfID,
 		&transaction.CreatedAt,
 		&transaction.UpdatedAt,
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("transaction not found: %w", err)
+		return nil, notFound("transaction", err)
 	}
 
 	return transaction, nil
 }
 
-func (r *transactionRepository) Delete(id int) error {
+func (r *transactionRepository) FindFees(ctx context.Context, transferID int) ([]domain.Transaction, error) {
+	query := `
+		SELECT id, user_id, wallet_id, type, amount, category, category_id, description, date, to_wallet_id, to_amount, fee_of_id, created_at, updated_at
+		FROM transactions
+		WHERE fee_of_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, query, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transfer fees: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanTransactions(rows)
+}
+
+func (r *transactionRepository) Update(ctx context.Context, transaction *domain.Transaction) error {
+	query := `
+		UPDATE transactions
+		SET wallet_id = $1, type = $2, amount = $3, category = $4, category_id = $5, description = $6, date = $7, to_wallet_id = $8, to_amount = $9, updated_at = $10
+		WHERE id = $11
+	`
+
+	transaction.UpdatedAt = time.Now()
+
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		transaction.WalletID,
+		transaction.Type,
+		transaction.Amount,
+		transaction.Category,
+		transaction.CategoryID,
+		transaction.Description,
+		transaction.Date,
+		transaction.ToWalletID,
+		transaction.ToAmount,
+		transaction.UpdatedAt,
+		transaction.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *transactionRepository) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM transactions WHERE id = $1`
 
-	_, err := r.db.Exec(context.Background(), query, id)
+	_, err := r.db.Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete transaction: %w", err)
 	}
@@ -142,40 +196,243 @@ func (r *transactionRepository) Delete(id int) error {
 	return nil
 }
 
-func (r *transactionRepository) GetStatsByUserID(userID int) (*domain.TransactionStats, error) {
+// likeEscaper escapes the LIKE wildcards in user supplied search text
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *transactionRepository) Search(ctx context.Context, filter domain.TransactionFilter) ([]domain.Transaction, error) {
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := transactionFilterConditions(filter, param)
+
+	// The id breaks ties so that the order, and with it the keyset, is total
+	column, direction, comparison := "date", "DESC", "<"
+	switch filter.Sort {
+	case domain.TransactionSortDateAsc:
+		direction, comparison = "ASC", ">"
+	case domain.TransactionSortAmountDesc:
+		column = "amount"
+	case domain.TransactionSortAmountAsc:
+		column, direction, comparison = "amount", "ASC", ">"
+	}
+
+	if filter.After != nil {
+		var key string
+		if column == "amount" {
+			key = param(filter.After.Amount) + "::numeric"
+		} else {
+			key = param(filter.After.Date) + "::timestamp"
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, %s)", column, comparison, key, param(filter.After.ID)))
+	}
+
 	query := `
-		SELECT 
-			COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE 0 END), 0) as total_income,
-			COALESCE(SUM(CASE WHEN type = 'expense' THEN amount ELSE 0 END), 0) as total_expense,
-			COALESCE(SUM(CASE WHEN type = 'transfer' THEN amount ELSE 0 END), 0) as total_transfer
+		SELECT id, user_id, wallet_id, type, amount, category, category_id, description, date, to_wallet_id, to_amount, fee_of_id, created_at, updated_at
 		FROM transactions
-		WHERE user_id = $1
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + column + ` ` + direction + `, id ` + direction + `
+		LIMIT ` + param(filter.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanTransactions(rows)
+}
+
+// transactionFilterConditions turns the filter fields shared by listing and
+// export into SQL conditions on the transactions table. param binds a value
+// and returns its placeholder.
+func transactionFilterConditions(filter domain.TransactionFilter, param func(value interface{}) string) []string {
+	conditions := []string{"user_id = " + param(filter.UserID)}
+	if len(filter.WalletIDs) > 0 {
+		walletIDs := param(filter.WalletIDs)
+		conditions = append(conditions, fmt.Sprintf("(wallet_id = ANY(%s) OR to_wallet_id = ANY(%s))", walletIDs, walletIDs))
+	}
+	if len(filter.Types) > 0 {
+		types := make([]string, len(filter.Types))
+		for i, transactionType := range filter.Types {
+			types[i] = string(transactionType)
+		}
+		conditions = append(conditions, "type = ANY("+param(types)+")")
+	}
+	if len(filter.CategoryIDs) > 0 {
+		conditions = append(conditions, "category_id = ANY("+param(filter.CategoryIDs)+")")
+	}
+	if filter.Category != "" {
+		conditions = append(conditions, "LOWER(category) = LOWER(TRIM("+param(filter.Category)+"))")
+	}
+	if filter.MinAmount != nil {
+		conditions = append(conditions, "amount >= "+param(*filter.MinAmount)+"::numeric")
+	}
+	if filter.MaxAmount != nil {
+		conditions = append(conditions, "amount <= "+param(*filter.MaxAmount)+"::numeric")
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "description ILIKE '%' || "+param(likeEscaper.Replace(filter.Search))+" || '%'")
+	}
+	if filter.StartDate != nil {
+		conditions = append(conditions, "date >= "+param(*filter.StartDate))
+	}
+	if filter.EndDate != nil {
+		conditions = append(conditions, "date < "+param(*filter.EndDate))
+	}
+
+	return conditions
+}
+
+func (r *transactionRepository) StreamExport(ctx context.Context, filter domain.TransactionFilter, fn func(domain.TransactionExport) error) error {
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// Filtering in a subquery keeps the shared conditions unambiguous next
+	// to the columns of the joined wallets
+	query := `
+		SELECT t.id, t.user_id, t.wallet_id, t.type, t.amount, t.category, t.category_id, t.description, t.date, t.to_wallet_id, t.to_amount, t.fee_of_id, t.created_at, t.updated_at,
+			w.name, tw.name, w.currency, tw.currency
+		FROM (
+			SELECT * FROM transactions
+			WHERE ` + strings.Join(transactionFilterConditions(filter, param), " AND ") + `
+		) t
+		JOIN wallets w ON w.id = t.wallet_id
+		LEFT JOIN wallets tw ON tw.id = t.to_wallet_id
+		ORDER BY t.date, t.id
 	`
 
-	stats := &domain.TransactionStats{}
-	err := r.db.QueryRow(context.Background(), query, userID).Scan(
-		&stats.TotalIncome,
-		&stats.TotalExpense,
-		&stats.TotalTransfer,
-	)
+	// The rows are read while fn writes them to the client, which can take
+	// far longer than any single query should
+	rows, err := withoutQueryTimeout(r.db).Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export transactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var export domain.TransactionExport
+		err := rows.Scan(
+			&export.ID,
+			&export.UserID,
+			&export.WalletID,
+			&export.Type,
+			&export.Amount,
+			&export.Category,
+			&export.CategoryID,
+			&export.Description,
+			&export.Date,
+			&export.ToWalletID,
+			&export.ToAmount,
+			&export.FeeOfID,
+			&export.CreatedAt,
+			&export.UpdatedAt,
+			&export.WalletName,
+			&export.ToWalletName,
+			&export.Currency,
+			&export.ToCurrency,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		export.Amount = export.Amount.WithCurrency(export.Currency)
+		if export.ToAmount != nil && export.ToCurrency != nil {
+			*export.ToAmount = export.ToAmount.WithCurrency(*export.ToCurrency)
+		}
+
+		if err := fn(export); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to export transactions: %w", err)
+	}
+	return nil
+}
 
+func (r *transactionRepository) GetDailyTotalsByUserID(ctx context.Context, userID int) ([]domain.DailyTotal, error) {
+	query := `
+		SELECT DATE(t.date), t.type, w.currency, SUM(t.amount)
+		FROM transactions t
+		JOIN wallets w ON w.id = t.wallet_id
+		WHERE t.user_id = $1
+		GROUP BY DATE(t.date), t.type, w.currency
+		ORDER BY DATE(t.date)
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get transaction stats: %w", err)
+		return nil, fmt.Errorf("failed to get daily totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []domain.DailyTotal
+	for rows.Next() {
+		var total domain.DailyTotal
+		if err := rows.Scan(&total.Date, &total.Type, &total.Currency, &total.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan daily total: %w", err)
+		}
+		total.Amount = total.Amount.WithCurrency(total.Currency)
+		totals = append(totals, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get daily totals: %w", err)
 	}
 
-	return stats, nil
+	return totals, nil
 }
 
-func (r *transactionRepository) GetRecentByUserID(userID int, limit int) ([]domain.Transaction, error) {
+func (r *transactionRepository) SumExpensesByDay(ctx context.Context, userID int, categoryIDs []int, start, end time.Time) ([]domain.DailyTotal, error) {
 	query := `
-		SELECT id, user_id, wallet_id, type, amount, category, description, date, to_wallet_id, created_at, updated_at
+		SELECT DATE(t.date), w.currency, SUM(t.amount)
+		FROM transactions t
+		JOIN wallets w ON w.id = t.wallet_id
+		WHERE t.user_id = $1 AND t.type = 'expense' AND t.date >= $2 AND t.date < $3
+			AND ($4::int[] IS NULL OR t.category_id = ANY($4))
+		GROUP BY DATE(t.date), w.currency
+		ORDER BY DATE(t.date)
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, start, end, categoryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []domain.DailyTotal
+	for rows.Next() {
+		total := domain.DailyTotal{Type: domain.TransactionTypeExpense}
+		if err := rows.Scan(&total.Date, &total.Currency, &total.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan expense total: %w", err)
+		}
+		total.Amount = total.Amount.WithCurrency(total.Currency)
+		totals = append(totals, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to sum expenses: %w", err)
+	}
+
+	return totals, nil
+}
+
+func (r *transactionRepository) GetRecentByUserID(ctx context.Context, userID int, limit int) ([]domain.Transaction, error) {
+	query := `
+		SELECT id, user_id, wallet_id, type, amount, category, category_id, description, date, to_wallet_id, to_amount, fee_of_id, created_at, updated_at
 		FROM transactions
 		WHERE user_id = $1
 		ORDER BY date DESC, created_at DESC
 		LIMIT $2
 	`
 
-	rows, err := r.db.Query(context.Background(), query, userID, limit)
+	rows, err := r.db.Query(ctx, query, userID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch recent transactions: %w", err)
 	}
@@ -187,6 +444,7 @@ func (r *transactionRepository) GetRecentByUserID(userID int, limit int) ([]doma
 func (r *transactionRepository) scanTransactions(rows interface {
 	Next() bool
 	Scan(dest ...interface{}) error
+	Err() error
 }) ([]domain.Transaction, error) {
 	var transactions []domain.Transaction
 	for rows.Next() {
@@ -198,9 +456,12 @@ func (r *transactionRepository) scanTransactions(rows interface {
 			&transaction.Type,
 			&transaction.Amount,
 			&transaction.Category,
+			&transaction.CategoryID,
 			&transaction.Description,
 			&transaction.Date,
 			&transaction.ToWalletID,
+			&transaction.ToAmount,
+			&transaction.FeeOfID,
 			&transaction.CreatedAt,
 			&transaction.UpdatedAt,
 		)
@@ -210,5 +471,9 @@ func (r *transactionRepository) scanTransactions(rows interface {
 		transactions = append(transactions, transaction)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transactions: %w", err)
+	}
+
 	return transactions, nil
 }
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxAttempts is how many times a unit of work is tried when Postgres
+// aborts it with a serialization failure or a deadlock
+const maxAttempts = 3
+
+type unitOfWork struct {
+	db           *pgxpool.Pool
+	queryTimeout time.Duration
+}
+
+// NewUnitOfWork bounds each statement run inside a unit of work by
+// queryTimeout; zero means no limit besides the caller's context
+func NewUnitOfWork(db *pgxpool.Pool, queryTimeout time.Duration) domain.UnitOfWork {
+	return &unitOfWork{db: db, queryTimeout: queryTimeout}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos domain.Repositories) error) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		err = u.run(ctx, fn)
+		if !isRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (u *unitOfWork) run(ctx context.Context, fn func(repos domain.Repositories) error) error {
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer tx.Rollback(ctx)
+
+	if err := fn(newRepositories(WithQueryTimeout(tx, u.queryTimeout))); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// isRetryable reports whether err was caused by a serialization failure or
+// a deadlock, in which case the whole transaction can safely be run again
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	return false
+}
+
+func newRepositories(db DBTX) domain.Repositories {
+	return domain.Repositories{
+		Users:        NewUserRepository(db),
+		Wallets:      NewWalletRepository(db),
+		Transactions: NewTransactionRepository(db),
+		Ledger:       NewLedgerRepository(db),
+		Recurring:    NewRecurringRuleRepository(db),
+		Categories:   NewCategoryRepository(db),
+		Goals:        NewGoalRepository(db),
+		Imports:      NewImportRepository(db),
+		Installments: NewInstallmentRepository(db),
+		Sessions:     NewSessionRepository(db),
+	}
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go-moneyku/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, true},
+		{"wrapped", fmt.Errorf("failed to update wallet balance: %w", &pgconn.PgError{Code: "40001"}), true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"insufficient balance", domain.ErrInsufficientBalance, false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitOfWorkRetriesSerializationFailure(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	uow := NewUnitOfWork(db, 0)
+	wallet := testWallet(t, db, domain.NewMoney(0, "IDR"))
+
+	attempts := 0
+	err := uow.Do(ctx, func(repos domain.Repositories) error {
+		attempts++
+		if err := repos.Wallets.AdjustBalance(ctx, wallet.ID, domain.NewMoney(1_00, "IDR")); err != nil {
+			return err
+		}
+		if attempts == 1 {
+			return fmt.Errorf("commit: %w", &pgconn.PgError{Code: "40001"})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("ran %d times, want 2", attempts)
+	}
+
+	// The failed attempt was rolled back, so the increment is applied once
+	got, err := NewWalletRepository(db).FindByID(ctx, wallet.ID)
+	if err != nil {
+		t.Fatalf("find wallet: %v", err)
+	}
+	if want := domain.NewMoney(1_00, "IDR"); got.Balance.Cmp(want) != 0 {
+		t.Errorf("balance = %s, want %s", got.Balance, want)
+	}
+}
+
+func TestUnitOfWorkRetriesDeadlock(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	uow := NewUnitOfWork(db, 0)
+	a := testWallet(t, db, domain.NewMoney(0, "IDR"))
+	b := testWallet(t, db, domain.NewMoney(0, "IDR"))
+	amount := domain.NewMoney(1_00, "IDR")
+
+	// Two transfers lock the wallets in opposite order. On their first
+	// attempt each waits until the other holds its first lock, so Postgres
+	// aborts one of them with a deadlock and the unit of work runs it again.
+	var attempts atomic.Int32
+	locked := []chan struct{}{make(chan struct{}), make(chan struct{})}
+	transfer := func(i int, first, second int) error {
+		try := 0
+		return uow.Do(ctx, func(repos domain.Repositories) error {
+			attempts.Add(1)
+			try++
+			if err := repos.Wallets.AdjustBalance(ctx, first, amount); err != nil {
+				return err
+			}
+			if try == 1 {
+				close(locked[i])
+				<-locked[1-i]
+			}
+			return repos.Wallets.AdjustBalance(ctx, second, amount)
+		})
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	wg.Add(2)
+	go func() { defer wg.Done(); errs[0] = transfer(0, a.ID, b.ID) }()
+	go func() { defer wg.Done(); errs[1] = transfer(1, b.ID, a.ID) }()
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("transfer %d: %v", i, err)
+		}
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("ran %d attempts, want 3", got)
+	}
+
+	walletRepo := NewWalletRepository(db)
+	for _, wallet := range []*domain.Wallet{a, b} {
+		got, err := walletRepo.FindByID(ctx, wallet.ID)
+		if err != nil {
+			t.Fatalf("find wallet: %v", err)
+		}
+		if want := amount.Mul(2); got.Balance.Cmp(want) != 0 {
+			t.Errorf("wallet %d balance = %s, want %s", wallet.ID, got.Balance, want)
+		}
+	}
+}
@@ -6,22 +6,20 @@ import (
 	"time"
 
 	"go-moneyku/internal/domain"
-
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type userRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewUserRepository(db *pgxpool.Pool) domain.UserRepository {
+func NewUserRepository(db DBTX) domain.UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *domain.User) error {
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 	query := `
-		INSERT INTO users (username, password, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (username, password, base_currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id
 	`
 
@@ -30,10 +28,11 @@ func (r *userRepository) Create(user *domain.User) error {
 	user.UpdatedAt = now
 
 	err := r.db.QueryRow(
-		context.Background(),
+		ctx,
 		query,
 		user.Username,
 		user.Password,
+		user.BaseCurrency,
 		user.CreatedAt,
 		user.UpdatedAt,
 	).Scan(&user.ID)
@@ -45,48 +44,65 @@ func (r *userRepository) Create(user *domain.User) error {
 	return nil
 }
 
-func (r *userRepository) FindByUsername(username string) (*domain.User, error) {
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
 	query := `
-		SELECT id, username, password, created_at, updated_at
+		SELECT id, username, password, base_currency, created_at, updated_at
 		FROM users
 		WHERE username = $1
 	`
 
 	user := &domain.User{}
-	err := r.db.QueryRow(context.Background(), query, username).Scan(
+	err := r.db.QueryRow(ctx, query, username).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Password,
+		&user.BaseCurrency,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		return nil, notFound("user", err)
 	}
 
 	return user, nil
 }
 
-func (r *userRepository) FindByID(id int) (*domain.User, error) {
+func (r *userRepository) FindByID(ctx context.Context, id int) (*domain.User, error) {
 	query := `
-		SELECT id, username, password, created_at, updated_at
+		SELECT id, username, password, base_currency, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
 
 	user := &domain.User{}
-	err := r.db.QueryRow(context.Background(), query, id).Scan(
+	err := r.db.QueryRow(ctx, query, id).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Password,
+		&user.BaseCurrency,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		return nil, notFound("user", err)
 	}
 
 	return user, nil
 }
+
+func (r *userRepository) UpdateBaseCurrency(ctx context.Context, id int, currency string) error {
+	query := `
+		UPDATE users
+		SET base_currency = $1, updated_at = $2
+		WHERE id = $3
+	`
+
+	_, err := r.db.Exec(ctx, query, currency, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update base currency: %w", err)
+	}
+
+	return nil
+}
@@ -6,22 +6,20 @@ import (
 	"time"
 
 	"go-moneyku/internal/domain"
-
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type walletRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewWalletRepository(db *pgxpool.Pool) domain.WalletRepository {
+func NewWalletRepository(db DBTX) domain.WalletRepository {
 	return &walletRepository{db: db}
 }
 
-func (r *walletRepository) Create(wallet *domain.Wallet) error {
+func (r *walletRepository) Create(ctx context.Context, wallet *domain.Wallet) error {
 	query := `
-		INSERT INTO wallets (user_id, name, balance, currency, type, icon, color, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO wallets (user_id, name, balance, currency, type, account_class, account_class_override, credit_limit, statement_day, due_day, icon, color, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id
 	`
 
@@ -30,13 +28,18 @@ func (r *walletRepository) Create(wallet *domain.Wallet) error {
 	wallet.UpdatedAt = now
 
 	err := r.db.QueryRow(
-		context.Background(),
+		ctx,
 		query,
 		wallet.UserID,
 		wallet.Name,
 		wallet.Balance,
 		wallet.Currency,
 		wallet.Type,
+		wallet.AccountClass,
+		wallet.AccountClassOverride,
+		wallet.CreditLimit,
+		wallet.StatementDay,
+		wallet.DueDay,
 		wallet.Icon,
 		wallet.Color,
 		wallet.CreatedAt,
@@ -50,15 +53,15 @@ func (r *walletRepository) Create(wallet *domain.Wallet) error {
 	return nil
 }
 
-func (r *walletRepository) FindByUserID(userID int) ([]domain.Wallet, error) {
+func (r *walletRepository) FindByUserID(ctx context.Context, userID int) ([]domain.Wallet, error) {
 	query := `
-		SELECT id, user_id, name, balance, currency, type, icon, color, created_at, updated_at
+		SELECT id, user_id, name, balance, currency, type, account_class, account_class_override, credit_limit, statement_day, due_day, icon, color, created_at, updated_at
 		FROM wallets
 		WHERE user_id = $1
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(context.Background(), query, userID)
+	rows, err := r.db.Query(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch wallets: %w", err)
 	}
@@ -74,6 +77,11 @@ func (r *walletRepository) FindByUserID(userID int) ([]domain.Wallet, error) {
 			&wallet.Balance,
 			&wallet.Currency,
 			&wallet.Type,
+			&wallet.AccountClass,
+			&wallet.AccountClassOverride,
+			&wallet.CreditLimit,
+			&wallet.StatementDay,
+			&wallet.DueDay,
 			&wallet.Icon,
 			&wallet.Color,
 			&wallet.CreatedAt,
@@ -82,27 +90,37 @@ func (r *walletRepository) FindByUserID(userID int) ([]domain.Wallet, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan wallet: %w", err)
 		}
+		withWalletCurrency(&wallet)
 		wallets = append(wallets, wallet)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch wallets: %w", err)
+	}
+
 	return wallets, nil
 }
 
-func (r *walletRepository) FindByID(id int) (*domain.Wallet, error) {
+func (r *walletRepository) FindByID(ctx context.Context, id int) (*domain.Wallet, error) {
 	query := `
-		SELECT id, user_id, name, balance, currency, type, icon, color, created_at, updated_at
+		SELECT id, user_id, name, balance, currency, type, account_class, account_class_override, credit_limit, statement_day, due_day, icon, color, created_at, updated_at
 		FROM wallets
 		WHERE id = $1
 	`
 
 	wallet := &domain.Wallet{}
-	err := r.db.QueryRow(context.Background(), query, id).Scan(
+	err := r.db.QueryRow(ctx, query, id).Scan(
 		&wallet.ID,
 		&wallet.UserID,
 		&wallet.Name,
 		&wallet.Balance,
 		&wallet.Currency,
 		&wallet.Type,
+		&wallet.AccountClass,
+		&wallet.AccountClassOverride,
+		&wallet.CreditLimit,
+		&wallet.StatementDay,
+		&wallet.DueDay,
 		&wallet.Icon,
 		&wallet.Color,
 		&wallet.CreatedAt,
@@ -110,28 +128,34 @@ func (r *walletRepository) FindByID(id int) (*domain.Wallet, error) {
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("wallet not found: %w", err)
+		return nil, notFound("wallet", err)
 	}
+	withWalletCurrency(wallet)
 
 	return wallet, nil
 }
 
-func (r *walletRepository) Update(wallet *domain.Wallet) error {
+func (r *walletRepository) Update(ctx context.Context, wallet *domain.Wallet) error {
 	query := `
 		UPDATE wallets
-		SET name = $1, balance = $2, currency = $3, type = $4, icon = $5, color = $6, updated_at = $7
-		WHERE id = $8
+		SET name = $1, currency = $2, type = $3, account_class = $4, account_class_override = $5,
+			credit_limit = $6, statement_day = $7, due_day = $8, icon = $9, color = $10, updated_at = $11
+		WHERE id = $12
 	`
 
 	wallet.UpdatedAt = time.Now()
 
 	_, err := r.db.Exec(
-		context.Background(),
+		ctx,
 		query,
 		wallet.Name,
-		wallet.Balance,
 		wallet.Currency,
 		wallet.Type,
+		wallet.AccountClass,
+		wallet.AccountClassOverride,
+		wallet.CreditLimit,
+		wallet.StatementDay,
+		wallet.DueDay,
 		wallet.Icon,
 		wallet.Color,
 		wallet.UpdatedAt,
@@ -145,10 +169,10 @@ func (r *walletRepository) Update(wallet *domain.Wallet) error {
 	return nil
 }
 
-func (r *walletRepository) Delete(id int) error {
+func (r *walletRepository) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM wallets WHERE id = $1`
 
-	_, err := r.db.Exec(context.Background(), query, id)
+	_, err := r.db.Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete wallet: %w", err)
 	}
@@ -156,17 +180,24 @@ func (r *walletRepository) Delete(id int) error {
 	return nil
 }
 
-func (r *walletRepository) UpdateBalance(id int, newBalance float64) error {
+func (r *walletRepository) AdjustBalance(ctx context.Context, id int, delta domain.Money) error {
+	// The increment is applied by the database so concurrent adjustments never
+	// overwrite each other, and the guard rejects withdrawals that would go past
+	// the credit limit, or below zero for assets without one
 	query := `
 		UPDATE wallets
-		SET balance = $1, updated_at = $2
-		WHERE id = $3
+		SET balance = balance + $1::numeric, updated_at = $2
+		WHERE id = $3 AND (
+			$1::numeric >= 0
+			OR (credit_limit IS NULL AND account_class = 'liability')
+			OR balance + $1::numeric >= -COALESCE(credit_limit, 0)
+		)
 	`
 
-	_, err := r.db.Exec(
-		context.Background(),
+	tag, err := r.db.Exec(
+		ctx,
 		query,
-		newBalance,
+		delta,
 		time.Now(),
 		id,
 	)
@@ -175,5 +206,21 @@ func (r *walletRepository) UpdateBalance(id int, newBalance float64) error {
 		return fmt.Errorf("failed to update wallet balance: %w", err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrInsufficientBalance
+	}
+
 	return nil
 }
+
+// withWalletCurrency tags the amounts of a scanned wallet with its currency
+func withWalletCurrency(wallet *domain.Wallet) {
+	wallet.Balance = wallet.Balance.WithCurrency(wallet.Currency)
+	if wallet.CreditLimit != nil {
+		limit := wallet.CreditLimit.WithCurrency(wallet.Currency)
+		wallet.CreditLimit = &limit
+	}
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go-moneyku/internal/domain"
+)
+
+func TestAdjustBalanceConcurrentExpenses(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	uow := NewUnitOfWork(db, 0)
+
+	// 50 expenses of 3.000 against 100.000: only 33 of them fit
+	const expenses = 50
+	wallet := testWallet(t, db, domain.NewMoney(100_000_00, "IDR"))
+	expense := domain.NewMoney(-3_000_00, "IDR")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, expenses)
+	for i := 0; i < expenses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- uow.Do(ctx, func(repos domain.Repositories) error {
+				return repos.Wallets.AdjustBalance(ctx, wallet.ID, expense)
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded, rejected := 0, 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, domain.ErrInsufficientBalance):
+			rejected++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 33 || rejected != 17 {
+		t.Errorf("got %d succeeded and %d rejected, want 33 and 17", succeeded, rejected)
+	}
+
+	got, err := NewWalletRepository(db).FindByID(ctx, wallet.ID)
+	if err != nil {
+		t.Fatalf("find wallet: %v", err)
+	}
+	if want := domain.NewMoney(1_000_00, "IDR"); got.Balance.Cmp(want) != 0 {
+		t.Errorf("final balance = %s, want %s", got.Balance, want)
+	}
+}
+
+func TestAdjustBalanceMissingWallet(t *testing.T) {
+	db := testDB(t)
+
+	err := NewWalletRepository(db).AdjustBalance(context.Background(), -1, domain.NewMoney(-1, "IDR"))
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("got %v, want a not found error", err)
+	}
+}
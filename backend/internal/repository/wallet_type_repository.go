@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+type walletTypeRepository struct {
+	db DBTX
+}
+
+func NewWalletTypeRepository(db DBTX) domain.WalletTypeRepository {
+	return &walletTypeRepository{db: db}
+}
+
+const walletTypeColumns = `id, user_id, key, name, account_class, include_in_net_worth, display_group, created_at, updated_at`
+
+func (r *walletTypeRepository) Create(ctx context.Context, walletType *domain.WalletType) error {
+	query := `
+		INSERT INTO wallet_types (user_id, key, name, account_class, include_in_net_worth, display_group, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	now := time.Now()
+	walletType.CreatedAt = now
+	walletType.UpdatedAt = now
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		walletType.UserID,
+		walletType.Key,
+		walletType.Name,
+		walletType.AccountClass,
+		walletType.IncludeInNetWorth,
+		walletType.Group,
+		walletType.CreatedAt,
+		walletType.UpdatedAt,
+	).Scan(&walletType.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create wallet type: %w", err)
+	}
+
+	return nil
+}
+
+func (r *walletTypeRepository) FindByUserID(ctx context.Context, userID int) ([]domain.WalletType, error) {
+	query := `
+		SELECT ` + walletTypeColumns + `
+		FROM wallet_types
+		WHERE user_id = $1 OR user_id IS NULL
+		ORDER BY user_id IS NULL, display_group, name
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wallet types: %w", err)
+	}
+	defer rows.Close()
+
+	var walletTypes []domain.WalletType
+	for rows.Next() {
+		var walletType domain.WalletType
+		if err := scanWalletType(rows, &walletType); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet type: %w", err)
+		}
+		walletTypes = append(walletTypes, walletType)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch wallet types: %w", err)
+	}
+
+	return walletTypes, nil
+}
+
+func (r *walletTypeRepository) FindByID(ctx context.Context, id int) (*domain.WalletType, error) {
+	query := `
+		SELECT ` + walletTypeColumns + `
+		FROM wallet_types
+		WHERE id = $1
+	`
+
+	walletType := &domain.WalletType{}
+	if err := scanWalletType(r.db.QueryRow(ctx, query, id), walletType); err != nil {
+		return nil, notFound("wallet type", err)
+	}
+
+	return walletType, nil
+}
+
+func (r *walletTypeRepository) Update(ctx context.Context, walletType *domain.WalletType) error {
+	query := `
+		UPDATE wallet_types
+		SET name = $1, account_class = $2, include_in_net_worth = $3, display_group = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	walletType.UpdatedAt = time.Now()
+
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		walletType.Name,
+		walletType.AccountClass,
+		walletType.IncludeInNetWorth,
+		walletType.Group,
+		walletType.UpdatedAt,
+		walletType.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update wallet type: %w", err)
+	}
+
+	return nil
+}
+
+func (r *walletTypeRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM wallet_types WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete wallet type: %w", err)
+	}
+
+	return nil
+}
+
+func scanWalletType(row interface {
+	Scan(dest ...interface{}) error
+}, walletType *domain.WalletType) error {
+	return row.Scan(
+		&walletType.ID,
+		&walletType.UserID,
+		&walletType.Key,
+		&walletType.Name,
+		&walletType.AccountClass,
+		&walletType.IncludeInNetWorth,
+		&walletType.Group,
+		&walletType.CreatedAt,
+		&walletType.UpdatedAt,
+	)
+}
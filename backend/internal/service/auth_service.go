@@ -1,80 +1,112 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"go-moneyku/internal/domain"
 	"go-moneyku/internal/utils"
+	"go-moneyku/internal/validation"
+)
+
+// Refresh and access token errors. A reused refresh token was most likely
+// stolen, so its session is revoked and the client has to log in again.
+var (
+	errInvalidRefreshToken = domain.Unauthenticated("invalid_refresh_token", "invalid or expired refresh token")
+	errRefreshTokenReused  = domain.Unauthenticated("refresh_token_reused", "refresh token was already used, please log in again")
+	errSessionRevoked      = domain.Unauthenticated("session_revoked", "session has been logged out, please log in again")
 )
 
 type AuthService struct {
-	userRepo domain.UserRepository
+	userRepo    domain.UserRepository
+	sessionRepo domain.SessionRepository
+	unitOfWork  domain.UnitOfWork
+	refreshTTL  time.Duration
 }
 
-func NewAuthService(userRepo domain.UserRepository) *AuthService {
+// NewAuthService issues refresh tokens that expire after refreshTTL unless
+// they are exchanged for new ones
+func NewAuthService(userRepo domain.UserRepository, sessionRepo domain.SessionRepository, unitOfWork domain.UnitOfWork, refreshTTL time.Duration) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		unitOfWork:  unitOfWork,
+		refreshTTL:  refreshTTL,
 	}
 }
 
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type SignupRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username     string `json:"username" validate:"required,max=50"`
+	Password     string `json:"password" validate:"required,min=6,max=72"`             // bcrypt uses at most 72 bytes
+	BaseCurrency string `json:"base_currency,omitempty" validate:"omitempty,currency"` // Defaults to IDR
+}
+
+type UpdateUserRequest struct {
+	BaseCurrency string `json:"base_currency" validate:"required,currency"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 type AuthResponse struct {
-	Token string      `json:"token"`
-	User  domain.User `json:"user"`
+	Token        string      `json:"token"`      // Short-lived access token
+	ExpiresAt    time.Time   `json:"expires_at"` // When Token expires
+	RefreshToken string      `json:"refresh_token"`
+	User         domain.User `json:"user"`
 }
 
-func (s *AuthService) Login(req LoginRequest) (*AuthResponse, error) {
-	// Validate input
-	if req.Username == "" || req.Password == "" {
-		return nil, fmt.Errorf("username and password are required")
+func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*AuthResponse, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
 	}
 
 	// Find user by username
-	user, err := s.userRepo.FindByUsername(req.Username)
+	user, err := s.userRepo.FindByUsername(ctx, req.Username)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.Unauthenticated("invalid_credentials", "invalid username or password")
+	}
 	if err != nil {
-		return nil, fmt.Errorf("invalid username or password")
+		return nil, err
 	}
 
 	// Check password
 	if err := utils.CheckPassword(user.Password, req.Password); err != nil {
-		return nil, fmt.Errorf("invalid username or password")
-	}
-
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Username)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, domain.Unauthenticated("invalid_credentials", "invalid username or password")
 	}
 
-	return &AuthResponse{
-		Token: token,
-		User:  *user,
-	}, nil
+	return s.startSession(ctx, user)
 }
 
-func (s *AuthService) Signup(req SignupRequest) (*AuthResponse, error) {
-	// Validate input
-	if req.Username == "" || req.Password == "" {
-		return nil, fmt.Errorf("username and password are required")
+func (s *AuthService) Signup(ctx context.Context, req SignupRequest) (*AuthResponse, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
 	}
 
-	if len(req.Password) < 6 {
-		return nil, fmt.Errorf("password must be at least 6 characters")
+	baseCurrency := defaultBaseCurrency
+	if req.BaseCurrency != "" {
+		currency, err := normalizeCurrency(req.BaseCurrency)
+		if err != nil {
+			return nil, err
+		}
+		baseCurrency = currency
 	}
 
 	// Check if username already exists
-	existingUser, _ := s.userRepo.FindByUsername(req.Username)
-	if existingUser != nil {
-		return nil, fmt.Errorf("username already exists")
+	_, err := s.userRepo.FindByUsername(ctx, req.Username)
+	if err == nil {
+		return nil, domain.Conflict("username_taken", "username already exists")
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
 	}
 
 	// Hash password
@@ -85,30 +117,202 @@ func (s *AuthService) Signup(req SignupRequest) (*AuthResponse, error) {
 
 	// Create user
 	user := &domain.User{
-		Username: req.Username,
-		Password: hashedPassword,
+		Username:     req.Username,
+		Password:     hashedPassword,
+		BaseCurrency: baseCurrency,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Username)
+	return s.startSession(ctx, user)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. Each refresh token can be used once.
+func (s *AuthService) Refresh(ctx context.Context, req RefreshRequest) (*AuthResponse, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	token, err := s.sessionRepo.FindRefreshToken(ctx, utils.HashRefreshToken(req.RefreshToken))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, errInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, token.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if session.IsRevoked() || !now.Before(token.ExpiresAt) {
+		return nil, errInvalidRefreshToken
+	}
+
+	var refreshToken string
+	err = s.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		// Claiming the token fails when it was used before, including by a
+		// concurrent refresh
+		claimed, err := repos.Sessions.UseRefreshToken(ctx, token.ID, now)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return errRefreshTokenReused
+		}
+
+		refreshToken, err = s.createRefreshToken(ctx, repos.Sessions, session.ID)
+		return err
+	})
+	if errors.Is(err, errRefreshTokenReused) {
+		// Revoked outside the unit of work, which has been rolled back
+		log.Printf("Auth: refresh token of session %d was reused, revoking the session", session.ID)
+		if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+			return nil, err
+		}
+		return nil, errRefreshTokenReused
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authResponse(user, session.ID, refreshToken)
+}
+
+// Logout revokes the session the request was made with. Its refresh token and
+// access tokens stop working at once.
+func (s *AuthService) Logout(ctx context.Context, userID int, sessionID int) error {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return domain.Forbidden("session")
+	}
+
+	return s.sessionRepo.Revoke(ctx, sessionID)
+}
+
+// LogoutAll revokes every session of the user, logging out all devices
+func (s *AuthService) LogoutAll(ctx context.Context, userID int) error {
+	return s.sessionRepo.RevokeByUserID(ctx, userID)
+}
+
+// CheckSession returns an error unless access tokens of the session can still
+// be used
+func (s *AuthService) CheckSession(ctx context.Context, userID int, sessionID int) error {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return errSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID || session.IsRevoked() {
+		return errSessionRevoked
+	}
+
+	return nil
+}
+
+// RunDue deletes expired refresh tokens and the sessions left without any
+func (s *AuthService) RunDue(ctx context.Context, now time.Time) {
+	deleted, err := s.sessionRepo.DeleteExpired(ctx, now)
+	if err != nil {
+		log.Printf("Auth: failed to delete expired refresh tokens: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Auth: deleted %d expired refresh tokens", deleted)
+	}
+}
+
+// startSession starts a new session for the user, as on a new device
+func (s *AuthService) startSession(ctx context.Context, user *domain.User) (*AuthResponse, error) {
+	session := &domain.Session{UserID: user.ID}
+	var refreshToken string
+	err := s.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		if err := repos.Sessions.Create(ctx, session); err != nil {
+			return err
+		}
+
+		var err error
+		refreshToken, err = s.createRefreshToken(ctx, repos.Sessions, session.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authResponse(user, session.ID, refreshToken)
+}
+
+// createRefreshToken stores a new refresh token for the session and returns
+// the token, which is only ever sent to the client
+func (s *AuthService) createRefreshToken(ctx context.Context, sessionRepo domain.SessionRepository, sessionID int) (string, error) {
+	token, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	err = sessionRepo.CreateRefreshToken(ctx, &domain.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: utils.HashRefreshToken(token),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *AuthService) authResponse(user *domain.User, sessionID int, refreshToken string) (*AuthResponse, error) {
+	token, expiresAt, err := utils.GenerateToken(user.ID, user.Username, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
 	return &AuthResponse{
-		Token: token,
-		User:  *user,
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+		User:         *user,
 	}, nil
 }
 
-func (s *AuthService) GetUserByID(userID int) (*domain.User, error) {
-	user, err := s.userRepo.FindByID(userID)
+func (s *AuthService) GetUserByID(ctx context.Context, userID int) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		return nil, err
 	}
 	return user, nil
 }
+
+// UpdateUser changes the settings of the user, currently the base currency
+// that totals are converted to
+func (s *AuthService) UpdateUser(ctx context.Context, userID int, req UpdateUserRequest) (*domain.User, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	currency, err := normalizeCurrency(req.BaseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateBaseCurrency(ctx, userID, currency); err != nil {
+		return nil, err
+	}
+
+	return s.GetUserByID(ctx, userID)
+}
@@ -0,0 +1,494 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+type BudgetState string
+
+const (
+	BudgetStateOK       BudgetState = "ok"
+	BudgetStateWarning  BudgetState = "warning"  // 80% or more of the limit is used
+	BudgetStateExceeded BudgetState = "exceeded" // more than the limit is spent
+)
+
+type BudgetService struct {
+	budgetRepo          domain.BudgetRepository
+	transactionRepo     domain.TransactionRepository
+	categoryRepo        domain.CategoryRepository
+	exchangeRateService *ExchangeRateService
+}
+
+func NewBudgetService(budgetRepo domain.BudgetRepository, transactionRepo domain.TransactionRepository, categoryRepo domain.CategoryRepository, exchangeRateService *ExchangeRateService) *BudgetService {
+	return &BudgetService{
+		budgetRepo:          budgetRepo,
+		transactionRepo:     transactionRepo,
+		categoryRepo:        categoryRepo,
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+type BudgetRequest struct {
+	CategoryID *int                `json:"category_id,omitempty"`
+	Name       string              `json:"name"`
+	Amount     domain.Money        `json:"amount"`
+	Period     domain.BudgetPeriod `json:"period"`
+	StartDay   *int                `json:"start_day,omitempty"`
+	PeriodDays *int                `json:"period_days,omitempty"`
+	StartDate  string              `json:"start_date"`
+	Rollover   bool                `json:"rollover"`
+}
+
+// BudgetStatus is a budget together with its usage in the current period
+type BudgetStatus struct {
+	domain.Budget
+	PeriodStart time.Time    `json:"period_start"`
+	PeriodEnd   time.Time    `json:"period_end"` // Exclusive
+	RolledOver  domain.Money `json:"rolled_over"`
+	Limit       domain.Money `json:"limit"` // Amount plus the rolled over amount
+	Spent       domain.Money `json:"spent"`
+	Remaining   domain.Money `json:"remaining"`
+	PercentUsed float64      `json:"percent_used"`
+	State       BudgetState  `json:"state"`
+	// MissingRates lists the currency pairs without a rate; expenses in those
+	// currencies are left out of spent
+	MissingRates []string `json:"missing_rates,omitempty"`
+}
+
+// BudgetAlert reports a budget that a new expense pushed into a worse state
+type BudgetAlert struct {
+	BudgetID    int          `json:"budget_id"`
+	Name        string       `json:"name"`
+	State       BudgetState  `json:"state"`
+	Spent       domain.Money `json:"spent"`
+	Limit       domain.Money `json:"limit"`
+	PercentUsed float64      `json:"percent_used"`
+}
+
+func (s *BudgetService) CreateBudget(ctx context.Context, userID int, req BudgetRequest) (*BudgetStatus, error) {
+	budget := &domain.Budget{UserID: userID}
+	if err := s.applyRequest(ctx, budget, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.budgetRepo.Create(ctx, budget); err != nil {
+		return nil, fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return s.status(ctx, budget, time.Now())
+}
+
+func (s *BudgetService) GetUserBudgets(ctx context.Context, userID int) ([]BudgetStatus, error) {
+	budgets, err := s.budgetRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch budgets: %w", err)
+	}
+
+	now := time.Now()
+	statuses := make([]BudgetStatus, 0, len(budgets))
+	for i := range budgets {
+		status, err := s.status(ctx, &budgets[i], now)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+
+	return statuses, nil
+}
+
+func (s *BudgetService) GetBudget(ctx context.Context, budgetID int, userID int) (*BudgetStatus, error) {
+	budget, err := s.findOwnedBudget(ctx, budgetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.status(ctx, budget, time.Now())
+}
+
+func (s *BudgetService) UpdateBudget(ctx context.Context, budgetID int, userID int, req BudgetRequest) (*BudgetStatus, error) {
+	budget, err := s.findOwnedBudget(ctx, budgetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRequest(ctx, budget, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.budgetRepo.Update(ctx, budget); err != nil {
+		return nil, fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	return s.status(ctx, budget, time.Now())
+}
+
+func (s *BudgetService) DeleteBudget(ctx context.Context, budgetID int, userID int) error {
+	if _, err := s.findOwnedBudget(ctx, budgetID, userID); err != nil {
+		return err
+	}
+
+	if err := s.budgetRepo.Delete(ctx, budgetID); err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	return nil
+}
+
+// AlertsForTransaction returns the budgets that the expense pushed past 80% or
+// 100% of their limit in the period it falls in
+func (s *BudgetService) AlertsForTransaction(ctx context.Context, transaction *domain.Transaction) ([]BudgetAlert, error) {
+	if transaction.Type != domain.TransactionTypeExpense {
+		return nil, nil
+	}
+
+	budgets, err := s.budgetRepo.FindByUserID(ctx, transaction.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch budgets: %w", err)
+	}
+	if len(budgets) == 0 {
+		return nil, nil
+	}
+
+	categories, err := s.categoryRepo.FindByUserID(ctx, transaction.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
+	}
+
+	converter, err := s.exchangeRateService.converter(ctx, transaction.UserID)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := converter.convert(ctx, transaction.Amount, transaction.Amount.Currency(), transaction.Date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert transaction amount: %w", err)
+	}
+
+	var alerts []BudgetAlert
+	for i := range budgets {
+		budget := &budgets[i]
+		if dateOf(transaction.Date).Before(dateOf(budget.StartDate)) {
+			continue
+		}
+		if !budgetCovers(budget, categories, transaction.CategoryID) {
+			continue
+		}
+
+		status, err := s.statusWithCategories(ctx, budget, categories, converter, transaction.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		before := budgetState(status.Spent.Sub(amount), status.Limit)
+		if stateRank(status.State) > stateRank(before) {
+			alerts = append(alerts, BudgetAlert{
+				BudgetID:    budget.ID,
+				Name:        budget.Name,
+				State:       status.State,
+				Spent:       status.Spent,
+				Limit:       status.Limit,
+				PercentUsed: status.PercentUsed,
+			})
+		}
+	}
+
+	return alerts, nil
+}
+
+func (s *BudgetService) findOwnedBudget(ctx context.Context, budgetID int, userID int) (*domain.Budget, error) {
+	budget, err := s.budgetRepo.FindByID(ctx, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	if budget.UserID != userID {
+		return nil, domain.Forbidden("budget")
+	}
+	return budget, nil
+}
+
+// applyRequest validates the request and copies it onto the budget
+func (s *BudgetService) applyRequest(ctx context.Context, budget *domain.Budget, req BudgetRequest) error {
+	if !req.Amount.IsPositive() {
+		return domain.Invalid("amount must be greater than zero")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if req.CategoryID != nil {
+		category, err := s.categoryRepo.FindByID(ctx, *req.CategoryID)
+		if err != nil {
+			return err
+		}
+		if category.UserID != budget.UserID {
+			return domain.Forbidden("category")
+		}
+		if category.Type != domain.CategoryTypeExpense {
+			return domain.Invalid("budgets can only be set on expense categories")
+		}
+		if name == "" {
+			name = category.Name
+		}
+	} else if name == "" {
+		name = "Overall"
+	}
+
+	startDate := dateOf(time.Now())
+	if req.StartDate != "" {
+		var err error
+		startDate, err = time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return domain.Invalid("invalid start date format (use YYYY-MM-DD)")
+		}
+	}
+
+	budget.StartDay = 0
+	budget.PeriodDays = nil
+
+	switch req.Period {
+	case domain.BudgetPeriodMonthly:
+		startDay := 1
+		if req.StartDay != nil {
+			startDay = *req.StartDay
+		}
+		if startDay < 1 || startDay > 31 {
+			return domain.Invalid("start day must be between 1 and 31 for monthly budgets")
+		}
+		budget.StartDay = startDay
+
+	case domain.BudgetPeriodWeekly:
+		startDay := int(time.Monday)
+		if req.StartDay != nil {
+			startDay = *req.StartDay
+		}
+		if startDay < 0 || startDay > 6 {
+			return domain.Invalid("start day must be between 0 (Sunday) and 6 (Saturday) for weekly budgets")
+		}
+		budget.StartDay = startDay
+
+	case domain.BudgetPeriodCustom:
+		if req.PeriodDays == nil || *req.PeriodDays <= 0 {
+			return domain.Invalid("period days must be greater than zero for custom budgets")
+		}
+		periodDays := *req.PeriodDays
+		budget.PeriodDays = &periodDays
+
+	default:
+		return domain.Invalid("invalid period (use monthly, weekly or custom)")
+	}
+
+	budget.CategoryID = req.CategoryID
+	budget.Name = name
+	budget.Amount = req.Amount
+	budget.Period = req.Period
+	budget.StartDate = startDate
+	budget.Rollover = req.Rollover
+
+	return nil
+}
+
+func (s *BudgetService) status(ctx context.Context, budget *domain.Budget, date time.Time) (*BudgetStatus, error) {
+	var categories []domain.Category
+	if budget.CategoryID != nil {
+		var err error
+		categories, err = s.categoryRepo.FindByUserID(ctx, budget.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch categories: %w", err)
+		}
+	}
+
+	converter, err := s.exchangeRateService.converter(ctx, budget.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.statusWithCategories(ctx, budget, categories, converter, date)
+}
+
+// statusWithCategories computes the usage of the budget in the period that
+// contains date
+func (s *BudgetService) statusWithCategories(ctx context.Context, budget *domain.Budget, categories []domain.Category, converter *currencyConverter, date time.Time) (*BudgetStatus, error) {
+	var categoryIDs []int
+	if budget.CategoryID != nil {
+		categoryIDs = categoryTreeIDs(categories, *budget.CategoryID)
+	}
+
+	start, end := budgetPeriod(budget, date)
+	spent, err := s.spent(ctx, converter, budget.UserID, categoryIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var rolledOver domain.Money
+	if budget.Rollover {
+		// Only a previous period in which the budget already existed rolls over
+		previousStart, previousEnd := budgetPeriod(budget, start.AddDate(0, 0, -1))
+		if previousEnd.After(dateOf(budget.StartDate)) {
+			previousSpent, err := s.spent(ctx, converter, budget.UserID, categoryIDs, previousStart, previousEnd)
+			if err != nil {
+				return nil, err
+			}
+			if unused := budget.Amount.Sub(previousSpent); unused.IsPositive() {
+				rolledOver = unused
+			}
+		}
+	}
+
+	// Budgets are in the base currency, as the expenses are converted to it
+	inBase := *budget
+	inBase.Amount = budget.Amount.WithCurrency(converter.base)
+	limit := inBase.Amount.Add(rolledOver)
+	return &BudgetStatus{
+		Budget:       inBase,
+		PeriodStart:  start,
+		PeriodEnd:    end,
+		RolledOver:   rolledOver,
+		Limit:        limit,
+		Spent:        spent,
+		Remaining:    limit.Sub(spent),
+		PercentUsed:  percentUsed(spent, limit),
+		State:        budgetState(spent, limit),
+		MissingRates: converter.missingRates(),
+	}, nil
+}
+
+// spent totals the expenses in [start, end) in the base currency
+func (s *BudgetService) spent(ctx context.Context, converter *currencyConverter, userID int, categoryIDs []int, start, end time.Time) (domain.Money, error) {
+	totals, err := s.transactionRepo.SumExpensesByDay(ctx, userID, categoryIDs, start, end)
+	if err != nil {
+		return domain.Money{}, err
+	}
+
+	spent := domain.NewMoney(0, converter.base)
+	for _, total := range totals {
+		amount, err := converter.convert(ctx, total.Amount, total.Currency, total.Date)
+		if err != nil {
+			return domain.Money{}, fmt.Errorf("failed to convert expenses: %w", err)
+		}
+		spent = spent.Add(amount)
+	}
+	return spent, nil
+}
+
+// budgetPeriod returns the period [start, end) of the budget that contains date
+func budgetPeriod(budget *domain.Budget, date time.Time) (time.Time, time.Time) {
+	day := dateOf(date)
+
+	switch budget.Period {
+	case domain.BudgetPeriodWeekly:
+		offset := (int(day.Weekday()) - budget.StartDay + 7) % 7
+		start := day.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 7)
+
+	case domain.BudgetPeriodCustom:
+		length := 1
+		if budget.PeriodDays != nil && *budget.PeriodDays > 0 {
+			length = *budget.PeriodDays
+		}
+		anchor := dateOf(budget.StartDate)
+		days := int(day.Sub(anchor).Hours() / 24)
+		periods := days / length
+		if days < 0 && days%length != 0 {
+			periods--
+		}
+		start := anchor.AddDate(0, 0, periods*length)
+		return start, start.AddDate(0, 0, length)
+
+	default:
+		start := monthlyPeriodStart(day.Year(), day.Month(), budget.StartDay)
+		if day.Before(start) {
+			start = monthlyPeriodStart(day.Year(), day.Month()-1, budget.StartDay)
+		}
+		return start, monthlyPeriodStart(start.Year(), start.Month()+1, budget.StartDay)
+	}
+}
+
+// monthlyPeriodStart returns the given day of the month, or the last day of
+// shorter months
+func monthlyPeriodStart(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	if last := daysInMonth(first); day > last {
+		day = last
+	}
+	if day < 1 {
+		day = 1
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// budgetCovers reports whether an expense in the category counts toward the budget
+func budgetCovers(budget *domain.Budget, categories []domain.Category, categoryID *int) bool {
+	if budget.CategoryID == nil {
+		return true
+	}
+	if categoryID == nil {
+		return false
+	}
+	for _, id := range categoryTreeIDs(categories, *budget.CategoryID) {
+		if id == *categoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// categoryTreeIDs returns the category and all of its descendants
+func categoryTreeIDs(categories []domain.Category, rootID int) []int {
+	children := make(map[int][]int)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []int{rootID}
+	seen := map[int]bool{rootID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+func budgetState(spent, limit domain.Money) BudgetState {
+	switch {
+	case spent.Cmp(limit) > 0:
+		return BudgetStateExceeded
+	// spent >= 80% of limit, compared exactly in minor units
+	case spent.Minor()*5 >= limit.Minor()*4 && spent.IsPositive():
+		return BudgetStateWarning
+	default:
+		return BudgetStateOK
+	}
+}
+
+func stateRank(state BudgetState) int {
+	switch state {
+	case BudgetStateExceeded:
+		return 2
+	case BudgetStateWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// percentUsed returns the spent share of the limit, rounded to two decimals
+func percentUsed(spent, limit domain.Money) float64 {
+	if !limit.IsPositive() {
+		if spent.IsPositive() {
+			return 100
+		}
+		return 0
+	}
+	percent := float64(spent.Minor()) * 100 / float64(limit.Minor())
+	return math.Round(percent*100) / 100
+}
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-moneyku/internal/domain"
+)
+
+type CategoryService struct {
+	categoryRepo domain.CategoryRepository
+}
+
+func NewCategoryService(categoryRepo domain.CategoryRepository) *CategoryService {
+	return &CategoryService{
+		categoryRepo: categoryRepo,
+	}
+}
+
+type CreateCategoryRequest struct {
+	Name     string              `json:"name"`
+	Type     domain.CategoryType `json:"type"`
+	ParentID *int                `json:"parent_id,omitempty"`
+	Icon     string              `json:"icon"`
+	Color    string              `json:"color"`
+}
+
+type UpdateCategoryRequest struct {
+	Name     string `json:"name"`
+	ParentID *int   `json:"parent_id,omitempty"` // 0 moves the category to the top level
+	Icon     string `json:"icon"`
+	Color    string `json:"color"`
+}
+
+func (s *CategoryService) CreateCategory(ctx context.Context, userID int, req CreateCategoryRequest) (*domain.Category, error) {
+	// Validate input
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, domain.Invalid("category name is required")
+	}
+	if req.Type != domain.CategoryTypeIncome && req.Type != domain.CategoryTypeExpense {
+		return nil, domain.Invalid("invalid category type (use income or expense)")
+	}
+
+	// Names are unique per type regardless of case, so "Makan" and "makan" are one category
+	_, err := s.categoryRepo.FindByName(ctx, userID, req.Type, name)
+	if err == nil {
+		return nil, domain.Conflict("category_exists", "category already exists")
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	category := &domain.Category{
+		UserID:   userID,
+		ParentID: req.ParentID,
+		Name:     name,
+		Type:     req.Type,
+		Icon:     req.Icon,
+		Color:    req.Color,
+	}
+
+	if err := s.validateParent(ctx, category); err != nil {
+		return nil, err
+	}
+
+	// A concurrent request may take the name after the check above; the
+	// repository reports that as a conflict
+	if err := s.categoryRepo.Create(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *CategoryService) GetUserCategories(ctx context.Context, userID int) ([]domain.Category, error) {
+	categories, err := s.categoryRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
+	}
+	return categories, nil
+}
+
+func (s *CategoryService) GetCategoryByID(ctx context.Context, categoryID int, userID int) (*domain.Category, error) {
+	category, err := s.categoryRepo.FindByID(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if category.UserID != userID {
+		return nil, domain.Forbidden("category")
+	}
+
+	return category, nil
+}
+
+func (s *CategoryService) UpdateCategory(ctx context.Context, categoryID int, userID int, req UpdateCategoryRequest) (*domain.Category, error) {
+	// Get category and verify ownership
+	category, err := s.GetCategoryByID(ctx, categoryID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update fields
+	if name := strings.TrimSpace(req.Name); name != "" && name != category.Name {
+		existing, err := s.categoryRepo.FindByName(ctx, userID, category.Type, name)
+		if err == nil && existing.ID != category.ID {
+			return nil, domain.Conflict("category_exists", "category already exists")
+		}
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		category.Name = name
+	}
+	// The parent is kept unless parent_id is sent; 0 detaches it
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			category.ParentID = nil
+		} else {
+			category.ParentID = req.ParentID
+		}
+	}
+	if req.Icon != "" {
+		category.Icon = req.Icon
+	}
+	if req.Color != "" {
+		category.Color = req.Color
+	}
+
+	if err := s.validateParent(ctx, category); err != nil {
+		return nil, err
+	}
+
+	// A concurrent request may take the name after the check above; the
+	// repository reports that as a conflict
+	if err := s.categoryRepo.Update(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *CategoryService) DeleteCategory(ctx context.Context, categoryID int, userID int) error {
+	// Get category and verify ownership
+	category, err := s.GetCategoryByID(ctx, categoryID, userID)
+	if err != nil {
+		return err
+	}
+
+	children, err := s.categoryRepo.CountChildren(ctx, category.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check child categories: %w", err)
+	}
+	if children > 0 {
+		return domain.Conflict("category_has_children", "cannot delete category with subcategories")
+	}
+
+	// Transactions keep the category name and lose the reference
+	if err := s.categoryRepo.Delete(ctx, category.ID); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	return nil
+}
+
+// validateParent checks that the parent belongs to the same user, has the same
+// type and that linking to it does not create a cycle
+func (s *CategoryService) validateParent(ctx context.Context, category *domain.Category) error {
+	seen := map[int]bool{category.ID: true}
+	parentID := category.ParentID
+
+	for parentID != nil {
+		if seen[*parentID] {
+			return domain.Invalid("category cannot be its own ancestor")
+		}
+		seen[*parentID] = true
+
+		parent, err := s.categoryRepo.FindByID(ctx, *parentID)
+		if err != nil {
+			return err
+		}
+		if parent.UserID != category.UserID {
+			return domain.Forbidden("parent category")
+		}
+		if parent.Type != category.Type {
+			return domain.Invalid("parent category must have the same type")
+		}
+		parentID = parent.ParentID
+	}
+
+	return nil
+}
+
+// resolveTransactionCategory links a transaction to its category. A category_id
+// takes precedence; otherwise the free-text name is matched case-insensitively
+// and the category is created on first use. Transfers are not categorised.
+func resolveTransactionCategory(ctx context.Context, categoryRepo domain.CategoryRepository, userID int, transaction *domain.Transaction) error {
+	if transaction.Type == domain.TransactionTypeTransfer {
+		transaction.CategoryID = nil
+		return nil
+	}
+	categoryType := domain.CategoryType(transaction.Type)
+
+	if transaction.CategoryID != nil {
+		category, err := categoryRepo.FindByID(ctx, *transaction.CategoryID)
+		if err != nil {
+			return err
+		}
+		if category.UserID != userID {
+			return domain.Forbidden("category")
+		}
+		if category.Type != categoryType {
+			return domain.Invalid("category type does not match transaction type")
+		}
+		transaction.Category = category.Name
+		return nil
+	}
+
+	name := strings.TrimSpace(transaction.Category)
+	if name == "" {
+		transaction.Category = ""
+		return nil
+	}
+
+	category, err := categoryRepo.FindByName(ctx, userID, categoryType, name)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		// Two transactions may both miss the name and create it; the
+		// second one gets the category the first created
+		category = &domain.Category{
+			UserID: userID,
+			Name:   name,
+			Type:   categoryType,
+		}
+		if err := categoryRepo.FindOrCreate(ctx, category); err != nil {
+			return err
+		}
+	}
+
+	transaction.CategoryID = &category.ID
+	transaction.Category = category.Name
+	return nil
+}
+
+// rollUpSpending totals expense transactions per top-level category. Spending in
+// a subcategory counts toward its root, with a breakdown per direct child.
+// Transactions without a category reference are grouped by their name.
+func rollUpSpending(transactions []domain.Transaction, categories []domain.Category) []SpendingByCategory {
+	byID := make(map[int]*domain.Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+
+	// path returns the category's ancestors from the root down to itself
+	path := func(category *domain.Category) []*domain.Category {
+		chain := []*domain.Category{category}
+		for len(chain) <= len(categories) && category.ParentID != nil {
+			parent, ok := byID[*category.ParentID]
+			if !ok {
+				break
+			}
+			chain = append([]*domain.Category{parent}, chain...)
+			category = parent
+		}
+		return chain
+	}
+
+	roots := make(map[string]*SpendingByCategory)
+	children := make(map[string]map[int]*SpendingByCategory)
+	var order []string
+
+	bucket := func(key string, entry SpendingByCategory) *SpendingByCategory {
+		if existing, ok := roots[key]; ok {
+			return existing
+		}
+		roots[key] = &entry
+		order = append(order, key)
+		return roots[key]
+	}
+
+	for _, transaction := range transactions {
+		if transaction.Type != domain.TransactionTypeExpense {
+			continue
+		}
+
+		var category *domain.Category
+		if transaction.CategoryID != nil {
+			category = byID[*transaction.CategoryID]
+		}
+
+		if category == nil {
+			name := strings.TrimSpace(transaction.Category)
+			if name == "" {
+				name = "Uncategorized"
+			}
+			root := bucket("name:"+strings.ToLower(name), SpendingByCategory{Category: name})
+			root.Amount = root.Amount.Add(transaction.Amount)
+			continue
+		}
+
+		chain := path(category)
+		rootCategory := chain[0]
+		key := fmt.Sprintf("id:%d", rootCategory.ID)
+		rootID := rootCategory.ID
+		root := bucket(key, SpendingByCategory{
+			CategoryID: &rootID,
+			Category:   rootCategory.Name,
+			Icon:       rootCategory.Icon,
+			Color:      rootCategory.Color,
+		})
+		root.Amount = root.Amount.Add(transaction.Amount)
+
+		if len(chain) > 1 {
+			child := chain[1]
+			if children[key] == nil {
+				children[key] = make(map[int]*SpendingByCategory)
+			}
+			if children[key][child.ID] == nil {
+				childID := child.ID
+				children[key][child.ID] = &SpendingByCategory{
+					CategoryID: &childID,
+					Category:   child.Name,
+					Icon:       child.Icon,
+					Color:      child.Color,
+				}
+			}
+			children[key][child.ID].Amount = children[key][child.ID].Amount.Add(transaction.Amount)
+		}
+	}
+
+	result := make([]SpendingByCategory, 0, len(order))
+	for _, key := range order {
+		root := *roots[key]
+		for _, child := range children[key] {
+			root.Subcategories = append(root.Subcategories, *child)
+		}
+		sortSpending(root.Subcategories)
+		result = append(result, root)
+	}
+	sortSpending(result)
+
+	return result
+}
+
+// sortSpending orders categories by amount, largest first
+func sortSpending(spending []SpendingByCategory) {
+	sort.SliceStable(spending, func(i, j int) bool {
+		if cmp := spending[i].Amount.Cmp(spending[j].Amount); cmp != 0 {
+			return cmp > 0
+		}
+		return spending[i].Category < spending[j].Category
+	})
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// minimumPaymentPercent is the share of the statement balance that must be
+// paid by the due date, rounded up to the next minor unit
+const minimumPaymentPercent = 5
+
+type CreditCardService struct {
+	walletRepo         domain.WalletRepository
+	ledgerRepo         domain.LedgerRepository
+	transactionService *TransactionService
+}
+
+func NewCreditCardService(walletRepo domain.WalletRepository, ledgerRepo domain.LedgerRepository, transactionService *TransactionService) *CreditCardService {
+	return &CreditCardService{
+		walletRepo:         walletRepo,
+		ledgerRepo:         ledgerRepo,
+		transactionService: transactionService,
+	}
+}
+
+// BillingPeriod is the period [Start, End) of one statement. End is the day
+// after the closing day.
+type BillingPeriod struct {
+	Start   time.Time    `json:"start"`
+	End     time.Time    `json:"end"`
+	Charges domain.Money `json:"charges"` // Spending and other money out, positive
+	Credits domain.Money `json:"credits"` // Payments and refunds, positive
+}
+
+// BillingCycle is the last closed statement of a card together with the
+// cycle that is still open. Balances are amounts owed, positive while owing.
+type BillingCycle struct {
+	WalletID         int           `json:"wallet_id"`
+	Statement        BillingPeriod `json:"statement"`
+	PreviousBalance  domain.Money  `json:"previous_balance"`
+	StatementBalance domain.Money  `json:"statement_balance"`
+	DueDate          time.Time     `json:"due_date"`
+	DaysUntilDue     int           `json:"days_until_due"` // Negative once the due date has passed
+	PaidSinceClosing domain.Money  `json:"paid_since_closing"`
+	AmountDue        domain.Money  `json:"amount_due"`      // Statement balance not paid yet
+	MinimumPayment   domain.Money  `json:"minimum_payment"` // Still to pay to meet the minimum
+	Overdue          bool          `json:"overdue"`
+	CurrentCycle     BillingPeriod `json:"current_cycle"`
+	CurrentBalance   domain.Money  `json:"current_balance"`
+}
+
+// PayCardRequest pays a card from another wallet. Without an amount the
+// unpaid statement balance is paid, or the minimum payment when Minimum is set.
+type PayCardRequest struct {
+	FromWalletID int           `json:"from_wallet_id"`
+	Amount       *domain.Money `json:"amount,omitempty"`
+	Minimum      bool          `json:"minimum"`
+	Date         string        `json:"date"`
+	Description  string        `json:"description"`
+	// Paying from a wallet in another currency needs the amount received by
+	// the card or the exchange rate, as for any transfer between currencies
+	ToAmount     *domain.Money `json:"to_amount,omitempty"`
+	ExchangeRate *domain.Rate  `json:"exchange_rate,omitempty"`
+}
+
+// GetBillingCycle computes the billing cycle of a card as of today
+func (s *CreditCardService) GetBillingCycle(ctx context.Context, walletID int, userID int) (*BillingCycle, error) {
+	wallet, err := findOwnedWallet(ctx, s.walletRepo, walletID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.billingCycle(ctx, wallet, time.Now())
+}
+
+// PayCard books a transfer from a bank wallet to the card
+func (s *CreditCardService) PayCard(ctx context.Context, walletID int, userID int, req PayCardRequest) (*TransactionResult, error) {
+	card, err := findOwnedWallet(ctx, s.walletRepo, walletID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if card.AccountClass != domain.AccountClassLiability {
+		return nil, domain.Invalid("only liability wallets can be paid")
+	}
+	if req.FromWalletID == card.ID {
+		return nil, domain.Invalid("cannot pay a card from itself")
+	}
+	source, err := findOwnedWallet(ctx, s.walletRepo, req.FromWalletID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := req.Amount
+	if amount == nil {
+		if source.Currency != card.Currency {
+			return nil, domain.Invalid("amount is required when paying from a wallet in another currency")
+		}
+		due, err := s.amountDue(ctx, card, req.Minimum)
+		if err != nil {
+			return nil, err
+		}
+		if !due.IsPositive() {
+			return nil, domain.Invalid("nothing is due on this card")
+		}
+		amount = &due
+	}
+
+	description := req.Description
+	if description == "" {
+		description = "Pembayaran " + card.Name
+	}
+
+	return s.transactionService.CreateTransaction(ctx, userID, CreateTransactionRequest{
+		WalletID:     source.ID,
+		Type:         domain.TransactionTypeTransfer,
+		Amount:       *amount,
+		Description:  description,
+		Date:         req.Date,
+		ToWalletID:   &card.ID,
+		ToAmount:     req.ToAmount,
+		ExchangeRate: req.ExchangeRate,
+	})
+}
+
+// amountDue is the unpaid statement balance of the card, or the unpaid part
+// of the minimum payment. A card without a billing cycle owes its balance.
+func (s *CreditCardService) amountDue(ctx context.Context, card *domain.Wallet, minimum bool) (domain.Money, error) {
+	if card.StatementDay == nil || card.DueDay == nil {
+		if minimum {
+			return domain.Money{}, domain.Invalid("set statement_day and due_day to pay the minimum")
+		}
+		return maxMoney(card.Balance.Neg(), domain.NewMoney(0, card.Currency)), nil
+	}
+
+	cycle, err := s.billingCycle(ctx, card, time.Now())
+	if err != nil {
+		return domain.Money{}, err
+	}
+	if minimum {
+		return cycle.MinimumPayment, nil
+	}
+	return cycle.AmountDue, nil
+}
+
+func (s *CreditCardService) billingCycle(ctx context.Context, wallet *domain.Wallet, now time.Time) (*BillingCycle, error) {
+	if wallet.AccountClass != domain.AccountClassLiability || wallet.StatementDay == nil || wallet.DueDay == nil {
+		return nil, domain.Invalid("wallet has no billing cycle, set statement_day and due_day on a liability wallet")
+	}
+
+	today := dateOf(now)
+	closing, dueDate := lastStatementDates(today, *wallet.StatementDay, *wallet.DueDay)
+	statementStart := monthlyPeriodStart(closing.Year(), closing.Month()-1, *wallet.StatementDay).AddDate(0, 0, 1)
+	statementEnd := closing.AddDate(0, 0, 1)
+	nextClosing := monthlyPeriodStart(closing.Year(), closing.Month()+1, *wallet.StatementDay)
+
+	statement, err := s.billingPeriod(ctx, wallet, statementStart, statementEnd)
+	if err != nil {
+		return nil, err
+	}
+	currentCycle, err := s.billingPeriod(ctx, wallet, statementEnd, nextClosing.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	previousBalance, err := s.ledgerRepo.WalletBalanceAt(ctx, wallet.ID, statementStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch card balance: %w", err)
+	}
+	statementBalance, err := s.ledgerRepo.WalletBalanceAt(ctx, wallet.ID, statementEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch card balance: %w", err)
+	}
+
+	zero := domain.NewMoney(0, wallet.Currency)
+	owed := statementBalance.Neg().WithCurrency(wallet.Currency)
+	paid := currentCycle.Credits
+	amountDue := maxMoney(owed.Sub(paid), zero)
+	minimumDue := zero
+	if owed.IsPositive() {
+		minimumDue = domain.NewMoney((owed.Minor()*minimumPaymentPercent+99)/100, wallet.Currency)
+	}
+	minimumPayment := maxMoney(minimumDue.Sub(paid), zero)
+	if minimumPayment.Cmp(amountDue) > 0 {
+		minimumPayment = amountDue
+	}
+
+	daysUntilDue := int(dueDate.Sub(today).Hours() / 24)
+	return &BillingCycle{
+		WalletID:         wallet.ID,
+		Statement:        *statement,
+		PreviousBalance:  previousBalance.Neg().WithCurrency(wallet.Currency),
+		StatementBalance: owed,
+		DueDate:          dueDate,
+		DaysUntilDue:     daysUntilDue,
+		PaidSinceClosing: paid,
+		AmountDue:        amountDue,
+		MinimumPayment:   minimumPayment,
+		Overdue:          daysUntilDue < 0 && minimumPayment.IsPositive(),
+		CurrentCycle:     *currentCycle,
+		CurrentBalance:   wallet.Balance.Neg(),
+	}, nil
+}
+
+// billingPeriod totals the charges and credits of the card in [start, end)
+func (s *CreditCardService) billingPeriod(ctx context.Context, wallet *domain.Wallet, start, end time.Time) (*BillingPeriod, error) {
+	postings, err := s.ledgerRepo.FindWalletPostings(ctx, wallet.ID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch card postings: %w", err)
+	}
+
+	period := &BillingPeriod{
+		Start:   start,
+		End:     end,
+		Charges: domain.NewMoney(0, wallet.Currency),
+		Credits: domain.NewMoney(0, wallet.Currency),
+	}
+	for _, posting := range postings {
+		if posting.Amount.IsNegative() {
+			period.Charges = period.Charges.Sub(posting.Amount)
+		} else {
+			period.Credits = period.Credits.Add(posting.Amount)
+		}
+	}
+	return period, nil
+}
+
+// lastStatementDates returns the closing date of the last statement closed
+// before today and its due date. A statement closes at the end of its
+// closing day and is due on the first due day after it.
+func lastStatementDates(today time.Time, statementDay, dueDay int) (time.Time, time.Time) {
+	closing := monthlyPeriodStart(today.Year(), today.Month(), statementDay)
+	if !closing.Before(today) {
+		closing = monthlyPeriodStart(today.Year(), today.Month()-1, statementDay)
+	}
+
+	dueDate := monthlyPeriodStart(closing.Year(), closing.Month(), dueDay)
+	if !dueDate.After(closing) {
+		dueDate = monthlyPeriodStart(closing.Year(), closing.Month()+1, dueDay)
+	}
+	return closing, dueDate
+}
+
+func maxMoney(a, b domain.Money) domain.Money {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
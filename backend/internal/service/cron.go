@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed five-field cron expression
+// ("minute hour day-of-month month day-of-week"). Recurring transactions
+// happen at most once per day, so only the day-level fields are evaluated;
+// the minute and hour fields are validated but otherwise ignored.
+type cronSchedule struct {
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	domAny      bool
+	dowAny      bool
+}
+
+var cronMacros = map[string]string{
+	"@daily":    "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+func parseCron(expression string) (*cronSchedule, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := cronMacros[expression]; ok {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields")
+	}
+
+	if _, err := parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid cron minute field: %w", err)
+	}
+	if _, err := parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid cron hour field: %w", err)
+	}
+
+	daysOfMonth, err := parseCronField(fields[2], 1, 31)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron day-of-month field: %w", err)
+	}
+	months, err := parseCronField(fields[3], 1, 12)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron month field: %w", err)
+	}
+	daysOfWeek, err := parseCronField(fields[4], 0, 7)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron day-of-week field: %w", err)
+	}
+	// Both 0 and 7 mean Sunday
+	if daysOfWeek[7] {
+		daysOfWeek[0] = true
+	}
+
+	return &cronSchedule{
+		daysOfMonth: daysOfMonth,
+		months:      months,
+		daysOfWeek:  daysOfWeek,
+		domAny:      strings.HasPrefix(fields[2], "*"),
+		dowAny:      strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField expands a field such as "*", "*/2", "1-5", "1,15" or "10-20/5"
+// into the set of values it matches
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := make(map[int]bool)
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		start, end := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+			start, err = strconv.Atoi(from)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", from)
+			}
+			end = start
+			if isRange {
+				end, err = strconv.Atoi(to)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value %q", to)
+				}
+			} else if hasStep {
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("value out of range %d-%d in %q", min, max, part)
+		}
+
+		for value := start; value <= end; value += step {
+			values[value] = true
+		}
+	}
+
+	return values, nil
+}
+
+// matches reports whether the schedule fires on the given day. As in standard
+// cron, when both day-of-month and day-of-week are restricted either may match.
+// A field starting with "*", such as "*/10", is not restricted, but its step
+// still has to match.
+func (c *cronSchedule) matches(date time.Time) bool {
+	if !c.months[int(date.Month())] {
+		return false
+	}
+
+	domMatch := c.daysOfMonth[date.Day()]
+	dowMatch := c.daysOfWeek[int(date.Weekday())]
+
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
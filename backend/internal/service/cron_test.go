@@ -0,0 +1,162 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		field    string
+		min, max int
+		want     []int
+		wantErr  bool
+	}{
+		{field: "*", min: 1, max: 5, want: []int{1, 2, 3, 4, 5}},
+		{field: "*/2", min: 0, max: 6, want: []int{0, 2, 4, 6}},
+		{field: "3", min: 1, max: 31, want: []int{3}},
+		{field: "1-5", min: 0, max: 7, want: []int{1, 2, 3, 4, 5}},
+		{field: "1,15", min: 1, max: 31, want: []int{1, 15}},
+		{field: "10-20/5", min: 1, max: 31, want: []int{10, 15, 20}},
+		{field: "5/10", min: 0, max: 30, want: []int{5, 15, 25}},
+		{field: "1-3,7,*/30", min: 0, max: 59, want: []int{0, 1, 2, 3, 7, 30}},
+
+		{field: "0", min: 1, max: 31, wantErr: true},
+		{field: "32", min: 1, max: 31, wantErr: true},
+		{field: "25-35", min: 1, max: 31, wantErr: true},
+		{field: "5-1", min: 0, max: 7, wantErr: true},
+		{field: "*/0", min: 0, max: 59, wantErr: true},
+		{field: "*/x", min: 0, max: 59, wantErr: true},
+		{field: "-1", min: 0, max: 59, wantErr: true},
+		{field: "1-b", min: 0, max: 59, wantErr: true},
+		{field: "mon", min: 0, max: 7, wantErr: true},
+		{field: "1,,2", min: 0, max: 7, wantErr: true},
+		{field: "", min: 0, max: 7, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			values, err := parseCronField(tt.field, tt.min, tt.max)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", sortedKeys(values))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := sortedKeys(values); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expression string
+		wantErr    bool
+	}{
+		{expression: "0 0 1 * *"},
+		{expression: " 30 8 1,15 * 1-5 "},
+		{expression: "@monthly"},
+		{expression: "@weekly"},
+		{expression: "59 23 31 12 7"},
+
+		{expression: "", wantErr: true},
+		{expression: "0 0 * *", wantErr: true},
+		{expression: "0 0 * * * *", wantErr: true},
+		{expression: "@hourly", wantErr: true},
+		{expression: "60 0 * * *", wantErr: true},
+		{expression: "0 24 * * *", wantErr: true},
+		{expression: "0 0 0 * *", wantErr: true},
+		{expression: "0 0 32 * *", wantErr: true},
+		{expression: "0 0 * 0 *", wantErr: true},
+		{expression: "0 0 * 13 *", wantErr: true},
+		{expression: "0 0 * * 8", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := parseCron(tt.expression)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCronMatches(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		date       string
+		want       bool
+	}{
+		{"every day", "0 0 * * *", "2024-09-10", true},
+		{"day of month", "0 0 1 * *", "2024-09-01", true},
+		{"other day of month", "0 0 1 * *", "2024-09-02", false},
+		{"step on day of month", "0 0 */10 * *", "2024-09-11", true},
+		{"off step on day of month", "0 0 */10 * *", "2024-09-10", false},
+		{"month", "0 0 * 2 *", "2024-02-10", true},
+		{"other month", "0 0 * 2 *", "2024-03-10", false},
+		{"weekday range", "0 0 * * 1-5", "2024-09-02", true},
+		{"weekend outside range", "0 0 * * 1-5", "2024-09-01", false},
+		{"sunday as 0", "0 0 * * 0", "2024-09-01", true},
+		{"sunday as 7", "0 0 * * 7", "2024-09-01", true},
+		{"weekly macro", "@weekly", "2024-09-08", true},
+
+		// With both day fields restricted, either one matching is enough
+		{"day of month and day of week", "0 0 13 * 5", "2024-09-13", true},
+		{"day of week only", "0 0 13 * 5", "2024-09-06", true},
+		{"day of month only", "0 0 13 * 5", "2024-10-13", true},
+		{"neither day field", "0 0 13 * 5", "2024-09-10", false},
+		{"first week or monday, by day of month", "0 0 1-7 * 1", "2024-09-03", true},
+		{"first week or monday, by day of week", "0 0 1-7 * 1", "2024-09-09", true},
+		{"first week or monday, neither", "0 0 1-7 * 1", "2024-09-10", false},
+		{"either day field, wrong month", "0 0 13 3 5", "2024-09-13", false},
+
+		// A starred field with a step is not restricted, so both fields must match
+		{"stepped day of month on the day of week", "0 0 */2 * 1", "2024-09-09", true},
+		{"stepped day of month off the step", "0 0 */2 * 1", "2024-09-02", false},
+		{"stepped day of month off the day of week", "0 0 */2 * 1", "2024-09-03", false},
+		{"stepped day of week", "0 0 * * */2", "2024-09-03", true},
+		{"stepped day of week off the step", "0 0 * * */2", "2024-09-02", false},
+
+		{"31st in a short month", "0 0 31 * *", "2024-04-30", false},
+		{"29 February", "0 0 29 2 *", "2024-02-29", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCron(tt.expression)
+			if err != nil {
+				t.Fatalf("parse %q: %v", tt.expression, err)
+			}
+			if got := schedule.matches(mustDate(t, tt.date)); got != tt.want {
+				t.Fatalf("%q on %s: got %v, want %v", tt.expression, tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func sortedKeys(values map[int]bool) []int {
+	keys := make([]int, 0, len(values))
+	for value := range values {
+		keys = append(keys, value)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func mustDate(t *testing.T, value string) time.Time {
+	t.Helper()
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatalf("parse date %q: %v", value, err)
+	}
+	return date
+}
@@ -1,103 +1,205 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"go-moneyku/internal/domain"
 )
 
 type DashboardService struct {
-	walletRepo      domain.WalletRepository
-	transactionRepo domain.TransactionRepository
+	walletRepo          domain.WalletRepository
+	transactionRepo     domain.TransactionRepository
+	categoryRepo        domain.CategoryRepository
+	exchangeRateService *ExchangeRateService
+	walletTypeService   *WalletTypeService
 }
 
-func NewDashboardService(walletRepo domain.WalletRepository, transactionRepo domain.TransactionRepository) *DashboardService {
+func NewDashboardService(walletRepo domain.WalletRepository, transactionRepo domain.TransactionRepository, categoryRepo domain.CategoryRepository, exchangeRateService *ExchangeRateService, walletTypeService *WalletTypeService) *DashboardService {
 	return &DashboardService{
-		walletRepo:      walletRepo,
-		transactionRepo: transactionRepo,
+		walletRepo:          walletRepo,
+		transactionRepo:     transactionRepo,
+		categoryRepo:        categoryRepo,
+		exchangeRateService: exchangeRateService,
+		walletTypeService:   walletTypeService,
 	}
 }
 
+// DashboardSummary holds totals in the user's base currency. Balances are
+// converted at today's rate, income and expenses at the rate of their date.
+// Wallets whose type is left out of net worth are not part of the balance totals.
 type DashboardSummary struct {
-	TotalBalance float64              `json:"total_balance"`
-	TotalIncome  float64              `json:"total_income"`
-	TotalExpense float64              `json:"total_expense"`
-	WalletCount  int                  `json:"wallet_count"`
-	Transactions []domain.Transaction `json:"recent_transactions"`
-	Wallets      []domain.Wallet      `json:"wallets"`
+	BaseCurrency     string               `json:"base_currency"`
+	TotalBalance     domain.Money         `json:"total_balance"` // Net worth: assets less liabilities
+	TotalAssets      domain.Money         `json:"total_assets"`
+	TotalLiabilities domain.Money         `json:"total_liabilities"` // Owed on liability wallets, positive
+	Groups           []NetWorthGroup      `json:"groups"`
+	TotalIncome      domain.Money         `json:"total_income"`
+	TotalExpense     domain.Money         `json:"total_expense"`
+	WalletCount      int                  `json:"wallet_count"`
+	Transactions     []domain.Transaction `json:"recent_transactions"`
+	Wallets          []domain.Wallet      `json:"wallets"`
+	// MissingRates lists the currency pairs without a rate; amounts in those
+	// currencies are left out of the totals
+	MissingRates []string `json:"missing_rates,omitempty"`
 }
 
+// NetWorthGroup totals the wallets of one display group, such as "Kas & Bank".
+// Total is what the group adds to net worth, negative for groups of debts.
+type NetWorthGroup struct {
+	Group       string       `json:"group"`
+	Total       domain.Money `json:"total"`
+	WalletCount int          `json:"wallet_count"`
+}
+
+// SpendingByCategory is the spending of a top-level category, including its
+// subcategories, which are broken down in Subcategories
 type SpendingByCategory struct {
-	Category string  `json:"category"`
-	Amount   float64 `json:"amount"`
+	CategoryID    *int                 `json:"category_id,omitempty"`
+	Category      string               `json:"category"`
+	Icon          string               `json:"icon,omitempty"`
+	Color         string               `json:"color,omitempty"`
+	Amount        domain.Money         `json:"amount"`
+	Subcategories []SpendingByCategory `json:"subcategories,omitempty"`
 }
 
-func (s *DashboardService) GetSummary(userID int) (*DashboardSummary, error) {
+func (s *DashboardService) GetSummary(ctx context.Context, userID int) (*DashboardSummary, error) {
 	// Get all wallets
-	wallets, err := s.walletRepo.FindByUserID(userID)
+	wallets, err := s.walletRepo.FindByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch wallets: %w", err)
 	}
 
-	// Calculate total balance (only for 'tabungan' type)
-	var totalBalance float64
-	for _, wallet := range wallets {
-		if wallet.Type == "tabungan" || wallet.Type == "Tabungan" {
-			totalBalance += wallet.Balance
+	converter, err := s.exchangeRateService.converter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := s.walletTypeService.registry(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Net worth counts what is held in asset wallets less what is owed on
+	// liability wallets, whose balances are negative while owing
+	totalAssets := domain.NewMoney(0, converter.base)
+	totalLiabilities := domain.NewMoney(0, converter.base)
+	groups := make(map[string]*NetWorthGroup)
+	today := time.Now()
+	for i := range wallets {
+		wallet := &wallets[i]
+		walletType := registry.lookup(wallet)
+		if !walletType.IncludeInNetWorth {
+			continue
 		}
+
+		balance, err := converter.convert(ctx, wallet.Balance, wallet.Currency, today)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert wallet balance: %w", err)
+		}
+		if registry.accountClass(wallet) == domain.AccountClassLiability {
+			totalLiabilities = totalLiabilities.Sub(balance)
+		} else {
+			totalAssets = totalAssets.Add(balance)
+		}
+
+		group, ok := groups[walletType.Group]
+		if !ok {
+			group = &NetWorthGroup{Group: walletType.Group, Total: domain.NewMoney(0, converter.base)}
+			groups[walletType.Group] = group
+		}
+		group.Total = group.Total.Add(balance)
+		group.WalletCount++
 	}
 
-	// Get transaction stats
-	stats, err := s.transactionRepo.GetStatsByUserID(userID)
+	// Get income and expenses per day, to convert each at its own rate
+	dailyTotals, err := s.transactionRepo.GetDailyTotalsByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch transaction stats: %w", err)
 	}
 
+	totalIncome := domain.NewMoney(0, converter.base)
+	totalExpense := domain.NewMoney(0, converter.base)
+	for _, daily := range dailyTotals {
+		amount, err := converter.convert(ctx, daily.Amount, daily.Currency, daily.Date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert transaction totals: %w", err)
+		}
+		switch daily.Type {
+		case domain.TransactionTypeIncome:
+			totalIncome = totalIncome.Add(amount)
+		case domain.TransactionTypeExpense:
+			totalExpense = totalExpense.Add(amount)
+		}
+	}
+
 	// Get recent transactions
-	recentTransactions, err := s.transactionRepo.GetRecentByUserID(userID, 10)
+	recentTransactions, err := s.transactionRepo.GetRecentByUserID(ctx, userID, 10)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch recent transactions: %w", err)
 	}
 
 	summary := &DashboardSummary{
-		TotalBalance: totalBalance,
-		TotalIncome:  stats.TotalIncome,
-		TotalExpense: stats.TotalExpense,
-		WalletCount:  len(wallets),
-		Transactions: recentTransactions,
-		Wallets:      wallets,
+		BaseCurrency:     converter.base,
+		TotalBalance:     totalAssets.Sub(totalLiabilities),
+		TotalAssets:      totalAssets,
+		TotalLiabilities: totalLiabilities,
+		Groups:           sortedNetWorthGroups(groups),
+		TotalIncome:      totalIncome,
+		TotalExpense:     totalExpense,
+		WalletCount:      len(wallets),
+		Transactions:     recentTransactions,
+		Wallets:          wallets,
+		MissingRates:     converter.missingRates(),
 	}
 
 	return summary, nil
 }
 
-func (s *DashboardService) GetSpendingByCategory(userID int) ([]SpendingByCategory, error) {
+// sortedNetWorthGroups orders the groups by name, with "Lainnya" last
+func sortedNetWorthGroups(groups map[string]*NetWorthGroup) []NetWorthGroup {
+	sorted := make([]NetWorthGroup, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, *group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if (sorted[i].Group == otherWalletGroup) != (sorted[j].Group == otherWalletGroup) {
+			return sorted[j].Group == otherWalletGroup
+		}
+		return sorted[i].Group < sorted[j].Group
+	})
+	return sorted
+}
+
+// GetSpendingByCategory totals spending in the user's base currency, each
+// transaction converted at the rate of its date
+func (s *DashboardService) GetSpendingByCategory(ctx context.Context, userID int) ([]SpendingByCategory, error) {
 	// Get all transactions
-	transactions, err := s.transactionRepo.FindByUserID(userID)
+	transactions, err := s.transactionRepo.FindByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
 	}
 
-	// Group by category
-	categoryMap := make(map[string]float64)
-	for _, transaction := range transactions {
-		if transaction.Type == domain.TransactionTypeExpense {
-			category := transaction.Category
-			if category == "" {
-				category = "Uncategorized"
-			}
-			categoryMap[category] += transaction.Amount
-		}
+	wallets, err := s.walletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wallets: %w", err)
 	}
 
-	// Convert to slice
-	var result []SpendingByCategory
-	for category, amount := range categoryMap {
-		result = append(result, SpendingByCategory{
-			Category: category,
-			Amount:   amount,
-		})
+	converter, err := s.exchangeRateService.converter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err = converter.convertTransactions(ctx, transactions, walletCurrencies(wallets))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert transactions: %w", err)
+	}
+
+	categories, err := s.categoryRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
 	}
 
-	return result, nil
+	return rollUpSpending(transactions, categories), nil
 }
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// defaultBaseCurrency is the base currency of new users
+const defaultBaseCurrency = "IDR"
+
+type ExchangeRateService struct {
+	rateRepo domain.ExchangeRateRepository
+	userRepo domain.UserRepository
+	provider domain.RateProvider // Nil when no provider is configured
+}
+
+func NewExchangeRateService(rateRepo domain.ExchangeRateRepository, userRepo domain.UserRepository, provider domain.RateProvider) *ExchangeRateService {
+	return &ExchangeRateService{
+		rateRepo: rateRepo,
+		userRepo: userRepo,
+		provider: provider,
+	}
+}
+
+type ExchangeRateRequest struct {
+	Base  string      `json:"base_currency"`
+	Quote string      `json:"quote_currency"`
+	Date  string      `json:"date"` // YYYY-MM-DD, defaults to today
+	Rate  domain.Rate `json:"rate"`
+}
+
+// CreateRate stores a manual rate of the user. A rate for the same pair and
+// date replaces the previous one.
+func (s *ExchangeRateService) CreateRate(ctx context.Context, userID int, req ExchangeRateRequest) (*domain.ExchangeRate, error) {
+	base, err := normalizeCurrency(req.Base)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := normalizeCurrency(req.Quote)
+	if err != nil {
+		return nil, err
+	}
+	if base == quote {
+		return nil, domain.Invalid("base and quote currency must differ")
+	}
+	if !req.Rate.IsPositive() {
+		return nil, domain.Invalid("rate must be greater than zero")
+	}
+
+	date := dateOf(time.Now())
+	if req.Date != "" {
+		date, err = time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return nil, domain.Invalid("invalid date format, use YYYY-MM-DD")
+		}
+	}
+
+	rate := &domain.ExchangeRate{
+		UserID: &userID,
+		Base:   base,
+		Quote:  quote,
+		Date:   date,
+		Rate:   req.Rate,
+		Source: "manual",
+	}
+	if err := s.rateRepo.Upsert(ctx, rate); err != nil {
+		return nil, fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+
+	return rate, nil
+}
+
+// GetRates lists the user's rates and the shared provider rates, optionally
+// for one base or quote currency
+func (s *ExchangeRateService) GetRates(ctx context.Context, userID int, base, quote string) ([]domain.ExchangeRate, error) {
+	rates, err := s.rateRepo.FindByUserID(ctx, userID, strings.ToUpper(strings.TrimSpace(base)), strings.ToUpper(strings.TrimSpace(quote)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+// DeleteRate deletes one of the user's own rates; shared rates are managed by
+// the provider
+func (s *ExchangeRateService) DeleteRate(ctx context.Context, rateID int, userID int) error {
+	rate, err := s.rateRepo.FindByID(ctx, rateID)
+	if err != nil {
+		return err
+	}
+	if rate.UserID == nil || *rate.UserID != userID {
+		return domain.Forbidden("exchange rate")
+	}
+
+	if err := s.rateRepo.Delete(ctx, rateID); err != nil {
+		return fmt.Errorf("failed to delete exchange rate: %w", err)
+	}
+	return nil
+}
+
+// RunDue copies the rates of the configured provider into the rate store.
+// It implements ScheduledJob.
+func (s *ExchangeRateService) RunDue(ctx context.Context, now time.Time) {
+	if s.provider == nil {
+		return
+	}
+
+	rates, err := s.provider.FetchRates(ctx)
+	if err != nil {
+		log.Printf("Exchange rates: failed to fetch rates from %s: %v", s.provider.Name(), err)
+		return
+	}
+
+	saved := 0
+	for i := range rates {
+		rate := rates[i]
+		rate.UserID = nil
+		rate.Source = s.provider.Name()
+		if err := s.rateRepo.Upsert(ctx, &rate); err != nil {
+			log.Printf("Exchange rates: failed to save %s/%s on %s: %v", rate.Base, rate.Quote, rate.Date.Format("2006-01-02"), err)
+			continue
+		}
+		saved++
+	}
+	log.Printf("Exchange rates: synced %d rates from %s", saved, s.provider.Name())
+}
+
+// converter returns a converter to the user's base currency
+func (s *ExchangeRateService) converter(ctx context.Context, userID int) (*currencyConverter, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	base := user.BaseCurrency
+	if base == "" {
+		base = defaultBaseCurrency
+	}
+
+	return &currencyConverter{
+		rateRepo: s.rateRepo,
+		userID:   userID,
+		base:     base,
+		rates:    make(map[rateKey]domain.Rate),
+		missing:  make(map[string]bool),
+	}, nil
+}
+
+type rateKey struct {
+	currency string
+	date     string
+}
+
+// currencyConverter converts amounts to one user's base currency at the rate
+// of a given day, caching the rates it looks up
+type currencyConverter struct {
+	rateRepo domain.ExchangeRateRepository
+	userID   int
+	base     string
+	rates    map[rateKey]domain.Rate // The zero Rate marks a missing rate
+	missing  map[string]bool
+}
+
+// convert converts an amount in currency to the base currency at the rate of
+// date. An amount without a usable rate converts to zero and its pair is
+// reported by missingRates, so that totals can still be shown.
+func (c *currencyConverter) convert(ctx context.Context, amount domain.Money, currency string, date time.Time) (domain.Money, error) {
+	if currency == "" || currency == c.base {
+		return amount.WithCurrency(c.base), nil
+	}
+
+	rate, err := c.rate(ctx, currency, date)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	if !rate.IsPositive() {
+		c.missing[currency+"/"+c.base] = true
+		return domain.NewMoney(0, c.base), nil
+	}
+
+	return amount.Convert(rate, c.base), nil
+}
+
+// canConvert reports whether amounts in currency can be converted at the rate
+// of date
+func (c *currencyConverter) canConvert(ctx context.Context, currency string, date time.Time) (bool, error) {
+	if currency == "" || currency == c.base {
+		return true, nil
+	}
+	rate, err := c.rate(ctx, currency, date)
+	return rate.IsPositive(), err
+}
+
+// rate finds the rate from currency to the base currency, falling back to the
+// inverse of the rate in the other direction
+func (c *currencyConverter) rate(ctx context.Context, currency string, date time.Time) (domain.Rate, error) {
+	key := rateKey{currency: currency, date: date.Format("2006-01-02")}
+	if rate, ok := c.rates[key]; ok {
+		return rate, nil
+	}
+
+	day := dateOf(date)
+	var rate domain.Rate
+	direct, err := c.rateRepo.FindRate(ctx, c.userID, currency, c.base, day)
+	if err != nil {
+		return domain.Rate{}, err
+	}
+	if direct != nil {
+		rate = direct.Rate
+	} else {
+		inverse, err := c.rateRepo.FindRate(ctx, c.userID, c.base, currency, day)
+		if err != nil {
+			return domain.Rate{}, err
+		}
+		if inverse != nil {
+			rate = inverse.Rate.Inverse()
+		}
+	}
+
+	c.rates[key] = rate
+	return rate, nil
+}
+
+// convertTransactions returns copies of the transactions with their amounts in
+// the base currency, given the currency of each wallet
+func (c *currencyConverter) convertTransactions(ctx context.Context, transactions []domain.Transaction, walletCurrencies map[int]string) ([]domain.Transaction, error) {
+	converted := make([]domain.Transaction, len(transactions))
+	for i, transaction := range transactions {
+		amount, err := c.convert(ctx, transaction.Amount, walletCurrencies[transaction.WalletID], transaction.Date)
+		if err != nil {
+			return nil, err
+		}
+		transaction.Amount = amount
+		converted[i] = transaction
+	}
+	return converted, nil
+}
+
+// missingRates lists the currency pairs, such as "USD/IDR", that had no rate
+func (c *currencyConverter) missingRates() []string {
+	pairs := make([]string, 0, len(c.missing))
+	for pair := range c.missing {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// walletCurrencies maps wallet IDs to their currencies
+func walletCurrencies(wallets []domain.Wallet) map[int]string {
+	currencies := make(map[int]string, len(wallets))
+	for _, wallet := range wallets {
+		currencies[wallet.ID] = wallet.Currency
+	}
+	return currencies
+}
+
+// normalizeCurrency validates a three letter ISO 4217 code such as "IDR"
+func normalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", domain.Invalid("invalid currency %q, use a three letter code such as IDR or USD", code)
+	}
+	return code, nil
+}
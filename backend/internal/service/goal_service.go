@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// goalHistoryMonths is how far back savings are averaged to project a goal
+const goalHistoryMonths = 6
+
+// daysPerMonth is the average length of a month, used to convert between
+// days and months in projections
+const daysPerMonth = 30.44
+
+type GoalService struct {
+	goalRepo   domain.GoalRepository
+	walletRepo domain.WalletRepository
+	ledgerRepo domain.LedgerRepository
+	uow        domain.UnitOfWork
+}
+
+func NewGoalService(goalRepo domain.GoalRepository, walletRepo domain.WalletRepository, ledgerRepo domain.LedgerRepository, uow domain.UnitOfWork) *GoalService {
+	return &GoalService{
+		goalRepo:   goalRepo,
+		walletRepo: walletRepo,
+		ledgerRepo: ledgerRepo,
+		uow:        uow,
+	}
+}
+
+type GoalRequest struct {
+	Name         string       `json:"name"`
+	TargetAmount domain.Money `json:"target_amount"`
+	Deadline     string       `json:"deadline,omitempty"`
+	WalletIDs    []int        `json:"wallet_ids"`
+}
+
+type GoalContributionRequest struct {
+	Amount domain.Money `json:"amount"`
+	Date   string       `json:"date"`
+	Note   string       `json:"note"`
+}
+
+// GoalProgress is a goal together with how much has been saved and a
+// projection of when the target is reached
+type GoalProgress struct {
+	domain.Goal
+	WalletBalance   domain.Money `json:"wallet_balance"`
+	Contributed     domain.Money `json:"contributed"`
+	Saved           domain.Money `json:"saved"`
+	Remaining       domain.Money `json:"remaining"`
+	PercentComplete float64      `json:"percent_complete"`
+	Completed       bool         `json:"completed"`
+	// MonthlyRequired is what has to be saved each month to reach the target
+	// by the deadline; it is only set for goals with a deadline
+	MonthlyRequired *domain.Money `json:"monthly_required,omitempty"`
+	// MonthlyAverage is the average saved per month over recent history
+	MonthlyAverage domain.Money `json:"monthly_average"`
+	// ProjectedCompletion is when the target is reached at the average rate;
+	// it is not set when nothing is being saved
+	ProjectedCompletion *time.Time `json:"projected_completion,omitempty"`
+	OnTrack             *bool      `json:"on_track,omitempty"`
+}
+
+func (s *GoalService) CreateGoal(ctx context.Context, userID int, req GoalRequest) (*GoalProgress, error) {
+	goal := &domain.Goal{UserID: userID}
+	if err := s.applyRequest(ctx, goal, req); err != nil {
+		return nil, err
+	}
+
+	err := s.uow.Do(ctx, func(repos domain.Repositories) error {
+		if err := repos.Goals.Create(ctx, goal); err != nil {
+			return fmt.Errorf("failed to create goal: %w", err)
+		}
+		return repos.Goals.SetWallets(ctx, goal.ID, goal.WalletIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.progress(ctx, goal, time.Now())
+}
+
+func (s *GoalService) GetUserGoals(ctx context.Context, userID int) ([]GoalProgress, error) {
+	goals, err := s.goalRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch goals: %w", err)
+	}
+
+	now := time.Now()
+	result := make([]GoalProgress, 0, len(goals))
+	for i := range goals {
+		progress, err := s.progress(ctx, &goals[i], now)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *progress)
+	}
+
+	return result, nil
+}
+
+func (s *GoalService) GetGoal(ctx context.Context, goalID int, userID int) (*GoalProgress, error) {
+	goal, err := s.findOwnedGoal(ctx, goalID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.progress(ctx, goal, time.Now())
+}
+
+func (s *GoalService) UpdateGoal(ctx context.Context, goalID int, userID int, req GoalRequest) (*GoalProgress, error) {
+	goal, err := s.findOwnedGoal(ctx, goalID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRequest(ctx, goal, req); err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(repos domain.Repositories) error {
+		if err := repos.Goals.Update(ctx, goal); err != nil {
+			return fmt.Errorf("failed to update goal: %w", err)
+		}
+		return repos.Goals.SetWallets(ctx, goal.ID, goal.WalletIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.progress(ctx, goal, time.Now())
+}
+
+func (s *GoalService) DeleteGoal(ctx context.Context, goalID int, userID int) error {
+	if _, err := s.findOwnedGoal(ctx, goalID, userID); err != nil {
+		return err
+	}
+
+	// Linked wallets are kept; only the link and the contributions go
+	if err := s.goalRepo.Delete(ctx, goalID); err != nil {
+		return fmt.Errorf("failed to delete goal: %w", err)
+	}
+
+	return nil
+}
+
+func (s *GoalService) AddContribution(ctx context.Context, goalID int, userID int, req GoalContributionRequest) (*domain.GoalContribution, error) {
+	if _, err := s.findOwnedGoal(ctx, goalID, userID); err != nil {
+		return nil, err
+	}
+
+	if req.Amount.IsZero() {
+		return nil, domain.Invalid("amount must not be zero")
+	}
+
+	date := dateOf(time.Now())
+	if req.Date != "" {
+		var err error
+		date, err = time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return nil, domain.Invalid("invalid date format (use YYYY-MM-DD)")
+		}
+	}
+
+	contribution := &domain.GoalContribution{
+		GoalID: goalID,
+		Amount: req.Amount,
+		Date:   date,
+		Note:   req.Note,
+	}
+
+	if err := s.goalRepo.AddContribution(ctx, contribution); err != nil {
+		return nil, fmt.Errorf("failed to add contribution: %w", err)
+	}
+
+	return contribution, nil
+}
+
+func (s *GoalService) GetContributions(ctx context.Context, goalID int, userID int) ([]domain.GoalContribution, error) {
+	if _, err := s.findOwnedGoal(ctx, goalID, userID); err != nil {
+		return nil, err
+	}
+
+	contributions, err := s.goalRepo.FindContributions(ctx, goalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contributions: %w", err)
+	}
+	return contributions, nil
+}
+
+func (s *GoalService) DeleteContribution(ctx context.Context, goalID int, contributionID int, userID int) error {
+	if _, err := s.findOwnedGoal(ctx, goalID, userID); err != nil {
+		return err
+	}
+
+	contribution, err := s.goalRepo.FindContributionByID(ctx, contributionID)
+	if err != nil {
+		return err
+	}
+	if contribution.GoalID != goalID {
+		return domain.Invalid("contribution does not belong to goal")
+	}
+
+	if err := s.goalRepo.DeleteContribution(ctx, contributionID); err != nil {
+		return fmt.Errorf("failed to delete contribution: %w", err)
+	}
+
+	return nil
+}
+
+func (s *GoalService) findOwnedGoal(ctx context.Context, goalID int, userID int) (*domain.Goal, error) {
+	goal, err := s.goalRepo.FindByID(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.UserID != userID {
+		return nil, domain.Forbidden("goal")
+	}
+	return goal, nil
+}
+
+// applyRequest validates the request and copies it onto the goal
+func (s *GoalService) applyRequest(ctx context.Context, goal *domain.Goal, req GoalRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return domain.Invalid("goal name is required")
+	}
+	if !req.TargetAmount.IsPositive() {
+		return domain.Invalid("target amount must be greater than zero")
+	}
+
+	var deadline *time.Time
+	if req.Deadline != "" {
+		parsed, err := time.Parse("2006-01-02", req.Deadline)
+		if err != nil {
+			return domain.Invalid("invalid deadline format (use YYYY-MM-DD)")
+		}
+		deadline = &parsed
+	}
+
+	// A wallet saves toward a single goal, so its balance is never counted twice
+	walletIDs := make([]int, 0, len(req.WalletIDs))
+	seen := make(map[int]bool)
+	for _, walletID := range req.WalletIDs {
+		if seen[walletID] {
+			continue
+		}
+		seen[walletID] = true
+
+		if _, err := findOwnedWallet(ctx, s.walletRepo, walletID, goal.UserID); err != nil {
+			return err
+		}
+		linked, err := s.goalRepo.FindByWalletID(ctx, walletID)
+		if err == nil && linked.ID != goal.ID {
+			return domain.Conflict("wallet_already_linked", "wallet %d is already linked to goal %q", walletID, linked.Name)
+		}
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		walletIDs = append(walletIDs, walletID)
+	}
+
+	goal.Name = name
+	goal.TargetAmount = req.TargetAmount
+	goal.Deadline = deadline
+	goal.WalletIDs = walletIDs
+
+	return nil
+}
+
+// progress computes how far the goal is and projects its completion from the
+// average monthly savings of the last goalHistoryMonths months
+func (s *GoalService) progress(ctx context.Context, goal *domain.Goal, now time.Time) (*GoalProgress, error) {
+	today := dateOf(now)
+	historyStart := today.AddDate(0, -goalHistoryMonths, 0)
+
+	// History only counts from when saving for the goal could have started
+	earliest := dateOf(goal.CreatedAt)
+
+	var walletBalance domain.Money
+	for _, walletID := range goal.WalletIDs {
+		wallet, err := s.walletRepo.FindByID(ctx, walletID)
+		if err != nil {
+			return nil, err
+		}
+		walletBalance = walletBalance.Add(wallet.Balance)
+		if created := dateOf(wallet.CreatedAt); created.Before(earliest) {
+			earliest = created
+		}
+	}
+
+	contributions, err := s.goalRepo.FindContributions(ctx, goal.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contributions: %w", err)
+	}
+
+	var contributed domain.Money
+	for _, contribution := range contributions {
+		contributed = contributed.Add(contribution.Amount)
+		if date := dateOf(contribution.Date); date.Before(earliest) {
+			earliest = date
+		}
+	}
+
+	if earliest.After(historyStart) {
+		historyStart = earliest
+	}
+	historyEnd := today.AddDate(0, 0, 1)
+
+	var recentSavings domain.Money
+	if len(goal.WalletIDs) > 0 {
+		recentSavings, err = s.ledgerRepo.SumWalletFlows(ctx, goal.WalletIDs, historyStart, historyEnd)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, contribution := range contributions {
+		if date := dateOf(contribution.Date); !date.Before(historyStart) && date.Before(historyEnd) {
+			recentSavings = recentSavings.Add(contribution.Amount)
+		}
+	}
+
+	historyMonths := math.Max(historyEnd.Sub(historyStart).Hours()/24/daysPerMonth, 1)
+	monthlyAverage := domain.NewMoney(int64(math.Round(float64(recentSavings.Minor())/historyMonths)), recentSavings.Currency())
+
+	saved := walletBalance.Add(contributed)
+	remaining := goal.TargetAmount.Sub(saved)
+	if remaining.IsNegative() {
+		remaining = domain.Money{}
+	}
+
+	progress := &GoalProgress{
+		Goal:            *goal,
+		WalletBalance:   walletBalance,
+		Contributed:     contributed,
+		Saved:           saved,
+		Remaining:       remaining,
+		PercentComplete: percentUsed(saved, goal.TargetAmount),
+		Completed:       remaining.IsZero(),
+		MonthlyAverage:  monthlyAverage,
+	}
+	if progress.Completed {
+		return progress, nil
+	}
+
+	if goal.Deadline != nil {
+		// At least one month is left, so a passed deadline asks for the rest now
+		monthsLeft := math.Max(math.Ceil(dateOf(*goal.Deadline).Sub(today).Hours()/24/daysPerMonth), 1)
+		required := domain.NewMoney(int64(math.Ceil(float64(remaining.Minor())/monthsLeft)), remaining.Currency())
+		progress.MonthlyRequired = &required
+
+		onTrack := monthlyAverage.Cmp(required) >= 0
+		progress.OnTrack = &onTrack
+	}
+
+	if monthlyAverage.IsPositive() {
+		months := float64(remaining.Minor()) / float64(monthlyAverage.Minor())
+		projected := today.AddDate(0, 0, int(math.Ceil(months*daysPerMonth)))
+		progress.ProjectedCompletion = &projected
+	}
+
+	return progress, nil
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+	"go-moneyku/internal/importer"
+	"go-moneyku/internal/validation"
+)
+
+type ImportService struct {
+	walletRepo         domain.WalletRepository
+	ledgerRepo         domain.LedgerRepository
+	importRepo         domain.ImportRepository
+	transactionService *TransactionService
+	uow                domain.UnitOfWork
+}
+
+func NewImportService(walletRepo domain.WalletRepository, ledgerRepo domain.LedgerRepository, importRepo domain.ImportRepository, transactionService *TransactionService, uow domain.UnitOfWork) *ImportService {
+	return &ImportService{
+		walletRepo:         walletRepo,
+		ledgerRepo:         ledgerRepo,
+		importRepo:         importRepo,
+		transactionService: transactionService,
+		uow:                uow,
+	}
+}
+
+// ImportOptions controls how parsed entries are applied
+type ImportOptions struct {
+	// WalletID is used for rows that do not name a wallet themselves
+	WalletID int
+	// Commit creates the transactions; without it the import is a dry run
+	Commit bool
+	// SkipInvalid commits the valid rows even when other rows have errors
+	SkipInvalid bool
+}
+
+type ImportRow struct {
+	importer.Entry
+	WalletID      int `json:"wallet_id,omitempty"`
+	TransactionID int `json:"transaction_id,omitempty"`
+	// Duplicate marks an entry whose external ID was imported before, or
+	// appears earlier in the same file; it is skipped
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+type ImportResult struct {
+	Committed   bool              `json:"committed"`
+	TotalRows   int               `json:"total_rows"`
+	ValidRows   int               `json:"valid_rows"`
+	InvalidRows int               `json:"invalid_rows"`
+	Duplicates  int               `json:"duplicates"`
+	Imported    int               `json:"imported"`
+	Balance     *StatementBalance `json:"balance,omitempty"`
+	Rows        []ImportRow       `json:"rows"`
+}
+
+// StatementBalance sets the closing balance reported by the bank next to the
+// balance of the wallet on the same day. For a preview the wallet balance
+// already includes the entries that would be imported.
+type StatementBalance struct {
+	AsOf             time.Time    `json:"as_of"`
+	StatementBalance domain.Money `json:"statement_balance"`
+	WalletBalance    domain.Money `json:"wallet_balance"`
+	Difference       domain.Money `json:"difference"` // Statement minus wallet; zero when they agree
+}
+
+// ImportCSV parses a CSV file with the given column mapping and either
+// previews or commits the resulting transactions
+func (s *ImportService) ImportCSV(ctx context.Context, userID int, file io.Reader, mapping importer.CSVMapping, options ImportOptions) (*ImportResult, error) {
+	entries, err := importer.ParseCSV(file, mapping)
+	if err != nil {
+		return nil, domain.Invalid("%v", err)
+	}
+
+	return s.importEntries(ctx, userID, entries, options)
+}
+
+// ImportStatement parses a bank statement file in one of importer.Formats()
+// into the wallet chosen in options. Entries imported before are skipped, and
+// the closing balance of the statement is compared with the wallet.
+func (s *ImportService) ImportStatement(ctx context.Context, userID int, format string, file io.Reader, parseOptions importer.Options, options ImportOptions) (*ImportResult, error) {
+	statementImporter, ok := importer.ForFormat(format)
+	if !ok {
+		return nil, domain.Invalid("unsupported format %q (use one of: %s)", format, strings.Join(importer.Formats(), ", "))
+	}
+	if options.WalletID == 0 {
+		return nil, domain.Invalid("wallet is required")
+	}
+
+	wallet, err := findOwnedWallet(ctx, s.walletRepo, options.WalletID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statement, err := statementImporter.Parse(file, parseOptions)
+	if err != nil {
+		return nil, domain.Invalid("%v", err)
+	}
+	if statement.Currency != "" && wallet.Currency != "" && !strings.EqualFold(statement.Currency, wallet.Currency) {
+		return nil, domain.Invalid("statement is in %s but the wallet uses %s", statement.Currency, wallet.Currency)
+	}
+
+	result, err := s.importEntries(ctx, userID, statement.Entries, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if statement.ClosingBalance != nil {
+		result.Balance, err = s.statementBalance(ctx, wallet, statement, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (s *ImportService) importEntries(ctx context.Context, userID int, entries []importer.Entry, options ImportOptions) (*ImportResult, error) {
+	wallets, err := s.walletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wallets: %w", err)
+	}
+
+	if options.WalletID != 0 {
+		if _, err := findOwnedWallet(ctx, s.walletRepo, options.WalletID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &ImportResult{
+		TotalRows: len(entries),
+		Rows:      make([]ImportRow, len(entries)),
+	}
+	for i, entry := range entries {
+		row := ImportRow{Entry: entry}
+		row.WalletID = resolveImportWallet(&row.Entry, wallets, options.WalletID)
+		if row.Type == domain.TransactionTypeTransfer {
+			row.Errors = append(row.Errors, "transfers cannot be imported")
+		}
+		result.Rows[i] = row
+	}
+
+	if err := s.markDuplicates(ctx, result); err != nil {
+		return nil, err
+	}
+
+	// A preview runs the same checks as a commit, so it does not promise rows
+	// that the commit would refuse
+	order := validateImportRows(result.Rows, wallets)
+	result.ValidRows = len(order)
+	for _, row := range result.Rows {
+		if len(row.Errors) > 0 {
+			result.InvalidRows++
+		}
+	}
+
+	if !options.Commit {
+		return result, nil
+	}
+	if result.InvalidRows > 0 && !options.SkipInvalid {
+		return nil, domain.Invalid("%d rows have errors; fix them or import with skip_invalid", result.InvalidRows)
+	}
+
+	// Everything is imported in one unit of work, so a failing row leaves no
+	// partial import behind
+	err = s.uow.Do(ctx, func(repos domain.Repositories) error {
+		for _, i := range order {
+			row := &result.Rows[i]
+			transaction, err := s.transactionService.createTransaction(ctx, repos, userID, importRequest(row))
+			if err != nil {
+				return fmt.Errorf("line %d: %w", row.Line, err)
+			}
+			row.TransactionID = transaction.ID
+
+			if row.ExternalID == "" {
+				continue
+			}
+			// Another import of the same statement may have committed since
+			// the duplicate check; the unique key settles it
+			claimed, err := repos.Imports.ClaimExternalID(ctx, row.WalletID, row.ExternalID, transaction.ID)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				return domain.Conflict("entry_already_imported", "line %d: entry %s was already imported", row.Line, row.ExternalID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Committed = true
+	result.Imported = len(order)
+	return result, nil
+}
+
+// validateImportRows checks the rows that are neither invalid nor duplicates
+// the way creating their transactions would: the request itself, and the
+// balance every wallet is left with when the rows are applied in order. Rows
+// that fail get an error; the others are returned by index, oldest first so
+// that balances build up in the order the money actually moved.
+func validateImportRows(rows []ImportRow, wallets []domain.Wallet) []int {
+	order := make([]int, 0, len(rows))
+	for i, row := range rows {
+		if len(row.Errors) == 0 && !row.Duplicate {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return rows[order[a]].Date.Before(rows[order[b]].Date)
+	})
+
+	byID := make(map[int]*domain.Wallet, len(wallets))
+	balances := make(map[int]domain.Money, len(wallets))
+	for i := range wallets {
+		byID[wallets[i].ID] = &wallets[i]
+		balances[wallets[i].ID] = wallets[i].Balance
+	}
+
+	valid := order[:0]
+	for _, i := range order {
+		row := &rows[i]
+		if err := validation.Struct(importRequest(row)); err != nil {
+			var invalid *domain.Error
+			if errors.As(err, &invalid) && len(invalid.Fields) > 0 {
+				for _, field := range invalid.Fields {
+					row.Errors = append(row.Errors, field.Message)
+				}
+			} else {
+				row.Errors = append(row.Errors, err.Error())
+			}
+			continue
+		}
+
+		if wallet, ok := byID[row.WalletID]; ok {
+			amount := row.Amount.WithCurrency(wallet.Currency)
+			balance := balances[wallet.ID].Add(amount)
+			if row.Type == domain.TransactionTypeExpense {
+				balance = balances[wallet.ID].Sub(amount)
+			}
+			if !wallet.AllowsBalance(balance) {
+				row.Errors = append(row.Errors, fmt.Sprintf("insufficient balance in wallet %q", wallet.Name))
+				continue
+			}
+			balances[wallet.ID] = balance
+		}
+		valid = append(valid, i)
+	}
+
+	return valid
+}
+
+// importRequest is the transaction an import row creates
+func importRequest(row *ImportRow) CreateTransactionRequest {
+	return CreateTransactionRequest{
+		WalletID:    row.WalletID,
+		Type:        row.Type,
+		Amount:      row.Amount,
+		Category:    row.Category,
+		Description: row.Description,
+		Date:        importDate(row.Date),
+	}
+}
+
+// markDuplicates flags the valid rows whose external ID was already imported
+// into their wallet, or occurs on an earlier row of the file
+func (s *ImportService) markDuplicates(ctx context.Context, result *ImportResult) error {
+	byWallet := make(map[int][]string)
+	for _, row := range result.Rows {
+		if row.ExternalID != "" && len(row.Errors) == 0 {
+			byWallet[row.WalletID] = append(byWallet[row.WalletID], row.ExternalID)
+		}
+	}
+	if len(byWallet) == 0 {
+		return nil
+	}
+
+	type key struct {
+		walletID   int
+		externalID string
+	}
+	seen := make(map[key]bool)
+	for walletID, externalIDs := range byWallet {
+		imported, err := s.importRepo.FindImportedExternalIDs(ctx, walletID, externalIDs)
+		if err != nil {
+			return err
+		}
+		for _, externalID := range imported {
+			seen[key{walletID, externalID}] = true
+		}
+	}
+
+	for i := range result.Rows {
+		row := &result.Rows[i]
+		if row.ExternalID == "" || len(row.Errors) > 0 {
+			continue
+		}
+		k := key{row.WalletID, row.ExternalID}
+		if seen[k] {
+			row.Duplicate = true
+			result.Duplicates++
+		}
+		seen[k] = true
+	}
+
+	return nil
+}
+
+// statementBalance compares the closing balance of the statement with the
+// wallet balance at the end of the closing date
+func (s *ImportService) statementBalance(ctx context.Context, wallet *domain.Wallet, statement *importer.Statement, result *ImportResult) (*StatementBalance, error) {
+	asOf := dateOf(time.Now())
+	if statement.ClosingDate != nil {
+		asOf = *statement.ClosingDate
+	} else if len(statement.Entries) > 0 {
+		asOf = time.Time{}
+		for _, entry := range statement.Entries {
+			if entry.Date.After(asOf) {
+				asOf = entry.Date
+			}
+		}
+	}
+
+	walletBalance, err := s.ledgerRepo.WalletBalanceAt(ctx, wallet.ID, asOf.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Committed {
+		for _, row := range result.Rows {
+			if len(row.Errors) > 0 || row.Duplicate || row.Date.After(asOf) {
+				continue
+			}
+			if row.Type == domain.TransactionTypeExpense {
+				walletBalance = walletBalance.Sub(row.Amount)
+			} else {
+				walletBalance = walletBalance.Add(row.Amount)
+			}
+		}
+	}
+
+	statementBalance := statement.ClosingBalance.WithCurrency(wallet.Currency)
+	walletBalance = walletBalance.WithCurrency(wallet.Currency)
+	return &StatementBalance{
+		AsOf:             asOf,
+		StatementBalance: statementBalance,
+		WalletBalance:    walletBalance,
+		Difference:       statementBalance.Sub(walletBalance),
+	}, nil
+}
+
+// resolveImportWallet finds the wallet named by the entry, by ID or by name
+// ignoring case, and falls back to the default wallet
+func resolveImportWallet(entry *importer.Entry, wallets []domain.Wallet, defaultWalletID int) int {
+	if entry.Wallet == "" {
+		if defaultWalletID == 0 {
+			entry.Errors = append(entry.Errors, "wallet is required (map a wallet column or choose a wallet)")
+		}
+		return defaultWalletID
+	}
+
+	if id, err := strconv.Atoi(entry.Wallet); err == nil {
+		for _, wallet := range wallets {
+			if wallet.ID == id {
+				return wallet.ID
+			}
+		}
+	}
+	for _, wallet := range wallets {
+		if strings.EqualFold(strings.TrimSpace(wallet.Name), entry.Wallet) {
+			return wallet.ID
+		}
+	}
+
+	entry.Errors = append(entry.Errors, fmt.Sprintf("wallet %q not found", entry.Wallet))
+	return 0
+}
+
+// importDate formats an imported date for CreateTransactionRequest, keeping
+// the time of day when the file had one
+func importDate(date time.Time) string {
+	if date.Hour() == 0 && date.Minute() == 0 && date.Second() == 0 {
+		return date.Format("2006-01-02")
+	}
+	return date.Format(time.RFC3339)
+}
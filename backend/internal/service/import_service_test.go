@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-moneyku/internal/domain"
+	"go-moneyku/internal/importer"
+)
+
+func TestValidateImportRows(t *testing.T) {
+	limit := rupiah(500)
+	wallets := []domain.Wallet{
+		{ID: 1, Name: "Dompet", Balance: rupiah(100), Currency: "IDR", AccountClass: domain.AccountClassAsset},
+		{ID: 2, Name: "Kartu Kredit", Balance: rupiah(0), Currency: "IDR", AccountClass: domain.AccountClassLiability, CreditLimit: &limit},
+	}
+	row := func(line, walletID int, date int, transactionType domain.TransactionType, amount int64) ImportRow {
+		return ImportRow{
+			Entry:    importer.Entry{Line: line, Date: day(date), Type: transactionType, Amount: rupiah(amount)},
+			WalletID: walletID,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		rows       []ImportRow
+		wantOrder  []int
+		wantErrors map[int][]string // Errors by row index
+	}{
+		{
+			name: "income is applied before a later expense",
+			rows: []ImportRow{
+				row(1, 1, 5, domain.TransactionTypeExpense, 150),
+				row(2, 1, 3, domain.TransactionTypeIncome, 100),
+			},
+			wantOrder: []int{1, 0},
+		},
+		{
+			name: "expense past the balance of an asset",
+			rows: []ImportRow{
+				row(1, 1, 1, domain.TransactionTypeExpense, 80),
+				row(2, 1, 2, domain.TransactionTypeExpense, 30),
+				row(3, 1, 3, domain.TransactionTypeExpense, 20),
+			},
+			wantOrder:  []int{0, 2},
+			wantErrors: map[int][]string{1: {`insufficient balance in wallet "Dompet"`}},
+		},
+		{
+			name: "liability up to its credit limit",
+			rows: []ImportRow{
+				row(1, 2, 1, domain.TransactionTypeExpense, 500),
+				row(2, 2, 2, domain.TransactionTypeExpense, 1),
+			},
+			wantOrder:  []int{0},
+			wantErrors: map[int][]string{1: {`insufficient balance in wallet "Kartu Kredit"`}},
+		},
+		{
+			name: "rows with errors and duplicates are left alone",
+			rows: []ImportRow{
+				{Entry: importer.Entry{Line: 1, Date: day(1), Type: domain.TransactionTypeExpense, Amount: rupiah(1000), Errors: []string{"wallet is required (map a wallet column or choose a wallet)"}}},
+				{Entry: importer.Entry{Line: 2, Date: day(1), Type: domain.TransactionTypeExpense, Amount: rupiah(1000)}, WalletID: 1, Duplicate: true},
+			},
+			wantOrder: []int{},
+			wantErrors: map[int][]string{
+				0: {"wallet is required (map a wallet column or choose a wallet)"},
+			},
+		},
+		{
+			name: "request validation",
+			rows: []ImportRow{
+				{Entry: importer.Entry{Line: 1, Date: day(1), Type: domain.TransactionTypeIncome, Amount: rupiah(10), Category: strings.Repeat("a", 101)}, WalletID: 1},
+				row(2, 1, 2, domain.TransactionTypeIncome, 10),
+			},
+			wantOrder:  []int{1},
+			wantErrors: map[int][]string{0: {"category must be at most 100 characters"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validateImportRows(tt.rows, append([]domain.Wallet(nil), wallets...))
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Fatalf("order = %v, want %v", order, tt.wantOrder)
+			}
+			for i, row := range tt.rows {
+				if !reflect.DeepEqual(row.Errors, tt.wantErrors[i]) {
+					t.Errorf("row %d errors = %q, want %q", i, row.Errors, tt.wantErrors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMarkDuplicatesOnReimport(t *testing.T) {
+	files := []struct {
+		format string
+		file   string
+	}{
+		{
+			format: "ofx",
+			// The second payment repeats the FITID of the first
+			file: "<OFX><CURDEF>IDR<BANKTRANLIST>" +
+				"<STMTTRN><DTPOSTED>20240301<TRNAMT>-100<FITID>A1</STMTTRN>" +
+				"<STMTTRN><DTPOSTED>20240301<TRNAMT>-100<FITID>A1</STMTTRN>" +
+				"<STMTTRN><DTPOSTED>20240302<TRNAMT>250<FITID>A2</STMTTRN>" +
+				"</BANKTRANLIST></OFX>",
+		},
+		{
+			format: "qif",
+			// Two identical purchases are two entries
+			file: "!Type:Bank\n" +
+				"D3/1/2024\nT-100\nPToko\n^\n" +
+				"D3/1/2024\nT-100\nPToko\n^\n" +
+				"D3/2/2024\nT250\nPGaji\nN17\n^\n",
+		},
+	}
+	wallets := []domain.Wallet{{ID: 1, Name: "Dompet", Balance: rupiah(1000), Currency: "IDR"}}
+
+	for _, f := range files {
+		t.Run(f.format, func(t *testing.T) {
+			repo := &fakeImportRepository{imported: make(map[string]bool)}
+			service := &ImportService{importRepo: repo}
+			preview := func() (*ImportResult, []int) {
+				statementImporter, _ := importer.ForFormat(f.format)
+				statement, err := statementImporter.Parse(strings.NewReader(f.file), importer.Options{})
+				if err != nil {
+					t.Fatalf("parse: %v", err)
+				}
+				result := &ImportResult{Rows: make([]ImportRow, len(statement.Entries))}
+				for i, entry := range statement.Entries {
+					result.Rows[i] = ImportRow{Entry: entry, WalletID: 1}
+				}
+				if err := service.markDuplicates(context.Background(), result); err != nil {
+					t.Fatalf("markDuplicates: %v", err)
+				}
+				return result, validateImportRows(result.Rows, append([]domain.Wallet(nil), wallets...))
+			}
+
+			first, order := preview()
+			wantDuplicates := 0
+			if f.format == "ofx" {
+				wantDuplicates = 1
+			}
+			if first.Duplicates != wantDuplicates || len(order) != 3-wantDuplicates {
+				t.Fatalf("first import: %d duplicates and %d rows to import, want %d and %d", first.Duplicates, len(order), wantDuplicates, 3-wantDuplicates)
+			}
+			for _, i := range order {
+				if _, err := repo.ClaimExternalID(context.Background(), 1, first.Rows[i].ExternalID, i+1); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			second, order := preview()
+			if second.Duplicates != 3 || len(order) != 0 {
+				t.Fatalf("second import: %d duplicates and %d rows to import, want 3 and 0", second.Duplicates, len(order))
+			}
+		})
+	}
+}
+
+// fakeImportRepository keeps the imported external IDs of wallet 1 in memory
+type fakeImportRepository struct {
+	imported map[string]bool
+}
+
+func (r *fakeImportRepository) ClaimExternalID(ctx context.Context, walletID int, externalID string, transactionID int) (bool, error) {
+	if r.imported[externalID] {
+		return false, nil
+	}
+	r.imported[externalID] = true
+	return true, nil
+}
+
+func (r *fakeImportRepository) FindImportedExternalIDs(ctx context.Context, walletID int, externalIDs []string) ([]string, error) {
+	var imported []string
+	for _, externalID := range externalIDs {
+		if r.imported[externalID] {
+			imported = append(imported, externalID)
+		}
+	}
+	return imported, nil
+}
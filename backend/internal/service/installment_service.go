@@ -0,0 +1,437 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// maxInstallmentMonths bounds the length of an installment plan
+const maxInstallmentMonths = 120
+
+type InstallmentService struct {
+	installmentRepo    domain.InstallmentRepository
+	walletRepo         domain.WalletRepository
+	transactionService *TransactionService
+	uow                domain.UnitOfWork
+}
+
+func NewInstallmentService(installmentRepo domain.InstallmentRepository, walletRepo domain.WalletRepository, transactionService *TransactionService, uow domain.UnitOfWork) *InstallmentService {
+	return &InstallmentService{
+		installmentRepo:    installmentRepo,
+		walletRepo:         walletRepo,
+		transactionService: transactionService,
+		uow:                uow,
+	}
+}
+
+type CreateInstallmentRequest struct {
+	WalletID         int           `json:"wallet_id"`
+	Category         string        `json:"category"`
+	Description      string        `json:"description"`
+	Principal        domain.Money  `json:"principal"`
+	Months           int           `json:"months"`
+	InterestRate     *domain.Rate  `json:"interest_rate,omitempty"` // Flat monthly rate, leave out for 0%
+	AdminFee         *domain.Money `json:"admin_fee,omitempty"`
+	FirstBillingDate string        `json:"first_billing_date"` // YYYY-MM-DD
+}
+
+type PayOffInstallmentRequest struct {
+	Date string `json:"date"` // YYYY-MM-DD, defaults to today
+}
+
+// InstallmentPlanDetail is a plan with its full schedule: the instalments
+// charged so far followed by the ones still to come
+type InstallmentPlanDetail struct {
+	domain.InstallmentPlan
+	MonthlyPayment     domain.Money           `json:"monthly_payment"`
+	TotalCost          domain.Money           `json:"total_cost"` // Principal, interest and fees over the whole plan
+	ChargedCount       int                    `json:"charged_count"`
+	RemainingPrincipal domain.Money           `json:"remaining_principal"`
+	NextDueDate        *time.Time             `json:"next_due_date,omitempty"`
+	Schedule           []ScheduledInstallment `json:"schedule"`
+}
+
+type ScheduledInstallment struct {
+	domain.InstallmentCharge
+	Amount  domain.Money `json:"amount"`
+	Charged bool         `json:"charged"`
+}
+
+func (s *InstallmentService) CreatePlan(ctx context.Context, userID int, req CreateInstallmentRequest) (*InstallmentPlanDetail, error) {
+	wallet, err := findOwnedWallet(ctx, s.walletRepo, req.WalletID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Months < 1 || req.Months > maxInstallmentMonths {
+		return nil, domain.Invalid("months must be between 1 and %d", maxInstallmentMonths)
+	}
+	// Every instalment repays at least one minor unit of the principal
+	if req.Principal.Minor() < int64(req.Months) {
+		return nil, domain.Invalid("principal is too small for %d months", req.Months)
+	}
+	if req.FirstBillingDate == "" {
+		return nil, domain.Invalid("first billing date is required")
+	}
+	firstBillingDate, err := time.Parse("2006-01-02", req.FirstBillingDate)
+	if err != nil {
+		return nil, domain.Invalid("invalid first billing date format (use YYYY-MM-DD)")
+	}
+
+	plan := &domain.InstallmentPlan{
+		UserID:           userID,
+		WalletID:         wallet.ID,
+		Category:         req.Category,
+		Description:      req.Description,
+		Principal:        req.Principal.WithCurrency(wallet.Currency),
+		Months:           req.Months,
+		AdminFee:         domain.NewMoney(0, wallet.Currency),
+		InterestRate:     req.InterestRate,
+		FirstBillingDate: firstBillingDate,
+		Status:           domain.InstallmentActive,
+	}
+	if req.AdminFee != nil {
+		if req.AdminFee.IsNegative() {
+			return nil, domain.Invalid("admin fee cannot be negative")
+		}
+		plan.AdminFee = req.AdminFee.WithCurrency(wallet.Currency)
+	}
+
+	if err := s.installmentRepo.Create(ctx, plan); err != nil {
+		return nil, fmt.Errorf("failed to create installment plan: %w", err)
+	}
+
+	return planDetail(plan, nil), nil
+}
+
+func (s *InstallmentService) GetUserPlans(ctx context.Context, userID int) ([]InstallmentPlanDetail, error) {
+	plans, err := s.installmentRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch installment plans: %w", err)
+	}
+
+	details := make([]InstallmentPlanDetail, 0, len(plans))
+	for i := range plans {
+		charges, err := s.installmentRepo.FindCharges(ctx, plans[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, *planDetail(&plans[i], charges))
+	}
+	return details, nil
+}
+
+func (s *InstallmentService) GetPlan(ctx context.Context, planID int, userID int) (*InstallmentPlanDetail, error) {
+	plan, err := s.findOwnedPlan(ctx, s.installmentRepo.FindByID, planID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	charges, err := s.installmentRepo.FindCharges(ctx, plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	return planDetail(plan, charges), nil
+}
+
+// DeletePlan stops a plan. Instalments that were already charged are kept.
+func (s *InstallmentService) DeletePlan(ctx context.Context, planID int, userID int) error {
+	if _, err := s.findOwnedPlan(ctx, s.installmentRepo.FindByID, planID, userID); err != nil {
+		return err
+	}
+
+	if err := s.installmentRepo.Delete(ctx, planID); err != nil {
+		return fmt.Errorf("failed to delete installment plan: %w", err)
+	}
+
+	return nil
+}
+
+// PayOff settles a plan early with one charge for the remaining principal.
+// Interest of the instalments that are not charged yet is waived.
+func (s *InstallmentService) PayOff(ctx context.Context, planID int, userID int, req PayOffInstallmentRequest) (*InstallmentPlanDetail, error) {
+	date := dateOf(time.Now())
+	if req.Date != "" {
+		var err error
+		date, err = time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return nil, domain.Invalid("invalid date format (use YYYY-MM-DD)")
+		}
+	}
+
+	var plan *domain.InstallmentPlan
+	var charges []domain.InstallmentCharge
+	err := s.uow.Do(ctx, func(repos domain.Repositories) error {
+		var err error
+		plan, err = s.findOwnedPlan(ctx, repos.Installments.FindByIDForUpdate, planID, userID)
+		if err != nil {
+			return err
+		}
+		if !plan.IsOpen() {
+			return domain.Conflict("installment_plan_closed", "installment plan is already %s", plan.Status)
+		}
+
+		charges, err = repos.Installments.FindCharges(ctx, plan.ID)
+		if err != nil {
+			return err
+		}
+
+		charge := domain.InstallmentCharge{
+			PlanID:    plan.ID,
+			Number:    len(charges) + 1,
+			DueDate:   date,
+			Principal: remainingPrincipal(plan, charges),
+			Interest:  domain.NewMoney(0, plan.Principal.Currency()),
+			Fee:       domain.NewMoney(0, plan.Principal.Currency()),
+			Payoff:    true,
+		}
+		if len(charges) == 0 {
+			charge.Fee = plan.AdminFee
+		}
+		if err := s.charge(ctx, repos, plan, &charge); err != nil {
+			return err
+		}
+		charges = append(charges, charge)
+
+		plan.Status = domain.InstallmentPaidOff
+		return repos.Installments.UpdateStatus(ctx, plan.ID, plan.Status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return planDetail(plan, charges), nil
+}
+
+// RunDue charges every instalment that is due up to and including today.
+// It implements ScheduledJob.
+func (s *InstallmentService) RunDue(ctx context.Context, now time.Time) {
+	plans, err := s.installmentRepo.FindActive(ctx)
+	if err != nil {
+		log.Printf("Installments: failed to fetch plans: %v", err)
+		return
+	}
+
+	today := dateOf(now)
+	for i := range plans {
+		// Stop early on shutdown; the rest is picked up on the next run
+		if ctx.Err() != nil {
+			return
+		}
+		s.chargeDue(ctx, plans[i].ID, today)
+	}
+}
+
+// Resume reactivates a suspended plan, once the reason it was suspended for
+// has been dealt with, and charges the instalments that are overdue
+func (s *InstallmentService) Resume(ctx context.Context, planID int, userID int) (*InstallmentPlanDetail, error) {
+	err := s.uow.Do(ctx, func(repos domain.Repositories) error {
+		plan, err := s.findOwnedPlan(ctx, repos.Installments.FindByIDForUpdate, planID, userID)
+		if err != nil {
+			return err
+		}
+		if plan.Status != domain.InstallmentSuspended {
+			return domain.Conflict("installment_plan_not_suspended", "installment plan is %s, not suspended", plan.Status)
+		}
+		return repos.Installments.UpdateStatus(ctx, plan.ID, domain.InstallmentActive)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A failure suspends the plan again, with the new reason in the result
+	s.chargeDue(ctx, planID, dateOf(time.Now()))
+	return s.GetPlan(ctx, planID, userID)
+}
+
+// chargeDue charges the instalments of the plan that are due, in order, one
+// unit of work each. An instalment that fails for a reason retrying cannot
+// fix, or because the card is at its credit limit, suspends the plan; unlike
+// a skipped recurring occurrence, a suspended plan is charged again once the
+// user resumes it.
+func (s *InstallmentService) chargeDue(ctx context.Context, planID int, today time.Time) {
+	for {
+		var number int
+		err := s.uow.Do(ctx, func(repos domain.Repositories) error {
+			number = 0
+
+			// The plan is locked and read again, so a payoff committed since
+			// the plans were listed is seen and not charged twice
+			plan, err := repos.Installments.FindByIDForUpdate(ctx, planID)
+			if err != nil {
+				return err
+			}
+			if plan.Status != domain.InstallmentActive {
+				return nil
+			}
+
+			charges, err := repos.Installments.FindCharges(ctx, plan.ID)
+			if err != nil {
+				return err
+			}
+			schedule := installmentSchedule(plan)
+			if len(charges) >= len(schedule) {
+				return nil
+			}
+			charge := schedule[len(charges)]
+			if charge.DueDate.After(today) {
+				return nil
+			}
+
+			// The claim, the transaction and the status are committed together,
+			// so an instalment is either fully charged or not at all
+			number = charge.Number
+			if err := s.charge(ctx, repos, plan, &charge); err != nil {
+				return err
+			}
+			if charge.Number == plan.Months {
+				return repos.Installments.UpdateStatus(ctx, plan.ID, domain.InstallmentCompleted)
+			}
+			return nil
+		})
+		if err != nil {
+			if !isPermanentFailure(err) && !errors.Is(err, domain.ErrInsufficientFunds) {
+				// Retried on the next run
+				log.Printf("Installments: plan %d failed on instalment %d: %v", planID, number, err)
+				return
+			}
+
+			// Later instalments wait so that they are charged in order, and the
+			// plan stays suspended until the user resumes it
+			reason := fmt.Sprintf("instalment %d could not be charged: %v", number, err)
+			if err := s.installmentRepo.Suspend(ctx, planID, reason); err != nil {
+				log.Printf("Installments: plan %d: %v", planID, err)
+				return
+			}
+			log.Printf("Installments: plan %d suspended: %s", planID, reason)
+			return
+		}
+		if number == 0 {
+			return
+		}
+	}
+}
+
+// charge claims the instalment and books it as an expense on the plan's wallet
+func (s *InstallmentService) charge(ctx context.Context, repos domain.Repositories, plan *domain.InstallmentPlan, charge *domain.InstallmentCharge) error {
+	claimed, err := repos.Installments.ClaimCharge(ctx, charge)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return domain.Conflict("installment_already_charged", "instalment %d was already charged", charge.Number)
+	}
+
+	description := fmt.Sprintf("Cicilan %d/%d", charge.Number, plan.Months)
+	if charge.Payoff {
+		description = "Pelunasan cicilan"
+	}
+	if plan.Description != "" {
+		description += ": " + plan.Description
+	}
+
+	transaction, err := s.transactionService.createTransaction(ctx, repos, plan.UserID, CreateTransactionRequest{
+		WalletID:    plan.WalletID,
+		Type:        domain.TransactionTypeExpense,
+		Amount:      charge.Amount(),
+		Category:    plan.Category,
+		Description: description,
+		Date:        charge.DueDate.Format("2006-01-02"),
+	})
+	if err != nil {
+		return err
+	}
+
+	charge.TransactionID = &transaction.ID
+	return repos.Installments.SetChargeTransaction(ctx, plan.ID, charge.Number, transaction.ID)
+}
+
+// findOwnedPlan loads the plan with find, either FindByID or
+// FindByIDForUpdate of a repository, and checks that it belongs to the user
+func (s *InstallmentService) findOwnedPlan(ctx context.Context, find func(ctx context.Context, id int) (*domain.InstallmentPlan, error), planID int, userID int) (*domain.InstallmentPlan, error) {
+	plan, err := find(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.UserID != userID {
+		return nil, domain.Forbidden("installment plan")
+	}
+	return plan, nil
+}
+
+// installmentSchedule lists every instalment of the plan. The principal is
+// split evenly with the remainder on the last instalment, interest is flat on
+// the full principal and the admin fee comes with the first instalment.
+func installmentSchedule(plan *domain.InstallmentPlan) []domain.InstallmentCharge {
+	currency := plan.Principal.Currency()
+	months := int64(plan.Months)
+	share := plan.Principal.Minor() / months
+	interest := domain.NewMoney(0, currency)
+	if plan.InterestRate != nil {
+		interest = plan.Principal.Convert(*plan.InterestRate, currency)
+	}
+	first := dateOf(plan.FirstBillingDate)
+
+	schedule := make([]domain.InstallmentCharge, plan.Months)
+	for i := range schedule {
+		principal := domain.NewMoney(share, currency)
+		if i == plan.Months-1 {
+			principal = domain.NewMoney(plan.Principal.Minor()-share*(months-1), currency)
+		}
+		fee := domain.NewMoney(0, currency)
+		if i == 0 {
+			fee = plan.AdminFee
+		}
+		schedule[i] = domain.InstallmentCharge{
+			PlanID:    plan.ID,
+			Number:    i + 1,
+			DueDate:   monthlyPeriodStart(first.Year(), first.Month()+time.Month(i), first.Day()),
+			Principal: principal,
+			Interest:  interest,
+			Fee:       fee,
+		}
+	}
+	return schedule
+}
+
+func remainingPrincipal(plan *domain.InstallmentPlan, charges []domain.InstallmentCharge) domain.Money {
+	remaining := plan.Principal
+	for _, charge := range charges {
+		remaining = remaining.Sub(charge.Principal)
+	}
+	return remaining
+}
+
+// planDetail merges the charged instalments with the rest of the schedule
+func planDetail(plan *domain.InstallmentPlan, charges []domain.InstallmentCharge) *InstallmentPlanDetail {
+	schedule := installmentSchedule(plan)
+
+	detail := &InstallmentPlanDetail{
+		InstallmentPlan:    *plan,
+		MonthlyPayment:     schedule[0].Principal.Add(schedule[0].Interest),
+		TotalCost:          plan.AdminFee,
+		ChargedCount:       len(charges),
+		RemainingPrincipal: remainingPrincipal(plan, charges),
+	}
+	for _, charge := range schedule {
+		detail.TotalCost = detail.TotalCost.Add(charge.Principal).Add(charge.Interest)
+	}
+
+	for _, charge := range charges {
+		detail.Schedule = append(detail.Schedule, ScheduledInstallment{InstallmentCharge: charge, Amount: charge.Amount(), Charged: true})
+	}
+	if plan.IsOpen() && len(charges) < len(schedule) {
+		for _, charge := range schedule[len(charges):] {
+			detail.Schedule = append(detail.Schedule, ScheduledInstallment{InstallmentCharge: charge, Amount: charge.Amount()})
+		}
+		nextDueDate := schedule[len(charges)].DueDate
+		detail.NextDueDate = &nextDueDate
+	}
+
+	return detail
+}
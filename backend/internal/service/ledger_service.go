@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+type LedgerService struct {
+	ledgerRepo domain.LedgerRepository
+}
+
+func NewLedgerService(ledgerRepo domain.LedgerRepository) *LedgerService {
+	return &LedgerService{
+		ledgerRepo: ledgerRepo,
+	}
+}
+
+type WalletReconciliation struct {
+	domain.WalletLedgerBalance
+	Difference domain.Money `json:"difference"`
+	Reconciled bool         `json:"reconciled"`
+}
+
+type ReconciliationReport struct {
+	Reconciled bool                   `json:"reconciled"`
+	Wallets    []WalletReconciliation `json:"wallets"`
+}
+
+// Reconcile compares every wallet's cached balance with the sum of its postings
+func (s *LedgerService) Reconcile(ctx context.Context, userID int) (*ReconciliationReport, error) {
+	balances, err := s.ledgerRepo.GetWalletBalances(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ledger balances: %w", err)
+	}
+
+	report := &ReconciliationReport{Reconciled: true}
+	for _, balance := range balances {
+		difference := balance.CachedBalance.Sub(balance.LedgerBalance)
+		report.Wallets = append(report.Wallets, WalletReconciliation{
+			WalletLedgerBalance: balance,
+			Difference:          difference,
+			Reconciled:          difference.IsZero(),
+		})
+		if !difference.IsZero() {
+			report.Reconciled = false
+		}
+	}
+
+	return report, nil
+}
+
+// journalEntryForTransaction builds the posting pattern for a transaction:
+//   - income:   +wallet, -income:<category>
+//   - expense:  -wallet, +expense:<category>
+//   - transfer: -source wallet, +destination wallet
+//
+// A transfer between currencies also books the amount sent against
+// exchange:<source currency> and the amount received against
+// exchange:<destination currency>.
+func journalEntryForTransaction(transaction *domain.Transaction) *domain.JournalEntry {
+	entry := &domain.JournalEntry{
+		UserID: transaction.UserID,
+		Memo:   transaction.Description,
+		Date:   transaction.Date,
+	}
+	if transaction.ID != 0 {
+		transactionID := transaction.ID
+		entry.TransactionID = &transactionID
+	}
+
+	walletID := transaction.WalletID
+	amount := transaction.Amount
+
+	switch transaction.Type {
+	case domain.TransactionTypeIncome:
+		entry.Kind = domain.JournalEntryIncome
+		entry.Postings = []domain.Posting{
+			{WalletID: &walletID, Account: domain.AccountWallet, Amount: amount},
+			{Account: domain.AccountIncomePrefix + categoryAccountName(transaction.Category), Amount: amount.Neg()},
+		}
+
+	case domain.TransactionTypeExpense:
+		entry.Kind = domain.JournalEntryExpense
+		entry.Postings = []domain.Posting{
+			{WalletID: &walletID, Account: domain.AccountWallet, Amount: amount.Neg()},
+			{Account: domain.AccountExpensePrefix + categoryAccountName(transaction.Category), Amount: amount},
+		}
+
+	case domain.TransactionTypeTransfer:
+		entry.Kind = domain.JournalEntryTransfer
+		received := amount
+		if transaction.ToAmount != nil {
+			received = *transaction.ToAmount
+		}
+
+		destination := domain.Posting{Account: domain.AccountRemovedWallet, Amount: received}
+		// The destination may have been deleted, in which case to_wallet_id is NULL
+		if transaction.ToWalletID != nil {
+			toWalletID := *transaction.ToWalletID
+			destination = domain.Posting{WalletID: &toWalletID, Account: domain.AccountWallet, Amount: received}
+		}
+		entry.Postings = []domain.Posting{
+			{WalletID: &walletID, Account: domain.AccountWallet, Amount: amount.Neg()},
+			destination,
+		}
+
+		if transaction.ToAmount != nil {
+			entry.Postings = append(entry.Postings,
+				domain.Posting{Account: domain.AccountExchangePrefix + amount.Currency(), Amount: amount},
+				domain.Posting{Account: domain.AccountExchangePrefix + received.Currency(), Amount: received.Neg()},
+			)
+		}
+	}
+
+	return entry
+}
+
+// adjustmentEntry moves a wallet balance by delta against an equity account,
+// used for opening balances and manual corrections
+func adjustmentEntry(wallet *domain.Wallet, delta domain.Money, account string, memo string) *domain.JournalEntry {
+	walletID := wallet.ID
+	return &domain.JournalEntry{
+		UserID: wallet.UserID,
+		Kind:   domain.JournalEntryAdjustment,
+		Memo:   memo,
+		Date:   time.Now(),
+		Postings: []domain.Posting{
+			{WalletID: &walletID, Account: domain.AccountWallet, Amount: delta},
+			{Account: account, Amount: delta.Neg()},
+		},
+	}
+}
+
+func categoryAccountName(category string) string {
+	if category == "" {
+		return "Uncategorized"
+	}
+	return category
+}
+
+// walletChanges returns how much each wallet's balance moves by when the
+// entry is posted (sign 1) or reversed (sign -1)
+func walletChanges(entry *domain.JournalEntry, sign int64) map[int]domain.Money {
+	changes := make(map[int]domain.Money)
+	for _, posting := range entry.Postings {
+		if posting.WalletID == nil {
+			continue
+		}
+		changes[*posting.WalletID] = changes[*posting.WalletID].Add(posting.Amount.Mul(sign))
+	}
+	return changes
+}
+
+// mergeWalletChanges adds the changes in other to changes
+func mergeWalletChanges(changes, other map[int]domain.Money) map[int]domain.Money {
+	for walletID, delta := range other {
+		changes[walletID] = changes[walletID].Add(delta)
+	}
+	return changes
+}
+
+// postJournalEntry records a balanced entry and applies its wallet postings to
+// the cached wallet balances in the same unit of work
+func postJournalEntry(ctx context.Context, repos domain.Repositories, entry *domain.JournalEntry) error {
+	if err := applyBalanceChanges(ctx, repos.Wallets, walletChanges(entry, 1)); err != nil {
+		return err
+	}
+	return recordJournalEntry(ctx, repos, entry)
+}
+
+// recordJournalEntry stores an entry whose wallet changes were already applied
+func recordJournalEntry(ctx context.Context, repos domain.Repositories, entry *domain.JournalEntry) error {
+	if !entry.IsBalanced() {
+		return fmt.Errorf("journal entry is not balanced")
+	}
+
+	if err := repos.Ledger.CreateEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record journal entry: %w", err)
+	}
+
+	return nil
+}
+
+// applyBalanceChanges adjusts every wallet in ascending ID order, so that
+// concurrent transactions lock the same rows in the same order and cannot deadlock
+func applyBalanceChanges(ctx context.Context, walletRepo domain.WalletRepository, changes map[int]domain.Money) error {
+	walletIDs := make([]int, 0, len(changes))
+	for walletID := range changes {
+		walletIDs = append(walletIDs, walletID)
+	}
+	sort.Ints(walletIDs)
+
+	for _, walletID := range walletIDs {
+		delta := changes[walletID]
+		if delta.IsZero() {
+			continue
+		}
+		if err := walletRepo.AdjustBalance(ctx, walletID, delta); err != nil {
+			if errors.Is(err, domain.ErrInsufficientBalance) {
+				return err
+			}
+			return fmt.Errorf("failed to update wallet balance: %w", err)
+		}
+	}
+
+	return nil
+}
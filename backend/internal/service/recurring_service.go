@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+// maxScheduleDays bounds the search for the next occurrence of a rule
+const maxScheduleDays = 5 * 366
+
+// maxCatchUpOccurrences bounds how many missed occurrences of one rule are
+// generated in a single scheduler run, e.g. after a long downtime
+const maxCatchUpOccurrences = 400
+
+type RecurringService struct {
+	recurringRepo      domain.RecurringRuleRepository
+	walletRepo         domain.WalletRepository
+	transactionService *TransactionService
+	uow                domain.UnitOfWork
+}
+
+func NewRecurringService(recurringRepo domain.RecurringRuleRepository, walletRepo domain.WalletRepository, transactionService *TransactionService, uow domain.UnitOfWork) *RecurringService {
+	return &RecurringService{
+		recurringRepo:      recurringRepo,
+		walletRepo:         walletRepo,
+		transactionService: transactionService,
+		uow:                uow,
+	}
+}
+
+type RecurringRuleRequest struct {
+	WalletID       int                        `json:"wallet_id"`
+	ToWalletID     *int                       `json:"to_wallet_id,omitempty"`
+	Type           domain.TransactionType     `json:"type"`
+	Amount         domain.Money               `json:"amount"`
+	Category       string                     `json:"category"`
+	Description    string                     `json:"description"`
+	Frequency      domain.RecurrenceFrequency `json:"frequency"`
+	DayOfWeek      *int                       `json:"day_of_week,omitempty"`
+	DayOfMonth     *int                       `json:"day_of_month,omitempty"`
+	CronExpression string                     `json:"cron_expression,omitempty"`
+	StartDate      string                     `json:"start_date"`
+	EndDate        string                     `json:"end_date,omitempty"`
+	MaxOccurrences *int                       `json:"max_occurrences,omitempty"`
+	Active         *bool                      `json:"active,omitempty"`
+}
+
+func (s *RecurringService) CreateRule(ctx context.Context, userID int, req RecurringRuleRequest) (*domain.RecurringRule, error) {
+	rule := &domain.RecurringRule{UserID: userID, Active: true}
+	if err := s.applyRequest(ctx, rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.recurringRepo.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create recurring rule: %w", err)
+	}
+
+	return withNextOccurrence(rule), nil
+}
+
+func (s *RecurringService) GetUserRules(ctx context.Context, userID int) ([]domain.RecurringRule, error) {
+	rules, err := s.recurringRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recurring rules: %w", err)
+	}
+
+	for i := range rules {
+		withNextOccurrence(&rules[i])
+	}
+	return rules, nil
+}
+
+func (s *RecurringService) GetRule(ctx context.Context, ruleID int, userID int) (*domain.RecurringRule, error) {
+	rule, err := s.recurringRepo.FindByID(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule.UserID != userID {
+		return nil, domain.Forbidden("recurring rule")
+	}
+
+	return withNextOccurrence(rule), nil
+}
+
+func (s *RecurringService) UpdateRule(ctx context.Context, ruleID int, userID int, req RecurringRuleRequest) (*domain.RecurringRule, error) {
+	rule, err := s.GetRule(ctx, ruleID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRequest(ctx, rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.recurringRepo.Update(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to update recurring rule: %w", err)
+	}
+
+	return withNextOccurrence(rule), nil
+}
+
+func (s *RecurringService) DeleteRule(ctx context.Context, ruleID int, userID int) error {
+	if _, err := s.GetRule(ctx, ruleID, userID); err != nil {
+		return err
+	}
+
+	// Transactions that were already generated are kept
+	if err := s.recurringRepo.Delete(ctx, ruleID); err != nil {
+		return fmt.Errorf("failed to delete recurring rule: %w", err)
+	}
+
+	return nil
+}
+
+// GetOccurrences lists the dates the rule fired on, including the skipped ones
+func (s *RecurringService) GetOccurrences(ctx context.Context, ruleID int, userID int) ([]domain.RecurringOccurrence, error) {
+	if _, err := s.GetRule(ctx, ruleID, userID); err != nil {
+		return nil, err
+	}
+
+	occurrences, err := s.recurringRepo.FindOccurrences(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	return occurrences, nil
+}
+
+// RunDue generates every occurrence that is due up to and including today.
+// It implements ScheduledJob.
+func (s *RecurringService) RunDue(ctx context.Context, now time.Time) {
+	rules, err := s.recurringRepo.FindActive(ctx)
+	if err != nil {
+		log.Printf("Recurring: failed to fetch rules: %v", err)
+		return
+	}
+
+	today := dateOf(now)
+	for i := range rules {
+		// Stop early on shutdown; the rest is picked up on the next run
+		if ctx.Err() != nil {
+			return
+		}
+		s.generateOccurrences(ctx, &rules[i], today)
+	}
+}
+
+func (s *RecurringService) generateOccurrences(ctx context.Context, rule *domain.RecurringRule, today time.Time) {
+	after := dateOf(rule.StartDate).AddDate(0, 0, -1)
+	if rule.LastOccurrence != nil {
+		after = dateOf(*rule.LastOccurrence)
+	}
+	count := rule.OccurrenceCount
+
+	for i := 0; i < maxCatchUpOccurrences; i++ {
+		if rule.MaxOccurrences != nil && count >= *rule.MaxOccurrences {
+			return
+		}
+
+		date, ok := nextOccurrence(rule, after)
+		if !ok || date.After(today) {
+			return
+		}
+
+		// The claim and the transaction are committed together, so an occurrence
+		// is either fully generated or retried on the next run
+		created := false
+		err := s.uow.Do(ctx, func(repos domain.Repositories) error {
+			created = false
+			claimed, err := repos.Recurring.ClaimOccurrence(ctx, rule.ID, date)
+			if err != nil || !claimed {
+				return err
+			}
+
+			transaction, err := s.transactionService.createTransaction(ctx, repos, rule.UserID, CreateTransactionRequest{
+				WalletID:    rule.WalletID,
+				Type:        rule.Type,
+				Amount:      rule.Amount,
+				Category:    rule.Category,
+				Description: rule.Description,
+				Date:        date.Format("2006-01-02"),
+				ToWalletID:  rule.ToWalletID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := repos.Recurring.SetOccurrenceTransaction(ctx, rule.ID, date, transaction.ID); err != nil {
+				return err
+			}
+			created = true
+			return nil
+		})
+		if err != nil {
+			if !isPermanentFailure(err) {
+				// Retried on the next run; later occurrences wait so that they
+				// are generated in order
+				log.Printf("Recurring: rule %d failed on %s: %v", rule.ID, date.Format("2006-01-02"), err)
+				return
+			}
+
+			// The same error would come back on every run, so the occurrence
+			// is skipped with its reason for the user to see
+			if err := s.recurringRepo.SkipOccurrence(ctx, rule.ID, date, err.Error()); err != nil {
+				log.Printf("Recurring: rule %d failed on %s: %v", rule.ID, date.Format("2006-01-02"), err)
+				return
+			}
+			log.Printf("Recurring: rule %d skipped %s: %v", rule.ID, date.Format("2006-01-02"), err)
+			after = date
+			continue
+		}
+
+		// An occurrence claimed by another run is already counted in the rule
+		after = date
+		if created {
+			count++
+		}
+	}
+}
+
+// isPermanentFailure reports whether err comes back on every retry, such as a
+// deleted wallet or a rule that is no longer valid. An insufficient balance or
+// a conflict depends on the state at the time and is not permanent, and
+// neither is the database being unavailable.
+func isPermanentFailure(err error) bool {
+	return errors.Is(err, domain.ErrNotFound) ||
+		errors.Is(err, domain.ErrForbidden) ||
+		errors.Is(err, domain.ErrValidation)
+}
+
+// applyRequest validates the request and copies it onto the rule
+func (s *RecurringService) applyRequest(ctx context.Context, rule *domain.RecurringRule, req RecurringRuleRequest) error {
+	if !req.Amount.IsPositive() {
+		return domain.Invalid("amount must be greater than zero")
+	}
+
+	transaction := &domain.Transaction{
+		WalletID:   req.WalletID,
+		Type:       req.Type,
+		Amount:     req.Amount,
+		ToWalletID: req.ToWalletID,
+	}
+	if transaction.Type != domain.TransactionTypeTransfer {
+		transaction.ToWalletID = nil
+	}
+	if err := validateTransactionWallets(ctx, s.walletRepo, rule.UserID, transaction, nil); err != nil {
+		return err
+	}
+
+	startDate := dateOf(time.Now())
+	if req.StartDate != "" {
+		var err error
+		startDate, err = time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return domain.Invalid("invalid start date format (use YYYY-MM-DD)")
+		}
+	}
+
+	var endDate *time.Time
+	if req.EndDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return domain.Invalid("invalid end date format (use YYYY-MM-DD)")
+		}
+		if parsed.Before(startDate) {
+			return domain.Invalid("end date must not be before start date")
+		}
+		endDate = &parsed
+	}
+
+	if req.MaxOccurrences != nil && *req.MaxOccurrences <= 0 {
+		return domain.Invalid("max occurrences must be greater than zero")
+	}
+
+	rule.DayOfWeek = nil
+	rule.DayOfMonth = nil
+	rule.CronExpression = ""
+
+	switch req.Frequency {
+	case domain.RecurrenceDaily:
+		// Fires every day
+
+	case domain.RecurrenceWeekly:
+		dayOfWeek := int(startDate.Weekday())
+		if req.DayOfWeek != nil {
+			dayOfWeek = *req.DayOfWeek
+		}
+		if dayOfWeek < 0 || dayOfWeek > 6 {
+			return domain.Invalid("day of week must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		rule.DayOfWeek = &dayOfWeek
+
+	case domain.RecurrenceMonthly:
+		dayOfMonth := startDate.Day()
+		if req.DayOfMonth != nil {
+			dayOfMonth = *req.DayOfMonth
+		}
+		if dayOfMonth < 1 || dayOfMonth > 31 {
+			return domain.Invalid("day of month must be between 1 and 31")
+		}
+		rule.DayOfMonth = &dayOfMonth
+
+	case domain.RecurrenceCron:
+		if _, err := parseCron(req.CronExpression); err != nil {
+			return domain.Invalid("%v", err)
+		}
+		rule.CronExpression = req.CronExpression
+
+	default:
+		return domain.Invalid("invalid frequency (use daily, weekly, monthly or cron)")
+	}
+
+	rule.WalletID = req.WalletID
+	rule.ToWalletID = transaction.ToWalletID
+	rule.Type = req.Type
+	rule.Amount = transaction.Amount
+	rule.Category = req.Category
+	rule.Description = req.Description
+	rule.Frequency = req.Frequency
+	rule.StartDate = startDate
+	rule.EndDate = endDate
+	rule.MaxOccurrences = req.MaxOccurrences
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+
+	return nil
+}
+
+// withNextOccurrence fills in the next date the rule will generate a transaction
+func withNextOccurrence(rule *domain.RecurringRule) *domain.RecurringRule {
+	rule.NextOccurrence = nil
+	if !rule.Active {
+		return rule
+	}
+	if rule.MaxOccurrences != nil && rule.OccurrenceCount >= *rule.MaxOccurrences {
+		return rule
+	}
+
+	after := dateOf(rule.StartDate).AddDate(0, 0, -1)
+	if rule.LastOccurrence != nil {
+		after = dateOf(*rule.LastOccurrence)
+	}
+	if next, ok := nextOccurrence(rule, after); ok {
+		rule.NextOccurrence = &next
+	}
+
+	return rule
+}
+
+// nextOccurrence returns the first day strictly after the given date on which
+// the rule fires, or false if the rule has ended
+func nextOccurrence(rule *domain.RecurringRule, after time.Time) (time.Time, bool) {
+	var cron *cronSchedule
+	if rule.Frequency == domain.RecurrenceCron {
+		var err error
+		cron, err = parseCron(rule.CronExpression)
+		if err != nil {
+			return time.Time{}, false
+		}
+	}
+
+	day := dateOf(after).AddDate(0, 0, 1)
+	if start := dateOf(rule.StartDate); day.Before(start) {
+		day = start
+	}
+
+	for i := 0; i < maxScheduleDays; i++ {
+		if rule.EndDate != nil && day.After(dateOf(*rule.EndDate)) {
+			return time.Time{}, false
+		}
+		if occursOn(rule, cron, day) {
+			return day, true
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return time.Time{}, false
+}
+
+func occursOn(rule *domain.RecurringRule, cron *cronSchedule, day time.Time) bool {
+	switch rule.Frequency {
+	case domain.RecurrenceDaily:
+		return true
+
+	case domain.RecurrenceWeekly:
+		weekday := rule.StartDate.Weekday()
+		if rule.DayOfWeek != nil {
+			weekday = time.Weekday(*rule.DayOfWeek)
+		}
+		return day.Weekday() == weekday
+
+	case domain.RecurrenceMonthly:
+		dayOfMonth := rule.StartDate.Day()
+		if rule.DayOfMonth != nil {
+			dayOfMonth = *rule.DayOfMonth
+		}
+		// A rule for the 31st falls on the last day of shorter months
+		if last := daysInMonth(day); dayOfMonth > last {
+			dayOfMonth = last
+		}
+		return day.Day() == dayOfMonth
+
+	case domain.RecurrenceCron:
+		return cron != nil && cron.matches(day)
+	}
+
+	return false
+}
+
+// dateOf strips the time of day, keeping the calendar date
+func dateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
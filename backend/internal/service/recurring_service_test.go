@@ -0,0 +1,131 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	day := func(value string) time.Time { return mustDate(t, value) }
+	intPtr := func(value int) *int { return &value }
+	timePtr := func(value time.Time) *time.Time { return &value }
+
+	tests := []struct {
+		name  string
+		rule  domain.RecurringRule
+		after string
+		want  string // Empty when there is no next occurrence
+	}{
+		{
+			name:  "daily",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceDaily, StartDate: day("2024-01-01")},
+			after: "2024-01-31",
+			want:  "2024-02-01",
+		},
+		{
+			name:  "not before the start date",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceDaily, StartDate: day("2024-03-10")},
+			after: "2024-01-01",
+			want:  "2024-03-10",
+		},
+		{
+			name:  "past the end date",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceDaily, StartDate: day("2024-01-01"), EndDate: timePtr(day("2024-01-05"))},
+			after: "2024-01-05",
+		},
+		{
+			name:  "weekly on the start weekday",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceWeekly, StartDate: day("2024-01-03")},
+			after: "2024-01-03",
+			want:  "2024-01-10",
+		},
+		{
+			name:  "weekly on monday",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceWeekly, StartDate: day("2024-01-03"), DayOfWeek: intPtr(1)},
+			after: "2024-01-03",
+			want:  "2024-01-08",
+		},
+		{
+			name:  "monthly on the start day",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceMonthly, StartDate: day("2024-01-15")},
+			after: "2024-01-15",
+			want:  "2024-02-15",
+		},
+
+		// A rule for a day a month does not have falls on its last day
+		{
+			name:  "day 31 in a leap february",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceMonthly, StartDate: day("2024-01-31"), DayOfMonth: intPtr(31)},
+			after: "2024-01-31",
+			want:  "2024-02-29",
+		},
+		{
+			name:  "day 31 in february",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceMonthly, StartDate: day("2023-01-31"), DayOfMonth: intPtr(31)},
+			after: "2023-01-31",
+			want:  "2023-02-28",
+		},
+		{
+			name:  "day 31 back in march",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceMonthly, StartDate: day("2024-01-31"), DayOfMonth: intPtr(31)},
+			after: "2024-02-29",
+			want:  "2024-03-31",
+		},
+		{
+			name:  "day 31 in april",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceMonthly, StartDate: day("2024-01-31"), DayOfMonth: intPtr(31)},
+			after: "2024-03-31",
+			want:  "2024-04-30",
+		},
+		{
+			name:  "start day 30 in february",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceMonthly, StartDate: day("2024-01-30")},
+			after: "2024-01-30",
+			want:  "2024-02-29",
+		},
+
+		// Cron has no such clamping: the 31st skips the shorter months
+		{
+			name:  "cron on the 31st",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceCron, CronExpression: "0 0 31 * *", StartDate: day("2024-01-01")},
+			after: "2024-03-31",
+			want:  "2024-05-31",
+		},
+		{
+			name:  "cron on weekdays",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceCron, CronExpression: "0 9 * * 1-5", StartDate: day("2024-01-01")},
+			after: "2024-09-06",
+			want:  "2024-09-09",
+		},
+		{
+			name:  "invalid cron",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceCron, CronExpression: "0 0 32 * *", StartDate: day("2024-01-01")},
+			after: "2024-01-01",
+		},
+		{
+			name:  "cron that never fires",
+			rule:  domain.RecurringRule{Frequency: domain.RecurrenceCron, CronExpression: "0 0 30 2 *", StartDate: day("2024-01-01")},
+			after: "2024-01-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := nextOccurrence(&tt.rule, day(tt.after))
+			if tt.want == "" {
+				if ok {
+					t.Fatalf("got %s, want no occurrence", got.Format("2006-01-02"))
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("got no occurrence, want %s", tt.want)
+			}
+			if !got.Equal(day(tt.want)) {
+				t.Fatalf("got %s, want %s", got.Format("2006-01-02"), tt.want)
+			}
+		})
+	}
+}
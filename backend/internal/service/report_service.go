@@ -1,71 +1,419 @@
 package service
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
 	"time"
 
 	"go-moneyku/internal/domain"
+	"go-moneyku/internal/exporter"
 )
 
 type ReportService struct {
-	transactionRepo domain.TransactionRepository
-	walletRepo      domain.WalletRepository
+	transactionRepo     domain.TransactionRepository
+	walletRepo          domain.WalletRepository
+	categoryRepo        domain.CategoryRepository
+	ledgerRepo          domain.LedgerRepository
+	exchangeRateService *ExchangeRateService
+	exportTimeout       time.Duration    // Bounds streaming an export; zero leaves only the request deadline
+	now                 func() time.Time // Time printed on statements and exports; replaced in tests
 }
 
-func NewReportService(transactionRepo domain.TransactionRepository, walletRepo domain.WalletRepository) *ReportService {
+func NewReportService(transactionRepo domain.TransactionRepository, walletRepo domain.WalletRepository, categoryRepo domain.CategoryRepository, ledgerRepo domain.LedgerRepository, exchangeRateService *ExchangeRateService, exportTimeout time.Duration) *ReportService {
 	return &ReportService{
-		transactionRepo: transactionRepo,
-		walletRepo:      walletRepo,
+		transactionRepo:     transactionRepo,
+		walletRepo:          walletRepo,
+		categoryRepo:        categoryRepo,
+		ledgerRepo:          ledgerRepo,
+		exchangeRateService: exchangeRateService,
+		exportTimeout:       exportTimeout,
+		now:                 time.Now,
 	}
 }
 
 type ReportData struct {
-	Transactions []domain.Transaction `json:"transactions"`
-	Summary      ReportSummary        `json:"summary"`
+	Transactions       []domain.Transaction `json:"transactions"`
+	Summary            ReportSummary        `json:"summary"`
+	SpendingByCategory []SpendingByCategory `json:"spending_by_category"`
 }
 
+// ReportSummary holds totals in the user's base currency, each transaction
+// converted at the rate of its date
 type ReportSummary struct {
-	TotalIncome      float64 `json:"total_income"`
-	TotalExpense     float64 `json:"total_expense"`
-	NetIncome        float64 `json:"net_income"`
-	TransactionCount int     `json:"transaction_count"`
+	BaseCurrency string       `json:"base_currency"`
+	TotalIncome  domain.Money `json:"total_income"`
+	TotalExpense domain.Money `json:"total_expense"`
+	NetIncome    domain.Money `json:"net_income"`
+	// FXDifference is the realised gain (positive) or loss (negative) of
+	// transfers between currencies: what was received less what was sent, both
+	// at the rates of the transfer date
+	FXDifference     domain.Money `json:"fx_difference"`
+	TransactionCount int          `json:"transaction_count"`
+	// MissingRates lists the currency pairs without a rate; amounts in those
+	// currencies are left out of the totals
+	MissingRates []string `json:"missing_rates,omitempty"`
 }
 
-func (s *ReportService) GetTransactionReport(userID int, startDate, endDate time.Time) (*ReportData, error) {
+func (s *ReportService) GetTransactionReport(ctx context.Context, userID int, startDate, endDate time.Time) (*ReportData, error) {
 	// Get transactions in date range
-	transactions, err := s.transactionRepo.FindByDateRange(userID, startDate, endDate)
+	transactions, err := s.transactionRepo.FindByDateRange(ctx, userID, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
 	}
 
+	wallets, err := s.walletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wallets: %w", err)
+	}
+
+	// The listed transactions keep their own amounts; totals use converted copies
+	converter, err := s.exchangeRateService.converter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	currencies := walletCurrencies(wallets)
+	converted, err := converter.convertTransactions(ctx, transactions, currencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert transactions: %w", err)
+	}
+	fxDifference, err := realisedFXDifference(ctx, converter, converted, currencies)
+	if err != nil {
+		return nil, err
+	}
+
 	// Calculate summary
-	var totalIncome, totalExpense float64
-	for _, transaction := range transactions {
+	totalIncome := domain.NewMoney(0, converter.base)
+	totalExpense := domain.NewMoney(0, converter.base)
+	for _, transaction := range converted {
 		switch transaction.Type {
 		case domain.TransactionTypeIncome:
-			totalIncome += transaction.Amount
+			totalIncome = totalIncome.Add(transaction.Amount)
 		case domain.TransactionTypeExpense:
-			totalExpense += transaction.Amount
+			totalExpense = totalExpense.Add(transaction.Amount)
 		}
 	}
 
 	summary := ReportSummary{
+		BaseCurrency:     converter.base,
 		TotalIncome:      totalIncome,
 		TotalExpense:     totalExpense,
-		NetIncome:        totalIncome - totalExpense,
+		NetIncome:        totalIncome.Sub(totalExpense),
+		FXDifference:     fxDifference,
 		TransactionCount: len(transactions),
+		MissingRates:     converter.missingRates(),
+	}
+
+	categories, err := s.categoryRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
 	}
 
 	return &ReportData{
-		Transactions: transactions,
-		Summary:      summary,
+		Transactions:       transactions,
+		Summary:            summary,
+		SpendingByCategory: rollUpSpending(converted, categories),
 	}, nil
 }
 
-func (s *ReportService) ExportTransactions(userID int) ([]domain.Transaction, error) {
-	transactions, err := s.transactionRepo.FindByUserID(userID)
+// realisedFXDifference sums, over the transfers between currencies, the
+// amount received less the amount sent, in the base currency. The
+// transactions must already be converted.
+func realisedFXDifference(ctx context.Context, converter *currencyConverter, transactions []domain.Transaction, walletCurrencies map[int]string) (domain.Money, error) {
+	total := domain.NewMoney(0, converter.base)
+	for _, transaction := range transactions {
+		if transaction.Type != domain.TransactionTypeTransfer || transaction.ToAmount == nil || transaction.ToWalletID == nil {
+			continue
+		}
+		received, err := converter.convert(ctx, *transaction.ToAmount, walletCurrencies[*transaction.ToWalletID], transaction.Date)
+		if err != nil {
+			return domain.Money{}, fmt.Errorf("failed to convert transfer: %w", err)
+		}
+
+		// Without both rates the difference would be the whole amount
+		sentKnown, err := converter.canConvert(ctx, walletCurrencies[transaction.WalletID], transaction.Date)
+		if err != nil {
+			return domain.Money{}, err
+		}
+		receivedKnown, err := converter.canConvert(ctx, walletCurrencies[*transaction.ToWalletID], transaction.Date)
+		if err != nil {
+			return domain.Money{}, err
+		}
+		if sentKnown && receivedKnown {
+			total = total.Add(received.Sub(transaction.Amount))
+		}
+	}
+	return total, nil
+}
+
+// WalletStatement is the monthly statement of one wallet, built from the
+// ledger so that adjustments are listed as well and the running balance
+// always ends at the closing balance
+type WalletStatement struct {
+	Wallet         *domain.Wallet           `json:"wallet"`
+	PeriodStart    time.Time                `json:"period_start"`
+	PeriodEnd      time.Time                `json:"period_end"` // Exclusive
+	OpeningBalance domain.Money             `json:"opening_balance"`
+	ClosingBalance domain.Money             `json:"closing_balance"`
+	TotalIn        domain.Money             `json:"total_in"`
+	TotalOut       domain.Money             `json:"total_out"`
+	Lines          []StatementLine          `json:"lines"`
+	CategoryTotals []StatementCategoryTotal `json:"category_totals"`
+	GeneratedAt    time.Time                `json:"generated_at"`
+}
+
+// StatementLine is one movement of the wallet balance
+type StatementLine struct {
+	Date        time.Time    `json:"date"`
+	Description string       `json:"description"`
+	Category    string       `json:"category"`
+	Amount      domain.Money `json:"amount"`  // Positive into the wallet, negative out of it
+	Balance     domain.Money `json:"balance"` // Running balance after this line
+}
+
+// StatementCategoryTotal sums the lines of one category in one direction
+type StatementCategoryTotal struct {
+	Category string       `json:"category"`
+	Incoming bool         `json:"incoming"`
+	Count    int          `json:"count"`
+	Amount   domain.Money `json:"amount"` // Always positive
+}
+
+// Labels of statement lines that do not come from a categorized transaction
+const (
+	statementTransferCategory   = "Transfer"
+	statementAdjustmentCategory = "Penyesuaian saldo"
+	statementUncategorized      = "Tanpa kategori"
+)
+
+// GetWalletStatement builds the statement of a wallet for the calendar month
+// that contains month
+func (s *ReportService) GetWalletStatement(ctx context.Context, userID int, walletID int, month time.Time) (*WalletStatement, error) {
+	wallet, err := findOwnedWallet(ctx, s.walletRepo, walletID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
+		return nil, err
+	}
+
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	opening, err := s.ledgerRepo.WalletBalanceAt(ctx, wallet.ID, start)
+	if err != nil {
+		return nil, err
+	}
+	postings, err := s.ledgerRepo.FindWalletPostings(ctx, wallet.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &WalletStatement{
+		Wallet:         wallet,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		OpeningBalance: opening.WithCurrency(wallet.Currency),
+		TotalIn:        domain.NewMoney(0, wallet.Currency),
+		TotalOut:       domain.NewMoney(0, wallet.Currency),
+		Lines:          make([]StatementLine, 0, len(postings)),
+		GeneratedAt:    s.now(),
+	}
+
+	type totalKey struct {
+		category string
+		incoming bool
+	}
+	totals := make(map[totalKey]*StatementCategoryTotal)
+
+	balance := statement.OpeningBalance
+	for _, posting := range postings {
+		amount := posting.Amount.WithCurrency(wallet.Currency)
+		balance = balance.Add(amount)
+
+		line := StatementLine{
+			Date:        posting.Date,
+			Description: posting.Memo,
+			Category:    statementCategory(posting),
+			Amount:      amount,
+			Balance:     balance,
+		}
+		if posting.Kind == domain.JournalEntryTransfer && posting.OtherWallet != "" {
+			direction := "ke"
+			if amount.IsPositive() {
+				direction = "dari"
+			}
+			line.Description = joinNonEmpty(line.Description, fmt.Sprintf("Transfer %s %s", direction, posting.OtherWallet))
+		}
+		statement.Lines = append(statement.Lines, line)
+
+		incoming := amount.IsPositive()
+		if incoming {
+			statement.TotalIn = statement.TotalIn.Add(amount)
+		} else {
+			statement.TotalOut = statement.TotalOut.Sub(amount)
+		}
+
+		key := totalKey{line.Category, incoming}
+		total, ok := totals[key]
+		if !ok {
+			total = &StatementCategoryTotal{Category: line.Category, Incoming: incoming, Amount: domain.NewMoney(0, wallet.Currency)}
+			totals[key] = total
+		}
+		total.Count++
+		if incoming {
+			total.Amount = total.Amount.Add(amount)
+		} else {
+			total.Amount = total.Amount.Sub(amount)
+		}
+	}
+	statement.ClosingBalance = balance
+
+	// Money out first, largest first, as that is what a statement is read for
+	statement.CategoryTotals = make([]StatementCategoryTotal, 0, len(totals))
+	for _, total := range totals {
+		statement.CategoryTotals = append(statement.CategoryTotals, *total)
+	}
+	sort.Slice(statement.CategoryTotals, func(i, j int) bool {
+		a, b := statement.CategoryTotals[i], statement.CategoryTotals[j]
+		if a.Incoming != b.Incoming {
+			return !a.Incoming
+		}
+		if c := a.Amount.Cmp(b.Amount); c != 0 {
+			return c > 0
+		}
+		return a.Category < b.Category
+	})
+
+	return statement, nil
+}
+
+func statementCategory(posting domain.WalletPosting) string {
+	switch {
+	case posting.Kind == domain.JournalEntryTransfer:
+		return statementTransferCategory
+	case posting.Kind == domain.JournalEntryAdjustment:
+		return statementAdjustmentCategory
+	case posting.Category == "":
+		return statementUncategorized
+	default:
+		return posting.Category
+	}
+}
+
+func joinNonEmpty(first, second string) string {
+	switch {
+	case first == "":
+		return second
+	case second == "":
+		return first
+	default:
+		return first + " - " + second
+	}
+}
+
+// Export is a checked export request. Nothing is read from the database
+// until Stream is called, so the response headers can be sent first.
+type Export struct {
+	ContentType string
+	Filename    string
+	stream      func(ctx context.Context, w io.Writer) error
+}
+
+// Stream writes the export file to w, one transaction at a time. Cancelling
+// ctx stops the export.
+func (e *Export) Stream(ctx context.Context, w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+	if err := e.stream(ctx, buffered); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// ExportTransactions prepares an export of the transactions matching the
+// filter in one of exporter.Formats(). A category filter includes the
+// subcategories, as reports do.
+func (s *ReportService) ExportTransactions(ctx context.Context, userID int, format string, filter domain.TransactionFilter) (*Export, error) {
+	fileExporter, ok := exporter.ForFormat(format)
+	if !ok {
+		return nil, domain.Invalid("unsupported format %q (use one of: %s)", format, strings.Join(exporter.Formats(), ", "))
+	}
+	filter.UserID = userID
+
+	if len(filter.CategoryIDs) > 0 {
+		categories, err := s.categoryRepo.FindByUserID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch categories: %w", err)
+		}
+		var categoryIDs []int
+		for _, categoryID := range filter.CategoryIDs {
+			categoryIDs = append(categoryIDs, categoryTreeIDs(categories, categoryID)...)
+		}
+		filter.CategoryIDs = categoryIDs
+	}
+
+	statement := exporter.Statement{
+		StartDate:   filter.StartDate,
+		EndDate:     filter.EndDate,
+		GeneratedAt: s.now(),
+	}
+	if fileExporter.PerWallet() {
+		if len(filter.WalletIDs) != 1 {
+			return nil, domain.Invalid("the %s format describes one account; choose exactly one wallet_id", format)
+		}
+		wallet, err := findOwnedWallet(ctx, s.walletRepo, filter.WalletIDs[0], userID)
+		if err != nil {
+			return nil, err
+		}
+		statement.Wallet = wallet
+
+		closingBalance := wallet.Balance
+		if filter.EndDate != nil {
+			closingBalance, err = s.ledgerRepo.WalletBalanceAt(ctx, wallet.ID, *filter.EndDate)
+			if err != nil {
+				return nil, err
+			}
+		}
+		statement.ClosingBalance = &closingBalance
+	}
+
+	return &Export{
+		ContentType: fileExporter.ContentType(),
+		Filename:    exportFilename(statement, fileExporter.Extension()),
+		stream: func(ctx context.Context, w io.Writer) error {
+			if s.exportTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, s.exportTimeout)
+				defer cancel()
+			}
+
+			writer, err := fileExporter.NewWriter(w, statement)
+			if err != nil {
+				return err
+			}
+			if err := s.transactionRepo.StreamExport(ctx, filter, writer.Write); err != nil {
+				return err
+			}
+			return writer.Close()
+		},
+	}, nil
+}
+
+// exportFilename names the file after the wallet and the date range
+func exportFilename(statement exporter.Statement, extension string) string {
+	name := "transactions"
+	if statement.Wallet != nil {
+		name += fmt.Sprintf("-wallet-%d", statement.Wallet.ID)
+	}
+	switch {
+	case statement.StartDate != nil && statement.EndDate != nil:
+		name += "-" + statement.StartDate.Format("20060102") + "-" + statement.EndDate.AddDate(0, 0, -1).Format("20060102")
+	case statement.StartDate != nil:
+		name += "-from-" + statement.StartDate.Format("20060102")
+	case statement.EndDate != nil:
+		name += "-until-" + statement.EndDate.AddDate(0, 0, -1).Format("20060102")
+	default:
+		name += "-" + statement.GeneratedAt.Format("20060102")
 	}
-	return transactions, nil
+	return name + "." + extension
 }
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ScheduledJob is periodic background work, such as generating recurring transactions
+type ScheduledJob interface {
+	RunDue(ctx context.Context, now time.Time)
+}
+
+// Scheduler runs its jobs once at startup and then on every tick of the interval
+type Scheduler struct {
+	interval time.Duration
+	jobs     []ScheduledJob
+}
+
+func NewScheduler(interval time.Duration, jobs ...ScheduledJob) *Scheduler {
+	return &Scheduler{
+		interval: interval,
+		jobs:     jobs,
+	}
+}
+
+// Run blocks until ctx is cancelled, which also cancels the jobs in flight
+func (s *Scheduler) Run(ctx context.Context) {
+	log.Printf("Scheduler started, running every %s", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.runJobs(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Scheduler stopped")
+			return
+		case <-ticker.C:
+			s.runJobs(ctx)
+		}
+	}
+}
+
+func (s *Scheduler) runJobs(ctx context.Context) {
+	now := time.Now()
+	for _, job := range s.jobs {
+		job.RunDue(ctx, now)
+	}
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+	"go-moneyku/internal/pdf"
+)
+
+// Layout of the statement in points on an A4 page
+const (
+	statementMargin   = 40.0
+	statementRight    = pdf.A4Width - statementMargin
+	statementBottom   = pdf.A4Height - 60
+	statementRowSize  = 14.0
+	statementFontSize = 8.0
+)
+
+// statementColumns are the left edges of the text columns and the right edges
+// of the amount columns of the transaction table
+var statementColumns = struct {
+	date, description, category float64
+	in, out, balance            float64
+}{
+	date:        statementMargin,
+	description: statementMargin + 52,
+	category:    statementMargin + 222,
+	in:          statementMargin + 378,
+	out:         statementMargin + 446,
+	balance:     statementRight,
+}
+
+var indonesianMonths = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// statementRenderer lays out the statement, starting new pages as the table
+// grows
+type statementRenderer struct {
+	statement *WalletStatement
+	document  *pdf.Document
+	page      *pdf.Page
+	y         float64
+}
+
+// WritePDF renders the statement as a printable A4 PDF
+func (st *WalletStatement) WritePDF(w io.Writer) error {
+	r := &statementRenderer{statement: st, document: pdf.New(pdf.A4Width, pdf.A4Height)}
+	r.header()
+	r.summary()
+	r.transactions()
+	r.categoryTotals()
+	r.footers()
+
+	if _, err := r.document.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+func (r *statementRenderer) header() {
+	st := r.statement
+	r.page = r.document.AddPage()
+
+	r.page.Text(statementMargin, 60, pdf.HelveticaBold, 16, "Laporan Bulanan Dompet")
+	r.page.Text(statementMargin, 80, pdf.Helvetica, 11, st.Wallet.Name)
+	r.page.TextRight(statementRight, 60, pdf.Helvetica, 9,
+		"Periode "+formatIndonesianDate(st.PeriodStart)+" - "+formatIndonesianDate(st.PeriodEnd.AddDate(0, 0, -1)))
+	r.page.TextRight(statementRight, 74, pdf.Helvetica, 9, "Mata uang "+st.Wallet.Currency)
+	r.page.TextRight(statementRight, 88, pdf.Helvetica, 9,
+		"Dicetak "+formatIndonesianDate(st.GeneratedAt)+" "+st.GeneratedAt.Format("15:04"))
+	r.page.Line(statementMargin, 98, statementRight, 98, 1)
+	r.y = 98
+}
+
+func (r *statementRenderer) summary() {
+	st := r.statement
+	boxes := []struct {
+		label  string
+		amount domain.Money
+	}{
+		{"Saldo awal", st.OpeningBalance},
+		{"Total masuk", st.TotalIn},
+		{"Total keluar", st.TotalOut},
+		{"Saldo akhir", st.ClosingBalance},
+	}
+
+	width := (statementRight - statementMargin) / float64(len(boxes))
+	top := r.y + 12
+	for i, box := range boxes {
+		x := statementMargin + float64(i)*width
+		r.page.FillRect(x+2, top, width-4, 38, 0.93)
+		r.page.Text(x+10, top+14, pdf.Helvetica, 8, box.label)
+		r.page.Text(x+10, top+30, pdf.HelveticaBold, 11, formatRupiah(box.amount))
+	}
+	r.y = top + 38 + 24
+}
+
+func (r *statementRenderer) transactions() {
+	st := r.statement
+	r.sectionTitle("Mutasi")
+	r.tableHeader()
+
+	r.row(pdf.HelveticaBold, formatShortDate(st.PeriodStart), "Saldo awal", "", nil, st.OpeningBalance)
+	for _, line := range st.Lines {
+		r.row(pdf.Helvetica, formatShortDate(line.Date), line.Description, line.Category, &line.Amount, line.Balance)
+	}
+	if len(st.Lines) == 0 {
+		r.ensureSpace(statementRowSize)
+		r.page.Text(statementColumns.description, r.y+statementRowSize-4, pdf.Helvetica, statementFontSize, "Tidak ada mutasi pada periode ini")
+		r.y += statementRowSize
+	}
+
+	// The closing row carries the totals of the two amount columns
+	r.ensureSpace(statementRowSize + 4)
+	r.page.Line(statementMargin, r.y+2, statementRight, r.y+2, 0.5)
+	r.y += 4
+	baseline := r.y + statementRowSize - 4
+	r.page.Text(statementColumns.date, baseline, pdf.HelveticaBold, statementFontSize, formatShortDate(st.PeriodEnd.AddDate(0, 0, -1)))
+	r.page.Text(statementColumns.description, baseline, pdf.HelveticaBold, statementFontSize, "Saldo akhir")
+	r.page.TextRight(statementColumns.in, baseline, pdf.HelveticaBold, statementFontSize, formatAmount(st.TotalIn))
+	r.page.TextRight(statementColumns.out, baseline, pdf.HelveticaBold, statementFontSize, formatAmount(st.TotalOut))
+	r.page.TextRight(statementColumns.balance, baseline, pdf.HelveticaBold, statementFontSize, formatAmount(st.ClosingBalance))
+	r.y += statementRowSize + 20
+}
+
+func (r *statementRenderer) categoryTotals() {
+	st := r.statement
+	if len(st.CategoryTotals) == 0 {
+		return
+	}
+
+	r.ensureSpace(3 * statementRowSize)
+	r.sectionTitle("Total per kategori")
+	r.categoryHeader()
+
+	for _, total := range st.CategoryTotals {
+		if r.ensureSpace(statementRowSize) {
+			r.categoryHeader()
+		}
+		direction := "Keluar"
+		if total.Incoming {
+			direction = "Masuk"
+		}
+		baseline := r.y + statementRowSize - 4
+		r.page.Text(statementColumns.date, baseline, pdf.Helvetica, statementFontSize,
+			pdf.Truncate(pdf.Helvetica, statementFontSize, total.Category, statementColumns.category-statementColumns.date-8))
+		r.page.Text(statementColumns.category, baseline, pdf.Helvetica, statementFontSize, direction)
+		r.page.TextRight(statementColumns.out, baseline, pdf.Helvetica, statementFontSize, fmt.Sprintf("%d transaksi", total.Count))
+		r.page.TextRight(statementColumns.balance, baseline, pdf.Helvetica, statementFontSize, formatAmount(total.Amount))
+		r.y += statementRowSize
+	}
+}
+
+// footers numbers the pages once their count is known
+func (r *statementRenderer) footers() {
+	pages := r.document.Pages()
+	for i, page := range pages {
+		page.Line(statementMargin, pdf.A4Height-40, statementRight, pdf.A4Height-40, 0.5)
+		page.Text(statementMargin, pdf.A4Height-28, pdf.Helvetica, 7, "Moneyku - "+r.statement.Wallet.Name)
+		page.TextRight(statementRight, pdf.A4Height-28, pdf.Helvetica, 7, fmt.Sprintf("Halaman %d dari %d", i+1, len(pages)))
+	}
+}
+
+func (r *statementRenderer) sectionTitle(title string) {
+	r.page.Text(statementMargin, r.y+10, pdf.HelveticaBold, 11, title)
+	r.y += 18
+}
+
+func (r *statementRenderer) tableHeader() {
+	r.page.FillRect(statementMargin, r.y, statementRight-statementMargin, statementRowSize+2, 0.85)
+	baseline := r.y + statementRowSize - 3
+	r.page.Text(statementColumns.date, baseline, pdf.HelveticaBold, statementFontSize, "Tanggal")
+	r.page.Text(statementColumns.description, baseline, pdf.HelveticaBold, statementFontSize, "Keterangan")
+	r.page.Text(statementColumns.category, baseline, pdf.HelveticaBold, statementFontSize, "Kategori")
+	r.page.TextRight(statementColumns.in, baseline, pdf.HelveticaBold, statementFontSize, "Masuk")
+	r.page.TextRight(statementColumns.out, baseline, pdf.HelveticaBold, statementFontSize, "Keluar")
+	r.page.TextRight(statementColumns.balance, baseline, pdf.HelveticaBold, statementFontSize, "Saldo")
+	r.y += statementRowSize + 4
+}
+
+func (r *statementRenderer) categoryHeader() {
+	r.page.FillRect(statementMargin, r.y, statementRight-statementMargin, statementRowSize+2, 0.85)
+	baseline := r.y + statementRowSize - 3
+	r.page.Text(statementColumns.date, baseline, pdf.HelveticaBold, statementFontSize, "Kategori")
+	r.page.Text(statementColumns.category, baseline, pdf.HelveticaBold, statementFontSize, "Arah")
+	r.page.TextRight(statementColumns.out, baseline, pdf.HelveticaBold, statementFontSize, "Jumlah")
+	r.page.TextRight(statementColumns.balance, baseline, pdf.HelveticaBold, statementFontSize, "Total")
+	r.y += statementRowSize + 4
+}
+
+// row writes one line of the transaction table; amount is nil for the
+// opening balance row
+func (r *statementRenderer) row(font pdf.Font, date, description, category string, amount *domain.Money, balance domain.Money) {
+	if r.ensureSpace(statementRowSize) {
+		r.tableHeader()
+	}
+
+	baseline := r.y + statementRowSize - 4
+	r.page.Text(statementColumns.date, baseline, font, statementFontSize, date)
+	r.page.Text(statementColumns.description, baseline, font, statementFontSize,
+		pdf.Truncate(font, statementFontSize, description, statementColumns.category-statementColumns.description-8))
+	r.page.Text(statementColumns.category, baseline, font, statementFontSize,
+		pdf.Truncate(font, statementFontSize, category, statementColumns.in-statementColumns.category-70))
+	if amount != nil {
+		if amount.IsNegative() {
+			r.page.TextRight(statementColumns.out, baseline, font, statementFontSize, formatAmount(amount.Neg()))
+		} else {
+			r.page.TextRight(statementColumns.in, baseline, font, statementFontSize, formatAmount(*amount))
+		}
+	}
+	r.page.TextRight(statementColumns.balance, baseline, font, statementFontSize, formatAmount(balance))
+	r.y += statementRowSize
+}
+
+// ensureSpace starts a new page when height no longer fits on the current
+// one, and reports whether it did
+func (r *statementRenderer) ensureSpace(height float64) bool {
+	if r.y+height <= statementBottom {
+		return false
+	}
+	r.page = r.document.AddPage()
+	r.y = statementMargin + 20
+	return true
+}
+
+// formatRupiah formats an amount the way Indonesian banks print it, such as
+// "Rp 1.250.000" or "-Rp 15.000,50". Other currencies use their code.
+func formatRupiah(amount domain.Money) string {
+	symbol := amount.Currency()
+	if symbol == "" || symbol == "IDR" {
+		symbol = "Rp"
+	}
+	if amount.IsNegative() {
+		return "-" + symbol + " " + formatAmount(amount.Neg())
+	}
+	return symbol + " " + formatAmount(amount)
+}
+
+// formatAmount formats an amount with "." between thousands and "," before
+// the cents, leaving the cents out when they are zero
+func formatAmount(amount domain.Money) string {
+	minor := amount.Minor()
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+
+	whole := fmt.Sprint(minor / 100)
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if cents := minor % 100; cents != 0 {
+		return fmt.Sprintf("%s%s,%02d", sign, grouped.String(), cents)
+	}
+	return sign + grouped.String()
+}
+
+func formatIndonesianDate(date time.Time) string {
+	return fmt.Sprintf("%d %s %d", date.Day(), indonesianMonths[date.Month()-1], date.Year())
+}
+
+func formatShortDate(date time.Time) string {
+	return date.Format("02/01/2006")
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-moneyku/internal/domain"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// statementWallets and statementLedger serve one wallet and its postings
+type statementWallets struct {
+	domain.WalletRepository
+	wallet *domain.Wallet
+}
+
+func (r statementWallets) FindByID(_ context.Context, id int) (*domain.Wallet, error) {
+	if id != r.wallet.ID {
+		return nil, domain.NotFound("wallet")
+	}
+	wallet := *r.wallet
+	return &wallet, nil
+}
+
+type statementLedger struct {
+	domain.LedgerRepository
+	opening  domain.Money
+	postings []domain.WalletPosting
+}
+
+func (r statementLedger) WalletBalanceAt(context.Context, int, time.Time) (domain.Money, error) {
+	return r.opening, nil
+}
+
+func (r statementLedger) FindWalletPostings(context.Context, int, time.Time, time.Time) ([]domain.WalletPosting, error) {
+	return r.postings, nil
+}
+
+func TestStatementPDF(t *testing.T) {
+	tests := []struct {
+		name     string
+		opening  domain.Money
+		postings []domain.WalletPosting
+		pages    int
+	}{
+		{
+			name:     "multi_page",
+			opening:  rupiah(5_000_000),
+			postings: busyMonthPostings(),
+			pages:    3,
+		},
+		{
+			name:    "empty_month",
+			opening: rupiah(1_250_000),
+			pages:   1,
+		},
+		{
+			name:    "transfers_and_adjustments",
+			opening: rupiah(750_000),
+			postings: []domain.WalletPosting{
+				{Kind: domain.JournalEntryIncome, Category: "Gaji", Memo: "Gaji Maret", Date: day(1), Amount: rupiah(8_500_000)},
+				{Kind: domain.JournalEntryTransfer, Memo: "Tabungan", OtherWallet: "BCA Tahapan", Date: day(2), Amount: rupiah(-2_000_000)},
+				{Kind: domain.JournalEntryTransfer, OtherWallet: "GoPay", Date: day(5), Amount: rupiah(150_000)},
+				{Kind: domain.JournalEntryExpense, Memo: "Kopi & roti (pagi)", Date: day(6), Amount: domain.NewMoney(-35_500_50, "IDR")},
+				{Kind: domain.JournalEntryAdjustment, Memo: "Manual balance adjustment", Date: day(20), Amount: rupiah(-12_345)},
+				{Kind: domain.JournalEntryExpense, Category: "Belanja Bulanan Keluarga Besar di Supermarket", Memo: "Belanja bulanan dengan keterangan yang sangat panjang sekali", Date: day(25), Amount: rupiah(-1_275_000)},
+			},
+			pages: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wallet := &domain.Wallet{ID: 7, UserID: 1, Name: "Rekening Usaha", Currency: "IDR"}
+			s := NewReportService(nil, statementWallets{wallet: wallet}, nil, statementLedger{opening: tt.opening, postings: tt.postings}, nil, 0)
+			s.now = func() time.Time { return time.Date(2024, time.April, 2, 9, 30, 0, 0, time.UTC) }
+
+			statement, err := s.GetWalletStatement(context.Background(), 1, wallet.ID, day(15))
+			if err != nil {
+				t.Fatalf("GetWalletStatement: %v", err)
+			}
+			var got bytes.Buffer
+			if err := statement.WritePDF(&got); err != nil {
+				t.Fatalf("WritePDF: %v", err)
+			}
+
+			if pages := bytes.Count(got.Bytes(), []byte("/Type /Page ")); pages != tt.pages {
+				t.Errorf("got %d pages, want %d", pages, tt.pages)
+			}
+
+			golden := filepath.Join("testdata", "statement_"+tt.name+".pdf")
+			if *update {
+				if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+					t.Fatalf("write golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("PDF differs from %s; check the layout change and run go test ./internal/service -run TestStatementPDF -update", golden)
+			}
+		})
+	}
+}
+
+// busyMonthPostings is a month with enough movements to fill several pages
+func busyMonthPostings() []domain.WalletPosting {
+	categories := []string{"Makan", "Transportasi", "Bahan Baku", "Listrik", "Gaji Karyawan"}
+	var postings []domain.WalletPosting
+	for i := 0; i < 110; i++ {
+		posting := domain.WalletPosting{
+			Kind:     domain.JournalEntryExpense,
+			Category: categories[i%len(categories)],
+			Memo:     fmt.Sprintf("Pembayaran nomor %d", i+1),
+			Date:     day(1 + i%28),
+			Amount:   rupiah(-int64(10_000 + i*1_500)),
+		}
+		if i%7 == 0 {
+			posting.Kind = domain.JournalEntryIncome
+			posting.Category = "Penjualan"
+			posting.Memo = fmt.Sprintf("Penjualan harian %d", i+1)
+			posting.Amount = rupiah(int64(250_000 + i*1_000))
+		}
+		postings = append(postings, posting)
+	}
+	return postings
+}
+
+func rupiah(amount int64) domain.Money {
+	return domain.NewMoney(amount*100, "IDR")
+}
+
+func day(d int) time.Time {
+	return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC)
+}
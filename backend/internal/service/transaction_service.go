@@ -1,242 +1,625 @@
 package service
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"go-moneyku/internal/domain"
+	"go-moneyku/internal/validation"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
 )
 
 type TransactionService struct {
-	transactionRepo domain.TransactionRepository
-	walletRepo      domain.WalletRepository
+	transactionRepo     domain.TransactionRepository
+	walletRepo          domain.WalletRepository
+	budgetService       *BudgetService
+	exchangeRateService *ExchangeRateService
+	uow                 domain.UnitOfWork
 }
 
-func NewTransactionService(transactionRepo domain.TransactionRepository, walletRepo domain.WalletRepository) *TransactionService {
+func NewTransactionService(transactionRepo domain.TransactionRepository, walletRepo domain.WalletRepository, budgetService *BudgetService, exchangeRateService *ExchangeRateService, uow domain.UnitOfWork) *TransactionService {
 	return &TransactionService{
-		transactionRepo: transactionRepo,
-		walletRepo:      walletRepo,
+		transactionRepo:     transactionRepo,
+		walletRepo:          walletRepo,
+		budgetService:       budgetService,
+		exchangeRateService: exchangeRateService,
+		uow:                 uow,
 	}
 }
 
+// defaultFeeCategory is the expense category of transfer fees when the
+// request names none
+const defaultFeeCategory = "Biaya Transfer"
+
+// TransactionResult is a created transaction together with the budgets it
+// pushed past an alert threshold
+type TransactionResult struct {
+	*domain.Transaction
+	Fee          *domain.Transaction `json:"fee,omitempty"` // Expense booked for the fee of a transfer
+	BudgetAlerts []BudgetAlert       `json:"budget_alerts,omitempty"`
+}
+
+// CreateTransactionRequest creates a transaction. A transfer between wallets
+// of different currencies also needs ToAmount, the amount received, or
+// ExchangeRate, the price of one unit of the source currency in the
+// destination currency. A transfer fee is booked as a separate expense from
+// the source wallet.
 type CreateTransactionRequest struct {
-	WalletID    int                    `json:"wallet_id"`
-	Type        domain.TransactionType `json:"type"`
-	Amount      float64                `json:"amount"`
-	Category    string                 `json:"category"`
-	Description string                 `json:"description"`
-	Date        string                 `json:"date"`
-	ToWalletID  *int                   `json:"to_wallet_id,omitempty"`
+	WalletID      int                    `json:"wallet_id" validate:"required"`
+	Type          domain.TransactionType `json:"type" validate:"required,oneof=income expense transfer"`
+	Amount        domain.Money           `json:"amount" validate:"gt=0"`
+	Category      string                 `json:"category" validate:"max=100"`
+	CategoryID    *int                   `json:"category_id,omitempty"`
+	Description   string                 `json:"description" validate:"max=1000"`
+	Date          string                 `json:"date" validate:"omitempty,date"` // Defaults to now
+	ToWalletID    *int                   `json:"to_wallet_id,omitempty" validate:"required_if=Type transfer"`
+	ToAmount      *domain.Money          `json:"to_amount,omitempty" validate:"omitempty,gt=0"`
+	ExchangeRate  *domain.Rate           `json:"exchange_rate,omitempty"`
+	Fee           *domain.Money          `json:"fee,omitempty" validate:"omitempty,gt=0"`
+	FeeCategory   string                 `json:"fee_category,omitempty" validate:"max=100"`
+	FeeCategoryID *int                   `json:"fee_category_id,omitempty"`
+}
+
+// UpdateTransactionRequest holds the fields to change on an existing transaction.
+// Fields left out of the request keep their current value.
+type UpdateTransactionRequest struct {
+	WalletID    *int                    `json:"wallet_id"`
+	Type        *domain.TransactionType `json:"type" validate:"omitempty,oneof=income expense transfer"`
+	Amount      *domain.Money           `json:"amount"`
+	Category    *string                 `json:"category" validate:"omitempty,max=100"`
+	CategoryID  *int                    `json:"category_id,omitempty"`
+	Description *string                 `json:"description" validate:"omitempty,max=1000"`
+	Date        *string                 `json:"date" validate:"omitempty,date"`
+	ToWalletID  *int                    `json:"to_wallet_id,omitempty"`
+	// Changing the amount or wallets of a transfer between currencies needs
+	// the amount received or the exchange rate again
+	ToAmount     *domain.Money `json:"to_amount,omitempty"`
+	ExchangeRate *domain.Rate  `json:"exchange_rate,omitempty"`
 }
 
-func (s *TransactionService) CreateTransaction(userID int, req CreateTransactionRequest) (*domain.Transaction, error) {
-	// Validate input
-	if req.Amount <= 0 {
-		return nil, fmt.Errorf("amount must be greater than zero")
+func (s *TransactionService) CreateTransaction(ctx context.Context, userID int, req CreateTransactionRequest) (*TransactionResult, error) {
+	if req.Fee != nil && req.Type != domain.TransactionTypeTransfer {
+		return nil, domain.Invalid("a fee can only be charged on a transfer")
 	}
 
-	// Parse date
-	var transactionDate time.Time
-	var err error
-	now := time.Now()
-	if req.Date == "" {
-		transactionDate = now
-	} else {
-		// Try parsing YYYY-MM-DD
-		transactionDate, err = time.Parse("2006-01-02", req.Date)
-		if err == nil {
-			// If it's today's date, use current time
-			if transactionDate.Format("2006-01-02") == now.Format("2006-01-02") {
-				transactionDate = now
-			}
-		} else {
-			// Try parsing RFC3339 as fallback
-			transactionDate, err = time.Parse(time.RFC3339, req.Date)
-			if err != nil {
-				return nil, fmt.Errorf("invalid date format (use YYYY-MM-DD or RFC3339)")
-			}
+	// Balance changes and the transaction records are written atomically
+	var transaction, fee *domain.Transaction
+	err := s.uow.Do(ctx, func(repos domain.Repositories) error {
+		var err error
+		transaction, err = s.createTransaction(ctx, repos, userID, req)
+		if err != nil {
+			return err
 		}
+		if req.Fee != nil {
+			fee, err = s.createTransferFee(ctx, repos, transaction, req)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Get source wallet and verify ownership
-	sourceWallet, err := s.walletRepo.FindByID(req.WalletID)
+	// The transaction is already saved, so a failing budget check only loses the alerts
+	alerts, err := s.budgetService.AlertsForTransaction(ctx, transaction)
 	if err != nil {
-		return nil, fmt.Errorf("wallet not found: %w", err)
+		log.Printf("Budget: failed to check alerts for transaction %d: %v", transaction.ID, err)
 	}
-	if sourceWallet.UserID != userID {
-		return nil, fmt.Errorf("unauthorized access to wallet")
+	if fee != nil {
+		feeAlerts, err := s.budgetService.AlertsForTransaction(ctx, fee)
+		if err != nil {
+			log.Printf("Budget: failed to check alerts for transaction %d: %v", fee.ID, err)
+		}
+		alerts = append(alerts, feeAlerts...)
+	}
+
+	return &TransactionResult{
+		Transaction:  transaction,
+		Fee:          fee,
+		BudgetAlerts: alerts,
+	}, nil
+}
+
+// createTransaction creates a transaction inside a unit of work that is owned
+// by the caller, so other services can combine it with their own writes
+func (s *TransactionService) createTransaction(ctx context.Context, repos domain.Repositories, userID int, req CreateTransactionRequest) (*domain.Transaction, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	// Parse date
+	transactionDate, err := parseTransactionDate(req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := &domain.Transaction{
+		UserID:      userID,
+		WalletID:    req.WalletID,
+		Type:        req.Type,
+		Amount:      req.Amount,
+		Category:    req.Category,
+		CategoryID:  req.CategoryID,
+		Description: req.Description,
+		Date:        transactionDate,
+		ToWalletID:  req.ToWalletID,
+		ToAmount:    req.ToAmount,
+	}
+
+	if err := validateTransactionWallets(ctx, repos.Wallets, userID, transaction, req.ExchangeRate); err != nil {
+		return nil, err
+	}
+	if err := resolveTransactionCategory(ctx, repos.Categories, userID, transaction); err != nil {
+		return nil, err
+	}
+
+	// Create transaction record
+	if err := repos.Transactions.Create(ctx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	// Book it in the ledger, which also moves the wallet balances
+	if err := postJournalEntry(ctx, repos, journalEntryForTransaction(transaction)); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// createTransferFee books the fee of a transfer as an expense from the
+// transfer's source wallet, linked to the transfer
+func (s *TransactionService) createTransferFee(ctx context.Context, repos domain.Repositories, transfer *domain.Transaction, req CreateTransactionRequest) (*domain.Transaction, error) {
+	category := req.FeeCategory
+	if category == "" && req.FeeCategoryID == nil {
+		category = defaultFeeCategory
+	}
+	transferID := transfer.ID
+	fee := &domain.Transaction{
+		UserID:      transfer.UserID,
+		WalletID:    transfer.WalletID,
+		Type:        domain.TransactionTypeExpense,
+		Amount:      req.Fee.WithCurrency(transfer.Amount.Currency()),
+		Category:    category,
+		CategoryID:  req.FeeCategoryID,
+		Description: strings.TrimSpace("Biaya transfer " + transfer.Description),
+		Date:        transfer.Date,
+		FeeOfID:     &transferID,
+	}
+
+	if err := resolveTransactionCategory(ctx, repos.Categories, transfer.UserID, fee); err != nil {
+		return nil, err
+	}
+	if err := repos.Transactions.Create(ctx, fee); err != nil {
+		return nil, fmt.Errorf("failed to create transfer fee: %w", err)
+	}
+	if err := postJournalEntry(ctx, repos, journalEntryForTransaction(fee)); err != nil {
+		return nil, err
 	}
 
-	// Handle different transaction types
-	switch req.Type {
-	case domain.TransactionTypeExpense:
-		if sourceWallet.Balance < req.Amount {
-			return nil, fmt.Errorf("insufficient balance")
+	return fee, nil
+}
+
+func (s *TransactionService) UpdateTransaction(ctx context.Context, transactionID int, userID int, req UpdateTransactionRequest) (*domain.Transaction, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	var transaction *domain.Transaction
+	err := s.uow.Do(ctx, func(repos domain.Repositories) error {
+		// Get transaction and verify ownership
+		existing, err := repos.Transactions.FindByID(ctx, transactionID)
+		if err != nil {
+			return err
 		}
-		// Decrease wallet balance
-		newBalance := sourceWallet.Balance - req.Amount
-		if err := s.walletRepo.UpdateBalance(sourceWallet.ID, newBalance); err != nil {
-			return nil, fmt.Errorf("failed to update wallet balance: %w", err)
+		if existing.UserID != userID {
+			return domain.Forbidden("transaction")
 		}
 
-	case domain.TransactionTypeIncome:
-		// Increase wallet balance
-		newBalance := sourceWallet.Balance + req.Amount
-		if err := s.walletRepo.UpdateBalance(sourceWallet.ID, newBalance); err != nil {
-			return nil, fmt.Errorf("failed to update wallet balance: %w", err)
+		// Apply the requested changes to a copy of the stored transaction
+		updated := *existing
+		if req.WalletID != nil {
+			updated.WalletID = *req.WalletID
 		}
-
-	case domain.TransactionTypeTransfer:
-		if req.ToWalletID == nil {
-			return nil, fmt.Errorf("destination wallet is required for transfer")
+		if req.Type != nil {
+			updated.Type = *req.Type
+		}
+		if req.Amount != nil {
+			if !req.Amount.IsPositive() {
+				return domain.Invalid("amount must be greater than zero")
+			}
+			updated.Amount = *req.Amount
+		}
+		if req.Type != nil && updated.Type != existing.Type {
+			// The category is looked up again by name for the new type
+			updated.CategoryID = nil
+		}
+		if req.Category != nil {
+			updated.Category = *req.Category
+			updated.CategoryID = nil
+		}
+		if req.CategoryID != nil {
+			updated.CategoryID = req.CategoryID
+		}
+		if req.Description != nil {
+			updated.Description = *req.Description
+		}
+		if req.Date != nil {
+			updated.Date, err = parseTransactionDate(*req.Date)
+			if err != nil {
+				return err
+			}
+		}
+		if req.ToWalletID != nil {
+			updated.ToWalletID = req.ToWalletID
+		}
+		// Only transfers have a destination wallet
+		if updated.Type != domain.TransactionTypeTransfer {
+			updated.ToWalletID = nil
 		}
-		if *req.ToWalletID == req.WalletID {
-			return nil, fmt.Errorf("cannot transfer to the same wallet")
+		// The amount received no longer holds once what was sent changes
+		if updated.WalletID != existing.WalletID || updated.Type != existing.Type ||
+			updated.Amount.Cmp(existing.Amount) != 0 || !sameWallet(updated.ToWalletID, existing.ToWalletID) {
+			updated.ToAmount = nil
 		}
-		if sourceWallet.Balance < req.Amount {
-			return nil, fmt.Errorf("insufficient balance")
+		if req.ToAmount != nil {
+			updated.ToAmount = req.ToAmount
 		}
 
-		// Get destination wallet and verify ownership
-		destWallet, err := s.walletRepo.FindByID(*req.ToWalletID)
+		if err := validateTransactionWallets(ctx, repos.Wallets, userID, &updated, req.ExchangeRate); err != nil {
+			return err
+		}
+		if err := resolveTransactionCategory(ctx, repos.Categories, userID, &updated); err != nil {
+			return err
+		}
+
+		oldEntry, err := repos.Ledger.FindByTransactionID(ctx, existing.ID)
 		if err != nil {
-			return nil, fmt.Errorf("destination wallet not found: %w", err)
+			return err
 		}
-		if destWallet.UserID != userID {
-			return nil, fmt.Errorf("unauthorized access to destination wallet")
+		newEntry := journalEntryForTransaction(&updated)
+
+		// Reverse the old entry and post the new one as a single net change per
+		// wallet, so an edit is only rejected when its end result would overdraw
+		changes := mergeWalletChanges(walletChanges(oldEntry, -1), walletChanges(newEntry, 1))
+		if err := applyBalanceChanges(ctx, repos.Wallets, changes); err != nil {
+			return err
 		}
 
-		// Update both wallet balances
-		if err := s.walletRepo.UpdateBalance(sourceWallet.ID, sourceWallet.Balance-req.Amount); err != nil {
-			return nil, fmt.Errorf("failed to update source wallet balance: %w", err)
+		if err := repos.Transactions.Update(ctx, &updated); err != nil {
+			return fmt.Errorf("failed to update transaction: %w", err)
 		}
-		if err := s.walletRepo.UpdateBalance(destWallet.ID, destWallet.Balance+req.Amount); err != nil {
-			// Rollback source wallet
-			s.walletRepo.UpdateBalance(sourceWallet.ID, sourceWallet.Balance)
-			return nil, fmt.Errorf("failed to update destination wallet balance: %w", err)
+
+		if err := repos.Ledger.DeleteByTransactionID(ctx, existing.ID); err != nil {
+			return err
+		}
+		if err := recordJournalEntry(ctx, repos, newEntry); err != nil {
+			return err
 		}
 
-	default:
-		return nil, fmt.Errorf("invalid transaction type")
+		transaction = &updated
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Create transaction record
-	transaction := &domain.Transaction{
-		UserID:      userID,
-		WalletID:    req.WalletID,
-		Type:        req.Type,
-		Amount:      req.Amount,
-		Category:    req.Category,
-		Description: req.Description,
-		Date:        transactionDate,
-		ToWalletID:  req.ToWalletID,
+	return transaction, nil
+}
+
+// TransactionPage is one page of a transaction search. NextCursor is empty on
+// the last page.
+type TransactionPage struct {
+	Transactions []domain.Transaction `json:"transactions"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
+}
+
+// SearchTransactions returns the page of the user's transactions matching the
+// filter that follows the given cursor
+func (s *TransactionService) SearchTransactions(ctx context.Context, userID int, filter domain.TransactionFilter, cursor string) (*TransactionPage, error) {
+	filter.UserID = userID
+
+	switch filter.Sort {
+	case "":
+		filter.Sort = domain.TransactionSortDateDesc
+	case domain.TransactionSortDateDesc, domain.TransactionSortDateAsc,
+		domain.TransactionSortAmountDesc, domain.TransactionSortAmountAsc:
+	default:
+		return nil, domain.Invalid("invalid sort (use date_desc, date_asc, amount_desc or amount_asc)")
 	}
 
-	if err := s.transactionRepo.Create(transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
 	}
 
-	return transaction, nil
-}
+	if cursor != "" {
+		after, err := decodeTransactionCursor(cursor, filter.Sort)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
 
-func (s *TransactionService) GetUserTransactions(userID int) ([]domain.Transaction, error) {
-	transactions, err := s.transactionRepo.FindByUserID(userID)
+	// One extra row tells whether another page follows
+	pageSize := filter.Limit
+	filter.Limit++
+	transactions, err := s.transactionRepo.Search(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
 	}
-	return transactions, nil
+
+	page := &TransactionPage{Transactions: transactions}
+	if len(transactions) > pageSize {
+		page.Transactions = transactions[:pageSize]
+		last := page.Transactions[pageSize-1]
+		page.NextCursor = encodeTransactionCursor(filter.Sort, domain.TransactionCursor{
+			Date:   last.Date,
+			Amount: last.Amount,
+			ID:     last.ID,
+		})
+	}
+	if page.Transactions == nil {
+		page.Transactions = []domain.Transaction{}
+	}
+
+	return page, nil
+}
+
+// DeleteTransaction deletes a transaction and reverses its ledger entry.
+// Deleting a transfer also deletes the fees booked for it.
+func (s *TransactionService) DeleteTransaction(ctx context.Context, transactionID int, userID int) error {
+	return s.uow.Do(ctx, func(repos domain.Repositories) error {
+		// Get transaction and verify ownership
+		transaction, err := repos.Transactions.FindByID(ctx, transactionID)
+		if err != nil {
+			return err
+		}
+		if transaction.UserID != userID {
+			return domain.Forbidden("transaction")
+		}
+
+		fees, err := repos.Transactions.FindFees(ctx, transaction.ID)
+		if err != nil {
+			return err
+		}
+
+		// Reverse the balance changes recorded in the ledger as one net change
+		// per wallet, which restores both sides of a transfer exactly
+		changes := make(map[int]domain.Money)
+		transactionIDs := []int{transaction.ID}
+		for _, fee := range fees {
+			transactionIDs = append(transactionIDs, fee.ID)
+		}
+		for _, id := range transactionIDs {
+			entry, err := repos.Ledger.FindByTransactionID(ctx, id)
+			if err != nil {
+				return err
+			}
+			changes = mergeWalletChanges(changes, walletChanges(entry, -1))
+		}
+		if err := applyBalanceChanges(ctx, repos.Wallets, changes); err != nil {
+			return err
+		}
+
+		// Fees go first, so the transfer is no longer referenced
+		for i := len(transactionIDs) - 1; i >= 0; i-- {
+			if err := repos.Ledger.DeleteByTransactionID(ctx, transactionIDs[i]); err != nil {
+				return err
+			}
+			if err := repos.Transactions.Delete(ctx, transactionIDs[i]); err != nil {
+				return fmt.Errorf("failed to delete transaction: %w", err)
+			}
+		}
+
+		return nil
+	})
 }
 
-func (s *TransactionService) GetWalletTransactions(walletID int, userID int) ([]domain.Transaction, error) {
-	// Verify wallet ownership
-	wallet, err := s.walletRepo.FindByID(walletID)
+// GetTransactionStats totals the transactions of each type in the base
+// currency, converting each day at its own rate
+func (s *TransactionService) GetTransactionStats(ctx context.Context, userID int) (*domain.TransactionStats, error) {
+	converter, err := s.exchangeRateService.converter(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("wallet not found: %w", err)
-	}
-	if wallet.UserID != userID {
-		return nil, fmt.Errorf("unauthorized access to wallet")
+		return nil, err
 	}
 
-	transactions, err := s.transactionRepo.FindByWalletID(walletID)
+	dailyTotals, err := s.transactionRepo.GetDailyTotalsByUserID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
+		return nil, fmt.Errorf("failed to get transaction stats: %w", err)
 	}
-	return transactions, nil
+
+	stats := &domain.TransactionStats{
+		BaseCurrency:  converter.base,
+		TotalIncome:   domain.NewMoney(0, converter.base),
+		TotalExpense:  domain.NewMoney(0, converter.base),
+		TotalTransfer: domain.NewMoney(0, converter.base),
+	}
+	for _, daily := range dailyTotals {
+		amount, err := converter.convert(ctx, daily.Amount, daily.Currency, daily.Date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert transaction totals: %w", err)
+		}
+		switch daily.Type {
+		case domain.TransactionTypeIncome:
+			stats.TotalIncome = stats.TotalIncome.Add(amount)
+		case domain.TransactionTypeExpense:
+			stats.TotalExpense = stats.TotalExpense.Add(amount)
+		case domain.TransactionTypeTransfer:
+			stats.TotalTransfer = stats.TotalTransfer.Add(amount)
+		}
+	}
+	stats.MissingRates = converter.missingRates()
+
+	return stats, nil
 }
 
-func (s *TransactionService) GetTransactionsByDateRange(userID int, startDate, endDate time.Time) ([]domain.Transaction, error) {
-	transactions, err := s.transactionRepo.FindByDateRange(userID, startDate, endDate)
+func (s *TransactionService) GetRecentTransactions(ctx context.Context, userID int, limit int) ([]domain.Transaction, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	transactions, err := s.transactionRepo.GetRecentByUserID(ctx, userID, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
+		return nil, fmt.Errorf("failed to fetch recent transactions: %w", err)
 	}
 	return transactions, nil
 }
 
-func (s *TransactionService) DeleteTransaction(transactionID int, userID int) error {
-	// Get transaction and verify ownership
-	transaction, err := s.transactionRepo.FindByID(transactionID)
-	if err != nil {
-		return fmt.Errorf("transaction not found: %w", err)
+// parseTransactionDate accepts YYYY-MM-DD or RFC3339 and defaults to now.
+// A plain date for today keeps the current time so the entry sorts as the latest.
+func parseTransactionDate(value string) (time.Time, error) {
+	now := time.Now()
+	if value == "" {
+		return now, nil
 	}
-	if transaction.UserID != userID {
-		return fmt.Errorf("unauthorized access to transaction")
+
+	// Try parsing YYYY-MM-DD
+	date, err := time.Parse("2006-01-02", value)
+	if err == nil {
+		// If it's today's date, use current time
+		if date.Format("2006-01-02") == now.Format("2006-01-02") {
+			return now, nil
+		}
+		return date, nil
 	}
 
-	// Reverse the balance changes
-	sourceWallet, err := s.walletRepo.FindByID(transaction.WalletID)
+	// Try parsing RFC3339 as fallback
+	date, err = time.Parse(time.RFC3339, value)
 	if err != nil {
-		return fmt.Errorf("wallet not found: %w", err)
+		return time.Time{}, domain.Invalid("invalid date format (use YYYY-MM-DD or RFC3339)")
+	}
+	return date, nil
+}
+
+// validateTransactionWallets checks the transaction type and that every wallet it
+// touches belongs to the user. The amount is tagged with the source wallet currency.
+// For a transfer between currencies it makes sure the amount received is known,
+// computing it from rate when given.
+func validateTransactionWallets(ctx context.Context, walletRepo domain.WalletRepository, userID int, transaction *domain.Transaction, rate *domain.Rate) error {
+	// Get source wallet and verify ownership
+	sourceWallet, err := walletRepo.FindByID(ctx, transaction.WalletID)
+	if err != nil {
+		return err
+	}
+	if sourceWallet.UserID != userID {
+		return domain.Forbidden("wallet")
 	}
 
 	switch transaction.Type {
-	case domain.TransactionTypeExpense:
-		// Add back the amount
-		newBalance := sourceWallet.Balance + transaction.Amount
-		if err := s.walletRepo.UpdateBalance(sourceWallet.ID, newBalance); err != nil {
-			return fmt.Errorf("failed to update wallet balance: %w", err)
+	case domain.TransactionTypeExpense, domain.TransactionTypeIncome:
+		// Only the source wallet is involved
+
+	case domain.TransactionTypeTransfer:
+		if transaction.ToWalletID == nil {
+			return domain.Invalid("destination wallet is required for transfer")
+		}
+		if *transaction.ToWalletID == transaction.WalletID {
+			return domain.Invalid("cannot transfer to the same wallet")
 		}
 
-	case domain.TransactionTypeIncome:
-		// Subtract the amount
-		newBalance := sourceWallet.Balance - transaction.Amount
-		if err := s.walletRepo.UpdateBalance(sourceWallet.ID, newBalance); err != nil {
-			return fmt.Errorf("failed to update wallet balance: %w", err)
+		// Get destination wallet and verify ownership
+		destWallet, err := walletRepo.FindByID(ctx, *transaction.ToWalletID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.NotFound("destination wallet")
+		}
+		if err != nil {
+			return err
+		}
+		if destWallet.UserID != userID {
+			return domain.Forbidden("destination wallet")
 		}
 
-	case domain.TransactionTypeTransfer:
-		if transaction.ToWalletID != nil {
-			destWallet, err := s.walletRepo.FindByID(*transaction.ToWalletID)
-			if err == nil {
-				// Reverse both wallets
-				s.walletRepo.UpdateBalance(sourceWallet.ID, sourceWallet.Balance+transaction.Amount)
-				s.walletRepo.UpdateBalance(destWallet.ID, destWallet.Balance-transaction.Amount)
-			}
+		if err := resolveTransferAmount(transaction, sourceWallet, destWallet, rate); err != nil {
+			return err
 		}
-	}
 
-	// Delete transaction
-	if err := s.transactionRepo.Delete(transactionID); err != nil {
-		return fmt.Errorf("failed to delete transaction: %w", err)
+	default:
+		return domain.Invalid("invalid transaction type")
 	}
 
+	if transaction.Type != domain.TransactionTypeTransfer {
+		transaction.ToAmount = nil
+	}
+	transaction.Amount = transaction.Amount.WithCurrency(sourceWallet.Currency)
 	return nil
 }
 
-func (s *TransactionService) GetTransactionStats(userID int) (*domain.TransactionStats, error) {
-	stats, err := s.transactionRepo.GetStatsByUserID(userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get transaction stats: %w", err)
+// resolveTransferAmount sets the amount a transfer adds to its destination.
+// Between wallets of the same currency that is the amount sent, so ToAmount
+// stays nil; otherwise it is the given ToAmount or the amount sent at rate.
+func resolveTransferAmount(transaction *domain.Transaction, source, destination *domain.Wallet, rate *domain.Rate) error {
+	if source.Currency == destination.Currency {
+		if rate != nil || transaction.ToAmount != nil && transaction.ToAmount.Cmp(transaction.Amount) != 0 {
+			return domain.Invalid("to_amount and exchange_rate only apply to transfers between currencies")
+		}
+		transaction.ToAmount = nil
+		return nil
 	}
-	return stats, nil
+
+	if rate != nil {
+		if transaction.ToAmount != nil {
+			return domain.Invalid("give either to_amount or exchange_rate, not both")
+		}
+		if !rate.IsPositive() {
+			return domain.Invalid("exchange rate must be greater than zero")
+		}
+		received := transaction.Amount.Convert(*rate, destination.Currency)
+		transaction.ToAmount = &received
+	}
+	if transaction.ToAmount == nil {
+		return domain.Invalid("to_amount or exchange_rate is required for a transfer from %s to %s", source.Currency, destination.Currency)
+	}
+	if !transaction.ToAmount.IsPositive() {
+		return domain.Invalid("to_amount must be greater than zero")
+	}
+
+	received := transaction.ToAmount.WithCurrency(destination.Currency)
+	transaction.ToAmount = &received
+	return nil
 }
 
-func (s *TransactionService) GetRecentTransactions(userID int, limit int) ([]domain.Transaction, error) {
-	if limit <= 0 {
-		limit = 10
+func sameWallet(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
 	}
-	transactions, err := s.transactionRepo.GetRecentByUserID(userID, limit)
+	return *a == *b
+}
+
+// transactionCursor is the opaque next_cursor handed to clients. The sort is
+// included so that a cursor cannot be replayed against a different order.
+type transactionCursor struct {
+	Sort domain.TransactionSort `json:"s"`
+	domain.TransactionCursor
+}
+
+func encodeTransactionCursor(sort domain.TransactionSort, key domain.TransactionCursor) string {
+	data, _ := json.Marshal(transactionCursor{Sort: sort, TransactionCursor: key})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTransactionCursor(cursor string, sort domain.TransactionSort) (*domain.TransactionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch recent transactions: %w", err)
+		return nil, domain.Invalid("invalid cursor")
 	}
-	return transactions, nil
+
+	var decoded transactionCursor
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, domain.Invalid("invalid cursor")
+	}
+	if decoded.Sort != sort {
+		return nil, domain.Invalid("cursor does not match the requested sort")
+	}
+
+	return &decoded.TransactionCursor, nil
 }
@@ -1,153 +1,342 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"go-moneyku/internal/domain"
+	"go-moneyku/internal/validation"
 )
 
 type WalletService struct {
-	walletRepo      domain.WalletRepository
-	transactionRepo domain.TransactionRepository
+	walletRepo          domain.WalletRepository
+	transactionRepo     domain.TransactionRepository
+	exchangeRateService *ExchangeRateService
+	walletTypeService   *WalletTypeService
+	uow                 domain.UnitOfWork
 }
 
-func NewWalletService(walletRepo domain.WalletRepository, transactionRepo domain.TransactionRepository) *WalletService {
+func NewWalletService(walletRepo domain.WalletRepository, transactionRepo domain.TransactionRepository, exchangeRateService *ExchangeRateService, walletTypeService *WalletTypeService, uow domain.UnitOfWork) *WalletService {
 	return &WalletService{
-		walletRepo:      walletRepo,
-		transactionRepo: transactionRepo,
+		walletRepo:          walletRepo,
+		transactionRepo:     transactionRepo,
+		exchangeRateService: exchangeRateService,
+		walletTypeService:   walletTypeService,
+		uow:                 uow,
 	}
 }
 
 type CreateWalletRequest struct {
-	Name     string  `json:"name"`
-	Balance  float64 `json:"balance"`
-	Currency string  `json:"currency"`
-	Type     string  `json:"type"`
-	Icon     string  `json:"icon"`
-	Color    string  `json:"color"`
+	Name         string              `json:"name" validate:"required,max=255"`
+	Balance      domain.Money        `json:"balance"`
+	Currency     string              `json:"currency" validate:"omitempty,currency"` // Defaults to IDR
+	Type         string              `json:"type" validate:"max=50"`
+	AccountClass domain.AccountClass `json:"account_class" validate:"omitempty,oneof=asset liability"` // Defaults to the class of the wallet type
+	CreditLimit  *domain.Money       `json:"credit_limit,omitempty" validate:"omitempty,gte=0"`
+	StatementDay *int                `json:"statement_day,omitempty" validate:"omitempty,min=1,max=31"`
+	DueDay       *int                `json:"due_day,omitempty" validate:"omitempty,min=1,max=31"`
+	Icon         string              `json:"icon" validate:"max=50"`
+	Color        string              `json:"color" validate:"max=50"`
 }
 
 type UpdateWalletRequest struct {
-	Name     string  `json:"name"`
-	Balance  float64 `json:"balance"`
-	Currency string  `json:"currency"`
-	Type     string  `json:"type"`
-	Icon     string  `json:"icon"`
-	Color    string  `json:"color"`
+	Name             string              `json:"name" validate:"max=255"`
+	Balance          *domain.Money       `json:"balance,omitempty"` // Booked as a manual adjustment when it differs
+	Currency         string              `json:"currency" validate:"omitempty,currency"`
+	Type             string              `json:"type" validate:"max=50"`
+	AccountClass     domain.AccountClass `json:"account_class" validate:"omitempty,oneof=asset liability"`
+	CreditLimit      *domain.Money       `json:"credit_limit,omitempty" validate:"omitempty,gte=0"`
+	ClearCreditLimit bool                `json:"clear_credit_limit"`                                  // Removes the credit limit
+	StatementDay     *int                `json:"statement_day,omitempty" validate:"omitempty,max=31"` // 0 removes the billing cycle
+	DueDay           *int                `json:"due_day,omitempty" validate:"omitempty,max=31"`
+	Icon             string              `json:"icon" validate:"max=50"`
+	Color            string              `json:"color" validate:"max=50"`
 }
 
-func (s *WalletService) CreateWallet(userID int, req CreateWalletRequest) (*domain.Wallet, error) {
-	// Validate input
-	if req.Name == "" {
-		return nil, fmt.Errorf("wallet name is required")
+func (s *WalletService) CreateWallet(ctx context.Context, userID int, req CreateWalletRequest) (*domain.Wallet, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
 	}
 
-	if req.Currency == "" {
-		req.Currency = "IDR"
+	currency := "IDR"
+	if req.Currency != "" {
+		currency = strings.ToUpper(req.Currency)
 	}
 
+	// The wallet starts empty and the initial balance is booked as an
+	// opening balance entry, so the ledger accounts for every rupiah
 	wallet := &domain.Wallet{
-		UserID:   userID,
-		Name:     req.Name,
-		Balance:  req.Balance,
-		Currency: req.Currency,
-		Type:     req.Type,
-		Icon:     req.Icon,
-		Color:    req.Color,
+		UserID:       userID,
+		Name:         req.Name,
+		Currency:     currency,
+		Type:         req.Type,
+		AccountClass: req.AccountClass,
+		CreditLimit:  req.CreditLimit,
+		StatementDay: req.StatementDay,
+		DueDay:       req.DueDay,
+		Icon:         req.Icon,
+		Color:        req.Color,
+	}
+	wallet.AccountClassOverride = wallet.AccountClass != ""
+	if wallet.AccountClass == "" {
+		registry, err := s.walletTypeService.registry(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		wallet.AccountClass = domain.AccountClassAsset
+		if walletType, ok := registry.types[domain.WalletTypeKey(wallet.Type)]; ok {
+			wallet.AccountClass = walletType.AccountClass
+		}
 	}
+	if err := validateWalletLimits(wallet, req.Balance); err != nil {
+		return nil, err
+	}
+
+	err := s.uow.Do(ctx, func(repos domain.Repositories) error {
+		if err := repos.Wallets.Create(ctx, wallet); err != nil {
+			return fmt.Errorf("failed to create wallet: %w", err)
+		}
 
-	if err := s.walletRepo.Create(wallet); err != nil {
-		return nil, fmt.Errorf("failed to create wallet: %w", err)
+		if req.Balance.IsZero() {
+			return nil
+		}
+		return postJournalEntry(ctx, repos, adjustmentEntry(wallet, req.Balance, domain.AccountOpeningBalance, "Opening balance"))
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	wallet.Balance = req.Balance.WithCurrency(wallet.Currency)
 	return wallet, nil
 }
 
-func (s *WalletService) GetUserWallets(userID int) ([]domain.Wallet, error) {
-	wallets, err := s.walletRepo.FindByUserID(userID)
+// validateWalletLimits checks the account class, credit limit and billing
+// days, and that the wallet may hold balance under them
+func validateWalletLimits(wallet *domain.Wallet, balance domain.Money) error {
+	switch wallet.AccountClass {
+	case domain.AccountClassAsset, domain.AccountClassLiability:
+	default:
+		return domain.Invalid("invalid account class (use asset or liability)")
+	}
+
+	if wallet.StatementDay != nil || wallet.DueDay != nil {
+		if wallet.AccountClass != domain.AccountClassLiability {
+			return domain.Invalid("statement and due days are only for liability wallets")
+		}
+		if wallet.StatementDay == nil || wallet.DueDay == nil {
+			return domain.Invalid("statement_day and due_day must be set together")
+		}
+		if *wallet.StatementDay < 1 || *wallet.StatementDay > 31 || *wallet.DueDay < 1 || *wallet.DueDay > 31 {
+			return domain.Invalid("statement_day and due_day must be between 1 and 31")
+		}
+	}
+
+	if wallet.CreditLimit != nil {
+		if wallet.CreditLimit.IsNegative() {
+			return domain.Invalid("credit limit cannot be negative")
+		}
+		limit := wallet.CreditLimit.WithCurrency(wallet.Currency)
+		wallet.CreditLimit = &limit
+	}
+
+	if !wallet.AllowsBalance(balance) {
+		if wallet.CreditLimit != nil {
+			return domain.Invalid("balance cannot go below the credit limit of %s", wallet.CreditLimit.String())
+		}
+		return domain.Invalid("balance cannot be negative for an asset wallet without a credit limit")
+	}
+	return nil
+}
+
+// nonZeroDay treats day 0 as no day
+func nonZeroDay(day *int) *int {
+	if day == nil || *day == 0 {
+		return nil
+	}
+	return day
+}
+
+func (s *WalletService) GetUserWallets(ctx context.Context, userID int) ([]domain.Wallet, error) {
+	wallets, err := s.walletRepo.FindByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch wallets: %w", err)
 	}
 	return wallets, nil
 }
 
-func (s *WalletService) GetWalletByID(walletID int, userID int) (*domain.Wallet, error) {
-	wallet, err := s.walletRepo.FindByID(walletID)
+func (s *WalletService) GetWalletByID(ctx context.Context, walletID int, userID int) (*domain.Wallet, error) {
+	return findOwnedWallet(ctx, s.walletRepo, walletID, userID)
+}
+
+// findOwnedWallet loads a wallet and verifies that it belongs to the user
+func findOwnedWallet(ctx context.Context, walletRepo domain.WalletRepository, walletID int, userID int) (*domain.Wallet, error) {
+	wallet, err := walletRepo.FindByID(ctx, walletID)
 	if err != nil {
-		return nil, fmt.Errorf("wallet not found: %w", err)
+		return nil, err
 	}
 
 	// Verify ownership
 	if wallet.UserID != userID {
-		return nil, fmt.Errorf("unauthorized access to wallet")
+		return nil, domain.Forbidden("wallet")
 	}
 
 	return wallet, nil
 }
 
-func (s *WalletService) UpdateWallet(walletID int, userID int, req UpdateWalletRequest) (*domain.Wallet, error) {
-	// Get wallet and verify ownership
-	wallet, err := s.GetWalletByID(walletID, userID)
-	if err != nil {
+func (s *WalletService) UpdateWallet(ctx context.Context, walletID int, userID int, req UpdateWalletRequest) (*domain.Wallet, error) {
+	if err := validation.Struct(req); err != nil {
 		return nil, err
 	}
-
-	// Update fields
-	if req.Name != "" {
-		wallet.Name = req.Name
+	if req.ClearCreditLimit && req.CreditLimit != nil {
+		return nil, domain.Invalid("credit_limit cannot be sent together with clear_credit_limit")
 	}
-	// Always update balance if provided (even if 0)
-	wallet.Balance = req.Balance
 
-	if req.Currency != "" {
-		wallet.Currency = req.Currency
-	}
-	if req.Type != "" {
-		wallet.Type = req.Type
-	}
-	if req.Icon != "" {
-		wallet.Icon = req.Icon
-	}
-	if req.Color != "" {
-		wallet.Color = req.Color
-	}
+	var wallet *domain.Wallet
+	err := s.uow.Do(ctx, func(repos domain.Repositories) error {
+		// Get wallet and verify ownership
+		var err error
+		wallet, err = findOwnedWallet(ctx, repos.Wallets, walletID, userID)
+		if err != nil {
+			return err
+		}
+
+		// Update fields
+		if req.Name != "" {
+			wallet.Name = req.Name
+		}
+		if currency := strings.ToUpper(req.Currency); currency != "" && currency != wallet.Currency {
+			// The balance and history are amounts in the old currency
+			hasPostings, err := repos.Ledger.HasPostings(ctx, wallet.ID)
+			if err != nil {
+				return err
+			}
+			if hasPostings {
+				return domain.Conflict("wallet_currency_in_use", "cannot change the currency of a wallet that has a balance or transactions")
+			}
+			wallet.Currency = currency
+		}
+		if req.Type != "" {
+			wallet.Type = req.Type
+		}
+		if req.AccountClass != "" {
+			wallet.AccountClass = req.AccountClass
+			wallet.AccountClassOverride = true
+		}
+		if req.CreditLimit != nil {
+			wallet.CreditLimit = req.CreditLimit
+		}
+		if req.ClearCreditLimit {
+			wallet.CreditLimit = nil
+		}
+		if req.StatementDay != nil {
+			wallet.StatementDay = nonZeroDay(req.StatementDay)
+		}
+		if req.DueDay != nil {
+			wallet.DueDay = nonZeroDay(req.DueDay)
+		}
+		if req.Icon != "" {
+			wallet.Icon = req.Icon
+		}
+		if req.Color != "" {
+			wallet.Color = req.Color
+		}
+		balance := wallet.Balance
+		if req.Balance != nil {
+			balance = req.Balance.WithCurrency(wallet.Currency)
+		}
+		if err := validateWalletLimits(wallet, balance); err != nil {
+			return err
+		}
+
+		if err := repos.Wallets.Update(ctx, wallet); err != nil {
+			return fmt.Errorf("failed to update wallet: %w", err)
+		}
 
-	if err := s.walletRepo.Update(wallet); err != nil {
-		return nil, fmt.Errorf("failed to update wallet: %w", err)
+		// A new balance is booked as a manual adjustment of the difference
+		// instead of overwriting the balance
+		delta := balance.Sub(wallet.Balance)
+		if !delta.IsZero() {
+			if err := postJournalEntry(ctx, repos, adjustmentEntry(wallet, delta, domain.AccountAdjustment, "Manual balance adjustment")); err != nil {
+				return err
+			}
+		}
+		wallet.Balance = balance
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return wallet, nil
 }
 
-func (s *WalletService) DeleteWallet(walletID int, userID int) error {
-	// Get wallet and verify ownership
-	wallet, err := s.GetWalletByID(walletID, userID)
+func (s *WalletService) DeleteWallet(ctx context.Context, walletID int, userID int) error {
+	return s.uow.Do(ctx, func(repos domain.Repositories) error {
+		// Get wallet and verify ownership
+		wallet, err := findOwnedWallet(ctx, repos.Wallets, walletID, userID)
+		if err != nil {
+			return err
+		}
+
+		// Check if wallet has transactions
+		transactions, err := repos.Transactions.FindByWalletID(ctx, wallet.ID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch transactions: %w", err)
+		}
+		if len(transactions) > 0 {
+			return domain.Conflict("wallet_has_transactions", "cannot delete wallet with existing transactions")
+		}
+
+		// Only opening balance and adjustment entries remain at this point
+		if err := repos.Ledger.DeleteByWalletID(ctx, walletID); err != nil {
+			return err
+		}
+
+		if err := repos.Wallets.Delete(ctx, walletID); err != nil {
+			return fmt.Errorf("failed to delete wallet: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetTotalBalance sums the balances of the wallets that count toward net
+// worth in the user's base currency, at today's rates
+func (s *WalletService) GetTotalBalance(ctx context.Context, userID int) (domain.Money, error) {
+	wallets, err := s.walletRepo.FindByUserID(ctx, userID)
 	if err != nil {
-		return err
+		return domain.Money{}, fmt.Errorf("failed to fetch wallets: %w", err)
 	}
 
-	// Check if wallet has transactions
-	transactions, err := s.transactionRepo.FindByWalletID(wallet.ID)
-	if err == nil && len(transactions) > 0 {
-		return fmt.Errorf("cannot delete wallet with existing transactions")
+	converter, err := s.exchangeRateService.converter(ctx, userID)
+	if err != nil {
+		return domain.Money{}, err
 	}
 
-	if err := s.walletRepo.Delete(walletID); err != nil {
-		return fmt.Errorf("failed to delete wallet: %w", err)
+	registry, err := s.walletTypeService.registry(ctx, userID)
+	if err != nil {
+		return domain.Money{}, err
 	}
 
-	return nil
-}
-
-func (s *WalletService) GetTotalBalance(userID int) (float64, error) {
-	wallets, err := s.walletRepo.FindByUserID(userID)
-	if err != nil {
-		return 0, fmt.Errorf("failed to fetch wallets: %w", err)
+	total := domain.NewMoney(0, converter.base)
+	today := time.Now()
+	for i := range wallets {
+		wallet := &wallets[i]
+		if !registry.lookup(wallet).IncludeInNetWorth {
+			continue
+		}
+		balance, err := converter.convert(ctx, wallet.Balance, wallet.Currency, today)
+		if err != nil {
+			return domain.Money{}, fmt.Errorf("failed to convert wallet balance: %w", err)
+		}
+		total = total.Add(balance)
 	}
 
-	var total float64
-	for _, wallet := range wallets {
-		total += wallet.Balance
+	if missing := converter.missingRates(); len(missing) > 0 {
+		return domain.Money{}, domain.Conflict("missing_exchange_rates", "missing exchange rates for %s", strings.Join(missing, ", "))
 	}
 
 	return total, nil
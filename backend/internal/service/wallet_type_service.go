@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-moneyku/internal/domain"
+	"go-moneyku/internal/validation"
+)
+
+// otherWalletGroup is the display group of wallets whose type is not registered
+const otherWalletGroup = "Lainnya"
+
+type WalletTypeService struct {
+	walletTypeRepo domain.WalletTypeRepository
+}
+
+func NewWalletTypeService(walletTypeRepo domain.WalletTypeRepository) *WalletTypeService {
+	return &WalletTypeService{
+		walletTypeRepo: walletTypeRepo,
+	}
+}
+
+type CreateWalletTypeRequest struct {
+	Key               string              `json:"key" validate:"max=50"`
+	Name              string              `json:"name" validate:"max=100"`
+	AccountClass      domain.AccountClass `json:"account_class" validate:"omitempty,oneof=asset liability"` // Defaults to asset
+	IncludeInNetWorth *bool               `json:"include_in_net_worth"`                                     // Defaults to true
+	Group             string              `json:"group" validate:"max=100"`
+}
+
+// UpdateWalletTypeRequest holds the fields to change; empty fields keep their
+// current value
+type UpdateWalletTypeRequest struct {
+	Name              string              `json:"name" validate:"max=100"`
+	AccountClass      domain.AccountClass `json:"account_class" validate:"omitempty,oneof=asset liability"`
+	IncludeInNetWorth *bool               `json:"include_in_net_worth"`
+	Group             string              `json:"group" validate:"max=100"`
+}
+
+// CreateWalletType adds a type of the user. Using the key of a system type
+// overrides that type for the user.
+func (s *WalletTypeService) CreateWalletType(ctx context.Context, userID int, req CreateWalletTypeRequest) (*domain.WalletType, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	key := domain.WalletTypeKey(req.Key)
+	if key == "" {
+		key = domain.WalletTypeKey(req.Name)
+	}
+	if key == "" {
+		return nil, domain.Invalid("wallet type key is required")
+	}
+	if len(key) > 50 {
+		// The key comes from the name when none is given
+		return nil, domain.Invalid("wallet type key must be at most 50 characters")
+	}
+	if req.AccountClass == "" {
+		req.AccountClass = domain.AccountClassAsset
+	}
+
+	registry, err := s.registry(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing, ok := registry.types[key]; ok && !existing.IsSystem() {
+		return nil, domain.Conflict("wallet_type_exists", "wallet type already exists")
+	}
+
+	walletType := &domain.WalletType{
+		UserID:            &userID,
+		Key:               key,
+		Name:              strings.TrimSpace(req.Name),
+		AccountClass:      req.AccountClass,
+		IncludeInNetWorth: req.IncludeInNetWorth == nil || *req.IncludeInNetWorth,
+		Group:             strings.TrimSpace(req.Group),
+	}
+	if walletType.Name == "" {
+		walletType.Name = strings.TrimSpace(req.Key)
+	}
+	if err := validateWalletType(walletType); err != nil {
+		return nil, err
+	}
+
+	if err := s.walletTypeRepo.Create(ctx, walletType); err != nil {
+		return nil, fmt.Errorf("failed to create wallet type: %w", err)
+	}
+
+	return walletType, nil
+}
+
+// GetWalletTypes lists the types that apply to the user: the user's own types
+// and the system types they do not override
+func (s *WalletTypeService) GetWalletTypes(ctx context.Context, userID int) ([]domain.WalletType, error) {
+	registry, err := s.registry(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	walletTypes := make([]domain.WalletType, 0, len(registry.types))
+	for _, walletType := range registry.types {
+		walletTypes = append(walletTypes, walletType)
+	}
+	sort.Slice(walletTypes, func(i, j int) bool {
+		if walletTypes[i].Group != walletTypes[j].Group {
+			return walletTypes[i].Group < walletTypes[j].Group
+		}
+		return walletTypes[i].Name < walletTypes[j].Name
+	})
+
+	return walletTypes, nil
+}
+
+// UpdateWalletType changes one of the user's own types; system types are
+// changed by creating a user type with the same key
+func (s *WalletTypeService) UpdateWalletType(ctx context.Context, walletTypeID int, userID int, req UpdateWalletTypeRequest) (*domain.WalletType, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	walletType, err := s.findOwnedWalletType(ctx, walletTypeID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		walletType.Name = name
+	}
+	if req.AccountClass != "" {
+		walletType.AccountClass = req.AccountClass
+	}
+	if req.IncludeInNetWorth != nil {
+		walletType.IncludeInNetWorth = *req.IncludeInNetWorth
+	}
+	if group := strings.TrimSpace(req.Group); group != "" {
+		walletType.Group = group
+	}
+
+	if err := validateWalletType(walletType); err != nil {
+		return nil, err
+	}
+
+	if err := s.walletTypeRepo.Update(ctx, walletType); err != nil {
+		return nil, fmt.Errorf("failed to update wallet type: %w", err)
+	}
+
+	return walletType, nil
+}
+
+// DeleteWalletType deletes one of the user's own types. Wallets of that type
+// fall back to the system type with the same key, if any.
+func (s *WalletTypeService) DeleteWalletType(ctx context.Context, walletTypeID int, userID int) error {
+	walletType, err := s.findOwnedWalletType(ctx, walletTypeID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.walletTypeRepo.Delete(ctx, walletType.ID); err != nil {
+		return fmt.Errorf("failed to delete wallet type: %w", err)
+	}
+
+	return nil
+}
+
+func (s *WalletTypeService) findOwnedWalletType(ctx context.Context, walletTypeID int, userID int) (*domain.WalletType, error) {
+	walletType, err := s.walletTypeRepo.FindByID(ctx, walletTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if walletType.IsSystem() {
+		return nil, domain.Invalid("system wallet types cannot be changed")
+	}
+	if *walletType.UserID != userID {
+		return nil, domain.Forbidden("wallet type")
+	}
+	return walletType, nil
+}
+
+func validateWalletType(walletType *domain.WalletType) error {
+	if walletType.Name == "" {
+		return domain.Invalid("wallet type name is required")
+	}
+	switch walletType.AccountClass {
+	case domain.AccountClassAsset, domain.AccountClassLiability:
+	default:
+		return domain.Invalid("invalid account class (use asset or liability)")
+	}
+	return nil
+}
+
+// registry loads the wallet types that apply to the user
+func (s *WalletTypeService) registry(ctx context.Context, userID int) (*walletTypeRegistry, error) {
+	walletTypes, err := s.walletTypeRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wallet types: %w", err)
+	}
+
+	registry := &walletTypeRegistry{types: make(map[string]domain.WalletType, len(walletTypes))}
+	for _, walletType := range walletTypes {
+		// A user type replaces the system type with the same key
+		if existing, ok := registry.types[walletType.Key]; ok && !existing.IsSystem() {
+			continue
+		}
+		registry.types[walletType.Key] = walletType
+	}
+	return registry, nil
+}
+
+// walletTypeRegistry resolves the free-text type of a wallet to its rules
+type walletTypeRegistry struct {
+	types map[string]domain.WalletType
+}
+
+// lookup returns the registered type of a wallet. Unregistered types count
+// toward net worth by the wallet's own class, in the "Lainnya" group.
+func (r *walletTypeRegistry) lookup(wallet *domain.Wallet) domain.WalletType {
+	walletType, ok := r.types[domain.WalletTypeKey(wallet.Type)]
+	if !ok {
+		return domain.WalletType{
+			Key:               domain.WalletTypeKey(wallet.Type),
+			Name:              wallet.Type,
+			AccountClass:      wallet.AccountClass,
+			IncludeInNetWorth: true,
+			Group:             otherWalletGroup,
+		}
+	}
+	if walletType.Group == "" {
+		walletType.Group = otherWalletGroup
+	}
+	return walletType
+}
+
+// accountClass returns the class a wallet counts toward net worth with: the
+// one chosen for the wallet, or else the current class of its type, which may
+// have changed since the wallet was created
+func (r *walletTypeRegistry) accountClass(wallet *domain.Wallet) domain.AccountClass {
+	if wallet.AccountClassOverride {
+		return wallet.AccountClass
+	}
+	return r.lookup(wallet).AccountClass
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go-moneyku/internal/domain"
+)
+
+func TestWalletTypeRegistryAccountClass(t *testing.T) {
+	userID := 1
+	registry := &walletTypeRegistry{types: map[string]domain.WalletType{
+		// The user turned PayLater into a liability after creating wallets of it
+		"paylater": {UserID: &userID, Key: "paylater", Name: "PayLater", AccountClass: domain.AccountClassLiability},
+		"tabungan": {Key: "tabungan", Name: "Tabungan", AccountClass: domain.AccountClassAsset},
+	}}
+
+	tests := []struct {
+		name   string
+		wallet domain.Wallet
+		want   domain.AccountClass
+	}{
+		{
+			name:   "class copied at creation follows the type",
+			wallet: domain.Wallet{Type: "PayLater", AccountClass: domain.AccountClassAsset},
+			want:   domain.AccountClassLiability,
+		},
+		{
+			name:   "class chosen for the wallet",
+			wallet: domain.Wallet{Type: "PayLater", AccountClass: domain.AccountClassAsset, AccountClassOverride: true},
+			want:   domain.AccountClassAsset,
+		},
+		{
+			name:   "registered asset type",
+			wallet: domain.Wallet{Type: " tabungan", AccountClass: domain.AccountClassAsset},
+			want:   domain.AccountClassAsset,
+		},
+		{
+			name:   "unregistered type keeps the wallet class",
+			wallet: domain.Wallet{Type: "Pinjaman teman", AccountClass: domain.AccountClassLiability},
+			want:   domain.AccountClassLiability,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := registry.accountClass(&tt.wallet); got != tt.want {
+				t.Errorf("accountClass = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateWalletType(t *testing.T) {
+	userID := 1
+	stored := domain.WalletType{ID: 7, UserID: &userID, Key: "paylater", Name: "PayLater", AccountClass: domain.AccountClassLiability, IncludeInNetWorth: true, Group: "Utang"}
+	excluded := false
+
+	tests := []struct {
+		name    string
+		req     UpdateWalletTypeRequest
+		want    domain.WalletType
+		wantErr bool
+	}{
+		{
+			name: "empty fields keep their value",
+			req:  UpdateWalletTypeRequest{Name: "  "},
+			want: stored,
+		},
+		{
+			name: "every field",
+			req:  UpdateWalletTypeRequest{Name: " Kredit ", AccountClass: domain.AccountClassAsset, IncludeInNetWorth: &excluded, Group: " Lain "},
+			want: domain.WalletType{ID: 7, UserID: &userID, Key: "paylater", Name: "Kredit", AccountClass: domain.AccountClassAsset, IncludeInNetWorth: false, Group: "Lain"},
+		},
+		{
+			name:    "unknown account class",
+			req:     UpdateWalletTypeRequest{AccountClass: "equity"},
+			wantErr: true,
+		},
+		{
+			name:    "group too long",
+			req:     UpdateWalletTypeRequest{Group: strings.Repeat("a", 101)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeWalletTypeRepository{walletType: stored}
+			service := NewWalletTypeService(repo)
+
+			got, err := service.UpdateWalletType(context.Background(), stored.ID, userID, tt.req)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrValidation) {
+					t.Fatalf("got error %v, want a validation error", err)
+				}
+				if repo.walletType != stored {
+					t.Errorf("stored type changed to %+v", repo.walletType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != tt.want || repo.walletType != tt.want {
+				t.Errorf("got %+v, stored %+v, want %+v", *got, repo.walletType, tt.want)
+			}
+		})
+	}
+}
+
+// fakeWalletTypeRepository holds a single wallet type
+type fakeWalletTypeRepository struct {
+	walletType domain.WalletType
+}
+
+func (r *fakeWalletTypeRepository) Create(ctx context.Context, walletType *domain.WalletType) error {
+	r.walletType = *walletType
+	return nil
+}
+
+func (r *fakeWalletTypeRepository) FindByUserID(ctx context.Context, userID int) ([]domain.WalletType, error) {
+	return []domain.WalletType{r.walletType}, nil
+}
+
+func (r *fakeWalletTypeRepository) FindByID(ctx context.Context, id int) (*domain.WalletType, error) {
+	if id != r.walletType.ID {
+		return nil, domain.NotFound("wallet type")
+	}
+	walletType := r.walletType
+	return &walletType, nil
+}
+
+func (r *fakeWalletTypeRepository) Update(ctx context.Context, walletType *domain.WalletType) error {
+	r.walletType = *walletType
+	return nil
+}
+
+func (r *fakeWalletTypeRepository) Delete(ctx context.Context, id int) error {
+	return nil
+}
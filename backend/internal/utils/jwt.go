@@ -8,9 +8,14 @@ import (
 
 var jwtSecret = []byte("your-secret-key-change-this-in-production")
 
+// accessTokenTTL is kept short because an access token is only revoked
+// through its session; a refresh token renews it
+var accessTokenTTL = 15 * time.Minute
+
 type Claims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	SessionID int    `json:"session_id"`
 	jwt.RegisteredClaims
 }
 
@@ -19,26 +24,39 @@ func SetJWTSecret(secret string) {
 	jwtSecret = []byte(secret)
 }
 
-// GenerateToken generates a new JWT token for a user
-func GenerateToken(userID int, username string) (string, error) {
+// SetAccessTokenTTL sets how long access tokens are valid from config
+func SetAccessTokenTTL(ttl time.Duration) {
+	accessTokenTTL = ttl
+}
+
+// GenerateToken generates a new JWT access token for a session of a user and
+// returns when it expires
+func GenerateToken(userID int, username string, sessionID int) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(accessTokenTTL)
 	claims := Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	signed, err := token.SignedString(jwtSecret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
 }
 
 // ValidateToken validates a JWT token and returns the claims
 func ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		return jwtSecret, nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
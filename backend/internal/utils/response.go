@@ -1,7 +1,15 @@
 package utils
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"reflect"
+
+	"go-moneyku/internal/domain"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,6 +19,9 @@ type Response struct {
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"` // Machine-readable error code, such as "wallet_not_found"
+	// Errors lists the invalid fields of the request body, so a form can mark them
+	Errors []domain.FieldError `json:"errors,omitempty"`
 }
 
 // SuccessResponse sends a successful JSON response
@@ -23,29 +34,115 @@ func SuccessResponse(c *gin.Context, statusCode int, message string, data interf
 }
 
 // ErrorResponse sends an error JSON response
-func ErrorResponse(c *gin.Context, statusCode int, message string) {
+func ErrorResponse(c *gin.Context, statusCode int, code string, message string) {
 	c.JSON(statusCode, Response{
 		Success: false,
 		Error:   message,
+		Code:    code,
 	})
 }
 
 // ValidationErrorResponse sends a validation error response
 func ValidationErrorResponse(c *gin.Context, message string) {
-	ErrorResponse(c, http.StatusBadRequest, message)
+	ErrorResponse(c, http.StatusBadRequest, "validation_failed", message)
 }
 
 // UnauthorizedResponse sends an unauthorized error response
 func UnauthorizedResponse(c *gin.Context, message string) {
-	ErrorResponse(c, http.StatusUnauthorized, message)
+	ErrorResponse(c, http.StatusUnauthorized, "unauthenticated", message)
 }
 
 // NotFoundResponse sends a not found error response
 func NotFoundResponse(c *gin.Context, message string) {
-	ErrorResponse(c, http.StatusNotFound, message)
+	ErrorResponse(c, http.StatusNotFound, "not_found", message)
 }
 
 // InternalErrorResponse sends an internal server error response
 func InternalErrorResponse(c *gin.Context, message string) {
-	ErrorResponse(c, http.StatusInternalServerError, message)
+	ErrorResponse(c, http.StatusInternalServerError, "internal_error", message)
+}
+
+// ServiceErrorResponse sends the status and code that match an error returned
+// by a service. Errors that are not a *domain.Error are logged and reported
+// without their text, so database details never reach the client.
+func ServiceErrorResponse(c *gin.Context, err error) {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		c.JSON(errorStatus(domainErr.Kind), Response{
+			Success: false,
+			Error:   err.Error(),
+			Code:    domainErr.Code,
+			Errors:  domainErr.Fields,
+		})
+		return
+	}
+
+	log.Printf("%s %s failed: %v", c.Request.Method, c.Request.URL.Path, err)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		ErrorResponse(c, http.StatusGatewayTimeout, "timeout", "The request took too long, please try again")
+	case errors.Is(err, context.Canceled):
+		// The client is gone; the status is only seen in the logs
+		ErrorResponse(c, 499, "canceled", "The request was canceled")
+	default:
+		InternalErrorResponse(c, "Internal server error")
+	}
+}
+
+// BindErrorResponse reports a request body that could not be decoded. A value
+// of the wrong JSON type is reported against its field.
+func BindErrorResponse(c *gin.Context, err error) {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field == "" {
+		ValidationErrorResponse(c, "Invalid request body")
+		return
+	}
+
+	fieldErr := domain.FieldError{
+		Field:   typeErr.Field,
+		Code:    "invalid_type",
+		Message: fmt.Sprintf("%s must be a %s, not a %s", typeErr.Field, jsonTypeName(typeErr.Type), typeErr.Value),
+	}
+	c.JSON(http.StatusBadRequest, Response{
+		Success: false,
+		Error:   fieldErr.Message,
+		Code:    "validation_failed",
+		Errors:  []domain.FieldError{fieldErr},
+	})
+}
+
+// jsonTypeName names the JSON type expected for a Go type
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return t.Kind().String()
+	}
+}
+
+// errorStatus maps a kind of domain error to its HTTP status
+func errorStatus(kind error) int {
+	switch kind {
+	case domain.ErrNotFound:
+		return http.StatusNotFound
+	case domain.ErrForbidden:
+		return http.StatusForbidden
+	case domain.ErrConflict:
+		return http.StatusConflict
+	case domain.ErrInsufficientFunds:
+		return http.StatusUnprocessableEntity
+	case domain.ErrUnauthenticated:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusBadRequest
+	}
 }
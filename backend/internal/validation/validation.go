@@ -0,0 +1,144 @@
+// Package validation checks requests against the rules declared in their
+// `validate` struct tags and reports every invalid field at once
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"go-moneyku/internal/domain"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = newValidator()
+
+// Struct validates req and returns a *domain.Error listing the invalid
+// fields, or nil when req is valid
+func Struct(req any) error {
+	err := validate.Struct(req)
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+
+	fields := make([]domain.FieldError, len(invalid))
+	for i, fieldErr := range invalid {
+		fields[i] = domain.FieldError{
+			Field:   fieldErr.Field(),
+			Code:    errorCode(fieldErr),
+			Message: errorMessage(fieldErr),
+		}
+	}
+	return &domain.Error{
+		Kind:    domain.ErrValidation,
+		Code:    "validation_failed",
+		Message: fields[0].Message,
+		Fields:  fields,
+	}
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Fields are reported by their JSON name, which is what clients send
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	// Money is compared in minor units, so "gt=0" means a positive amount
+	v.RegisterCustomTypeFunc(func(value reflect.Value) interface{} {
+		if money, ok := value.Interface().(domain.Money); ok {
+			return money.Minor()
+		}
+		return nil
+	}, domain.Money{})
+
+	// currency accepts ISO 4217 codes in any case; services normalize them
+	v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return v.Var(strings.ToUpper(fl.Field().String()), "iso4217") == nil
+	})
+
+	// date accepts a day (YYYY-MM-DD) or a full RFC3339 timestamp
+	v.RegisterValidation("date", func(fl validator.FieldLevel) bool {
+		value := fl.Field().String()
+		if _, err := time.Parse("2006-01-02", value); err == nil {
+			return true
+		}
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	})
+
+	return v
+}
+
+func errorCode(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required", "required_if":
+		return "required"
+	case "oneof":
+		return "invalid_choice"
+	case "gt", "gte", "min":
+		if fieldErr.Kind() == reflect.String {
+			return "too_short"
+		}
+		return "too_small"
+	case "lt", "lte", "max":
+		if fieldErr.Kind() == reflect.String {
+			return "too_long"
+		}
+		return "too_large"
+	case "currency":
+		return "invalid_currency"
+	case "date", "datetime":
+		return "invalid_date"
+	default:
+		return "invalid"
+	}
+}
+
+func errorMessage(fieldErr validator.FieldError) string {
+	field := fieldErr.Field()
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required", "required_if":
+		return field + " is required"
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(param, " ", ", "))
+	case "gt":
+		if param == "0" {
+			return field + " must be greater than zero"
+		}
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "gte":
+		if param == "0" {
+			return field + " cannot be negative"
+		}
+		return fmt.Sprintf("%s must be at least %s", field, param)
+	case "min":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at least %s characters", field, param)
+		}
+		return fmt.Sprintf("%s must be at least %s", field, param)
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at most %s characters", field, param)
+		}
+		return fmt.Sprintf("%s must be at most %s", field, param)
+	case "currency":
+		return field + " must be a three letter ISO 4217 code such as IDR or USD"
+	case "date":
+		return field + " must be a date in YYYY-MM-DD or RFC3339 format"
+	case "datetime":
+		return field + " must be a date in YYYY-MM-DD format"
+	default:
+		return field + " is invalid"
+	}
+}
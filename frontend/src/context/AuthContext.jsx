@@ -27,10 +27,11 @@ export const AuthProvider = ({ children }) => {
       const response = await authService.login(username, password);
       
       if (response.success) {
-        const { token, user } = response.data;
+        const { token, refresh_token, user } = response.data;
         
-        // Store token and user info
+        // Store tokens and user info
         localStorage.setItem('token', token);
+        localStorage.setItem('refresh_token', refresh_token);
         localStorage.setItem('user', JSON.stringify(user));
         
         setUser(user);
@@ -52,10 +53,11 @@ export const AuthProvider = ({ children }) => {
       const response = await authService.signup(username, password);
       
       if (response.success) {
-        const { token, user } = response.data;
+        const { token, refresh_token, user } = response.data;
         
-        // Store token and user info
+        // Store tokens and user info
         localStorage.setItem('token', token);
+        localStorage.setItem('refresh_token', refresh_token);
         localStorage.setItem('user', JSON.stringify(user));
         
         setUser(user);
@@ -72,8 +74,13 @@ export const AuthProvider = ({ children }) => {
     }
   };
 
-  const logout = () => {
-    authService.logout();
+  const logout = async () => {
+    try {
+      await authService.logout();
+    } catch (error) {
+      // The session is forgotten locally even if the server cannot be reached
+      console.error('Logout error:', error);
+    }
     setUser(null);
   };
 
@@ -1,11 +1,13 @@
-import React, { createContext, useContext, useState, useEffect } from 'react';
+import React, { createContext, useContext, useState, useEffect, useRef } from 'react';
 import { useAuth } from './AuthContext';
 import { useWallet } from './WalletContext';
 import transactionService from '../services/transactionService';
-import { isWithinInterval, parseISO } from 'date-fns';
 
 const TransactionContext = createContext();
 
+// Transactions are listed a page at a time; more are loaded on demand
+const PAGE_SIZE = 50;
+
 export const useTransaction = () => {
   const context = useContext(TransactionContext);
   if (!context) {
@@ -14,38 +16,112 @@ export const useTransaction = () => {
   return context;
 };
 
+// Loads every transaction dated from startDate to endDate (YYYY-MM-DD,
+// inclusive), optionally of one wallet, for views that total a period rather
+// than list it. Loads again after a transaction changes.
+export const usePeriodTransactions = (startDate, endDate, walletId) => {
+  const { revision } = useTransaction();
+  const [transactions, setTransactions] = useState([]);
+
+  useEffect(() => {
+    if (!startDate || !endDate) return;
+    let cancelled = false;
+    const filters = walletId ? { wallet_id: walletId } : {};
+    transactionService
+      .getTransactionsBetween(startDate, endDate, filters)
+      .then((response) => {
+        if (!cancelled && response.success) setTransactions(response.data);
+      })
+      .catch((err) => console.error('Failed to load transactions:', err));
+    return () => {
+      cancelled = true;
+    };
+  }, [startDate, endDate, walletId, revision]);
+
+  return transactions;
+};
+
 export const TransactionProvider = ({ children }) => {
   const { user } = useAuth();
   const { refreshWallets } = useWallet();
   const [transactions, setTransactions] = useState([]);
+  const [nextCursor, setNextCursor] = useState('');
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState(null);
+  // Bumped after every change, so views that total a period load it again
+  const [revision, setRevision] = useState(0);
+  const filtersRef = useRef({});
+  const requestRef = useRef(0);
 
-  // Load transactions when user is authenticated
   useEffect(() => {
-    if (user) {
-      loadTransactions();
-    } else {
+    if (!user) {
+      filtersRef.current = {};
       setTransactions([]);
+      setNextCursor('');
     }
   }, [user]);
 
-  const loadTransactions = async () => {
+  // Loads the first page of the list; filters are applied by the server and
+  // kept for loadMoreTransactions and the reload after a change
+  const loadTransactions = async (filters = filtersRef.current) => {
+    // Rows of other filters are not shown while the new list loads
+    if (filters !== filtersRef.current) {
+      filtersRef.current = filters;
+      setTransactions([]);
+      setNextCursor('');
+    }
+    const request = ++requestRef.current;
     try {
       setLoading(true);
       setError(null);
-      const response = await transactionService.getTransactions();
+      const response = await transactionService.getTransactions({ limit: PAGE_SIZE, ...filters });
+      // A newer load started meanwhile, with other filters
+      if (request !== requestRef.current) return;
       if (response.success) {
-        setTransactions(response.data || []);
+        setTransactions(response.data?.transactions || []);
+        setNextCursor(response.data?.next_cursor || '');
       }
     } catch (err) {
+      if (request !== requestRef.current) return;
       console.error('Failed to load transactions:', err);
       setError(err.response?.data?.error || 'Failed to load transactions');
     } finally {
-      setLoading(false);
+      if (request === requestRef.current) setLoading(false);
     }
   };
 
+  // Appends the next page of the list
+  const loadMoreTransactions = async () => {
+    if (!nextCursor || loading) return;
+    const request = ++requestRef.current;
+    try {
+      setLoading(true);
+      setError(null);
+      const response = await transactionService.getTransactions(
+        { limit: PAGE_SIZE, ...filtersRef.current },
+        nextCursor
+      );
+      if (request !== requestRef.current) return;
+      if (response.success) {
+        setTransactions(prev => [...prev, ...(response.data?.transactions || [])]);
+        setNextCursor(response.data?.next_cursor || '');
+      }
+    } catch (err) {
+      if (request !== requestRef.current) return;
+      console.error('Failed to load transactions:', err);
+      setError(err.response?.data?.error || 'Failed to load transactions');
+    } finally {
+      if (request === requestRef.current) setLoading(false);
+    }
+  };
+
+  // The list is reloaded rather than patched, so a change lands in its sorted
+  // place and only when it matches the filters
+  const transactionsChanged = async () => {
+    setRevision(prev => prev + 1);
+    await Promise.all([refreshWallets(), loadTransactions()]);
+  };
+
   // Add income
   const addIncome = async (transactionData) => {
     try {
@@ -61,9 +137,7 @@ export const TransactionProvider = ({ children }) => {
       });
       
       if (response.success) {
-        setTransactions(prev => [...prev, response.data]);
-        // Refresh wallets to update balance
-        await refreshWallets();
+        await transactionsChanged();
         return { success: true, data: response.data };
       }
       return { success: false, message: response.error };
@@ -92,9 +166,7 @@ export const TransactionProvider = ({ children }) => {
       });
       
       if (response.success) {
-        setTransactions(prev => [...prev, response.data]);
-        // Refresh wallets to update balance
-        await refreshWallets();
+        await transactionsChanged();
         return { success: true, data: response.data };
       }
       return { success: false, message: response.error };
@@ -124,9 +196,7 @@ export const TransactionProvider = ({ children }) => {
       });
       
       if (response.success) {
-        setTransactions(prev => [...prev, response.data]);
-        // Refresh wallets to update balances
-        await refreshWallets();
+        await transactionsChanged();
         return { success: true, data: response.data };
       }
       return { success: false, message: response.error };
@@ -148,9 +218,7 @@ export const TransactionProvider = ({ children }) => {
       const response = await transactionService.deleteTransaction(id);
       
       if (response.success) {
-        setTransactions(prev => prev.filter(t => t.id !== id));
-        // Refresh wallets to update balance
-        await refreshWallets();
+        await transactionsChanged();
         return { success: true };
       }
       return { success: false, message: response.error };
@@ -164,34 +232,18 @@ export const TransactionProvider = ({ children }) => {
     }
   };
 
-  const getRecentTransactions = (limit = 10) => {
-    return [...transactions]
-      .sort((a, b) => new Date(b.date) - new Date(a.date))
-      .slice(0, limit);
-  };
-
-  const calculatePeriodTotals = (startDate, endDate) => {
-    return transactions.reduce((acc, curr) => {
-      const date = parseISO(curr.date);
-      if (isWithinInterval(date, { start: startDate, end: endDate })) {
-        if (curr.type === 'income') acc.income += curr.amount;
-        if (curr.type === 'expense') acc.expense += curr.amount;
-      }
-      return acc;
-    }, { income: 0, expense: 0 });
-  };
-
   const value = {
     transactions,
+    hasMore: nextCursor !== '',
     loading,
     error,
+    revision,
+    loadTransactions,
+    loadMoreTransactions,
     addIncome,
     addExpense,
     addTransfer,
     deleteTransaction,
-    getRecentTransactions,
-    calculatePeriodTotals,
-    refreshTransactions: loadTransactions,
   };
 
   return (
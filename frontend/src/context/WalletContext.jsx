@@ -111,13 +111,13 @@ export const WalletProvider = ({ children }) => {
   };
 
   const getTotalBalance = () => {
-    return wallets.reduce((sum, wallet) => sum + wallet.balance, 0);
+    return wallets.reduce((sum, wallet) => sum + Number(wallet.balance), 0);
   };
 
   const getTotalByType = (type) => {
     return wallets
       .filter(wallet => wallet.type === type)
-      .reduce((sum, wallet) => sum + wallet.balance, 0);
+      .reduce((sum, wallet) => sum + Number(wallet.balance), 0);
   };
 
   const value = {
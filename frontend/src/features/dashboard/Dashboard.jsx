@@ -1,9 +1,9 @@
-import React, { useState } from 'react';
+import React, { useState, useEffect } from 'react';
 import { useNavigate } from 'react-router-dom';
 import { Wallet, TrendingUp, TrendingDown, PiggyBank, Plus } from 'lucide-react';
 import { useWallet } from '../../context/WalletContext';
-import { useTransaction } from '../../context/TransactionContext';
-import { formatCurrency } from '../../utils/formatters';
+import { useTransaction, usePeriodTransactions } from '../../context/TransactionContext';
+import { formatCurrency, formatDateForInput } from '../../utils/formatters';
 import { getDateRangePreset } from '../../utils/calculations';
 import StatCard from '../../shared/components/StatCard';
 import Card from '../../shared/components/Card';
@@ -30,7 +30,7 @@ ChartJS.register(ArcElement, Tooltip, Legend, CategoryScale, LinearScale, BarEle
 const Dashboard = () => {
   const navigate = useNavigate();
   const { wallets, getTotalByType } = useWallet();
-  const { getRecentTransactions, calculatePeriodTotals } = useTransaction();
+  const { transactions, loadTransactions } = useTransaction();
   
   const [isWalletModalOpen, setIsWalletModalOpen] = useState(false);
   const [isTransactionModalOpen, setIsTransactionModalOpen] = useState(false);
@@ -38,8 +38,21 @@ const Dashboard = () => {
 
   // Get current month data
   const { startDate, endDate } = getDateRangePreset('thisMonth');
-  const monthlyTotals = calculatePeriodTotals(startDate, endDate);
-  const recentTransactions = getRecentTransactions(5);
+  const monthTransactions = usePeriodTransactions(
+    formatDateForInput(startDate),
+    formatDateForInput(endDate)
+  );
+  const monthlyTotals = monthTransactions.reduce((acc, curr) => {
+    if (curr.type === 'income') acc.income += Number(curr.amount);
+    if (curr.type === 'expense') acc.expense += Number(curr.amount);
+    return acc;
+  }, { income: 0, expense: 0 });
+
+  // The first page of the unfiltered list is the newest transactions
+  useEffect(() => {
+    loadTransactions({});
+  }, []);
+  const recentTransactions = transactions.slice(0, 5);
 
   // Calculate stats
   const totalBalance = getTotalByType('tabungan');
@@ -162,7 +175,7 @@ const Dashboard = () => {
                     data={{
                       labels: wallets.map(w => w.name),
                       datasets: [{
-                        data: wallets.map(w => w.balance),
+                        data: wallets.map(w => Number(w.balance)),
                         backgroundColor: [
                           '#8b5cf6', '#10b981', '#f59e0b', '#ef4444', '#3b82f6'
                         ],
@@ -12,8 +12,8 @@ import {
   LineElement
 } from 'chart.js';
 import { Bar, Doughnut } from 'react-chartjs-2';
-import { useTransaction } from '../../context/TransactionContext';
-import { startOfMonth, endOfMonth, eachDayOfInterval, format, isSameDay, parseISO } from 'date-fns';
+import { usePeriodTransactions } from '../../context/TransactionContext';
+import { startOfMonth, endOfMonth, eachDayOfInterval, format, isSameDay, parseISO, subDays } from 'date-fns';
 import { formatDateForInput } from '../../utils/formatters';
 import { id } from 'date-fns/locale';
 import Input from '../../shared/components/Input';
@@ -34,20 +34,15 @@ ChartJS.register(
 );
 
 const ReportsPage = () => {
-  const { transactions } = useTransaction();
   const [startDate, setStartDate] = useState(formatDateForInput(startOfMonth(new Date())));
   const [endDate, setEndDate] = useState(formatDateForInput(endOfMonth(new Date())));
 
-  // Helper to filter transactions by current period
-  const filteredTransactions = useMemo(() => {
-    const start = parseISO(startDate);
-    const end = parseISO(endDate);
-    
-    return transactions.filter(t => {
-      const date = parseISO(t.date);
-      return date >= start && date <= end;
-    });
-  }, [transactions, startDate, endDate]);
+  // The server filters the transactions of the selected period
+  const filteredTransactions = usePeriodTransactions(startDate, endDate);
+  const weekTransactions = usePeriodTransactions(
+    formatDateForInput(subDays(new Date(), 6)),
+    formatDateForInput(new Date())
+  );
 
   // Chart 1: Income vs Expense (Bar Chart) - Last 7 Days
   const barChartData = useMemo(() => {
@@ -60,15 +55,15 @@ const ReportsPage = () => {
     const expenseData = [];
 
     days.forEach(day => {
-      const dayTransactions = transactions.filter(t => isSameDay(parseISO(t.date), day));
+      const dayTransactions = weekTransactions.filter(t => isSameDay(parseISO(t.date), day));
       
       const income = dayTransactions
         .filter(t => t.type === 'income')
-        .reduce((sum, t) => sum + t.amount, 0);
+        .reduce((sum, t) => sum + Number(t.amount), 0);
         
       const expense = dayTransactions
         .filter(t => t.type === 'expense')
-        .reduce((sum, t) => sum + t.amount, 0);
+        .reduce((sum, t) => sum + Number(t.amount), 0);
 
       incomeData.push(income);
       expenseData.push(expense);
@@ -91,7 +86,7 @@ const ReportsPage = () => {
         },
       ],
     };
-  }, [transactions]);
+  }, [weekTransactions]);
 
   // Chart 2: Expense by Category (Doughnut)
   const doughnutChartData = useMemo(() => {
@@ -100,7 +95,7 @@ const ReportsPage = () => {
 
     expenses.forEach(t => {
       const cat = t.category || 'Lainnya';
-      categories[cat] = (categories[cat] || 0) + t.amount;
+      categories[cat] = (categories[cat] || 0) + Number(t.amount);
     });
 
     return {
@@ -130,7 +125,7 @@ const ReportsPage = () => {
 
     incomes.forEach(t => {
       const cat = t.category || 'Lainnya';
-      categories[cat] = (categories[cat] || 0) + t.amount;
+      categories[cat] = (categories[cat] || 0) + Number(t.amount);
     });
 
     return {
@@ -1,4 +1,4 @@
-import React, { useState } from 'react';
+import React, { useState, useEffect } from 'react';
 import { Plus } from 'lucide-react';
 import { useTransaction } from '../../context/TransactionContext';
 import { useWallet } from '../../context/WalletContext';
@@ -9,14 +9,15 @@ import TransactionFormModal from './TransactionFormModal';
 import './TransactionsPage.css';
 
 const TransactionsPage = () => {
-  const { transactions } = useTransaction();
+  const { transactions, hasMore, loading, loadTransactions, loadMoreTransactions } = useTransaction();
   const { wallets } = useWallet();
   const [isModalOpen, setIsModalOpen] = useState(false);
   const [filterType, setFilterType] = useState('all'); // all, income, expense, transfer
 
-  const filteredTransactions = transactions
-    .filter(t => filterType === 'all' || t.type === filterType)
-    .sort((a, b) => new Date(b.date) - new Date(a.date));
+  // The server filters and sorts the list, newest first
+  useEffect(() => {
+    loadTransactions(filterType === 'all' ? {} : { type: filterType });
+  }, [filterType]);
 
   return (
     <div className="transactions-page">
@@ -62,14 +63,14 @@ const TransactionsPage = () => {
       </div>
 
       <div className="transactions-content">
-        {filteredTransactions.length === 0 ? (
+        {transactions.length === 0 && !loading ? (
           <Card className="empty-state">
             <h3>Tidak ada transaksi</h3>
             <p>Belum ada transaksi yang sesuai dengan filter ini</p>
           </Card>
         ) : (
           <div className="transactions-list">
-            {filteredTransactions.map(transaction => {
+            {transactions.map(transaction => {
               const wallet = wallets.find(
                 w => w.id === transaction.wallet_id
               );
@@ -83,6 +84,13 @@ const TransactionsPage = () => {
             })}
           </div>
         )}
+        {hasMore && (
+          <div className="transactions-load-more">
+            <Button variant="ghost" onClick={loadMoreTransactions} disabled={loading}>
+              {loading ? 'Memuat...' : 'Muat lebih banyak'}
+            </Button>
+          </div>
+        )}
       </div>
 
       <TransactionFormModal
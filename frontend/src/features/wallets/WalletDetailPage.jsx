@@ -1,9 +1,10 @@
-import React, { useState } from 'react';
+import React, { useState, useEffect } from 'react';
 import { useParams, useNavigate } from 'react-router-dom';
 import { ArrowLeft, TrendingUp, TrendingDown, Plus } from 'lucide-react';
 import { useWallet } from '../../context/WalletContext';
-import { useTransaction } from '../../context/TransactionContext';
-import { formatCurrency } from '../../utils/formatters';
+import { startOfMonth, endOfMonth } from 'date-fns';
+import { useTransaction, usePeriodTransactions } from '../../context/TransactionContext';
+import { formatCurrency, formatDateForInput } from '../../utils/formatters';
 import Card from '../../shared/components/Card';
 import Button from '../../shared/components/Button';
 import StatCard from '../../shared/components/StatCard';
@@ -16,12 +17,23 @@ const WalletDetailPage = () => {
   const { id } = useParams();
   const navigate = useNavigate();
   const { getWalletById, deleteWallet } = useWallet();
-  const { transactions } = useTransaction();
+  const { transactions, hasMore, loading, loadTransactions, loadMoreTransactions } = useTransaction();
   
   const [isEditModalOpen, setIsEditModalOpen] = useState(false);
   const [isTransactionModalOpen, setIsTransactionModalOpen] = useState(false);
 
   const wallet = getWalletById(id);
+
+  // The server lists the transactions of this wallet, including transfers in
+  useEffect(() => {
+    loadTransactions({ wallet_id: id });
+  }, [id]);
+
+  const monthTransactions = usePeriodTransactions(
+    formatDateForInput(startOfMonth(new Date())),
+    formatDateForInput(endOfMonth(new Date())),
+    id
+  );
   
   // Redirect if wallet not found
   if (!wallet) {
@@ -33,11 +45,6 @@ const WalletDetailPage = () => {
     );
   }
 
-  // Filter transactions for this wallet
-  const walletTransactions = transactions.filter(
-    t => t.wallet_id === parseInt(id) || t.to_wallet_id === parseInt(id)
-  ).sort((a, b) => new Date(b.date) - new Date(a.date));
-
   const handleDeleteWallet = async () => {
     if (window.confirm('Apakah Anda yakin ingin menghapus dompet ini? Semua riwayat transaksi akan tetap ada namun referensi dompet mungkin hilang.')) {
       const result = await deleteWallet(parseInt(id));
@@ -49,14 +56,14 @@ const WalletDetailPage = () => {
     }
   };
 
-  // Calculate stats
-  const totalIncome = walletTransactions
+  // Calculate stats for the current month
+  const totalIncome = monthTransactions
     .filter(t => t.type === 'income' && t.wallet_id === parseInt(id))
-    .reduce((sum, t) => sum + t.amount, 0);
+    .reduce((sum, t) => sum + Number(t.amount), 0);
 
-  const totalExpense = walletTransactions
+  const totalExpense = monthTransactions
     .filter(t => t.type === 'expense' && t.wallet_id === parseInt(id))
-    .reduce((sum, t) => sum + t.amount, 0);
+    .reduce((sum, t) => sum + Number(t.amount), 0);
 
   return (
     <div className="wallet-detail-page">
@@ -94,13 +101,13 @@ const WalletDetailPage = () => {
 
       <div className="wallet-stats-grid">
         <StatCard
-          label="Total Pemasukan"
+          label="Pemasukan Bulan Ini"
           value={formatCurrency(totalIncome)}
           icon={TrendingUp}
           variant="default"
         />
         <StatCard
-          label="Total Pengeluaran"
+          label="Pengeluaran Bulan Ini"
           value={formatCurrency(totalExpense)}
           icon={TrendingDown}
           variant="default"
@@ -109,13 +116,13 @@ const WalletDetailPage = () => {
 
       <div className="wallet-transactions-section">
         <h3>Riwayat Transaksi</h3>
-        {walletTransactions.length === 0 ? (
+        {transactions.length === 0 && !loading ? (
           <Card className="empty-transactions">
             <p>Belum ada transaksi di dompet ini</p>
           </Card>
         ) : (
           <div className="transactions-list">
-            {walletTransactions.map(transaction => (
+            {transactions.map(transaction => (
               <TransactionItem
                 key={transaction.id}
                 transaction={transaction}
@@ -124,6 +131,13 @@ const WalletDetailPage = () => {
             ))}
           </div>
         )}
+        {hasMore && (
+          <div className="transactions-load-more">
+            <Button variant="ghost" onClick={loadMoreTransactions} disabled={loading}>
+              {loading ? 'Memuat...' : 'Muat lebih banyak'}
+            </Button>
+          </div>
+        )}
       </div>
 
       <WalletFormModal
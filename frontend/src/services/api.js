@@ -24,17 +24,78 @@ api.interceptors.request.use(
   }
 );
 
+const clearSession = () => {
+  localStorage.removeItem("token");
+  localStorage.removeItem("refresh_token");
+  localStorage.removeItem("user");
+  window.location.href = "/login";
+};
+
+// Refresh tokens can be used once, so concurrent requests share one refresh
+let refreshing = null;
+
+const requestRefresh = (staleRefreshToken) => {
+  // Another tab refreshed while this one waited for the lock: reuse its token
+  const current = localStorage.getItem("refresh_token");
+  if (current !== staleRefreshToken) {
+    return Promise.resolve(localStorage.getItem("token"));
+  }
+
+  return axios
+    .post(`${API_BASE_URL}/auth/refresh`, { refresh_token: current })
+    .then((response) => {
+      const { token, refresh_token } = response.data.data;
+      localStorage.setItem("token", token);
+      localStorage.setItem("refresh_token", refresh_token);
+      return token;
+    });
+};
+
+const refreshToken = () => {
+  if (!refreshing) {
+    const staleRefreshToken = localStorage.getItem("refresh_token");
+
+    // Tabs share localStorage, so the lock makes them refresh one at a time
+    const refresh = navigator.locks
+      ? navigator.locks.request("moneyku-token-refresh", () =>
+          requestRefresh(staleRefreshToken)
+        )
+      : requestRefresh(staleRefreshToken);
+
+    refreshing = refresh.finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+};
+
 // Response interceptor to handle errors
 api.interceptors.response.use(
   (response) => {
     return response;
   },
-  (error) => {
-    if (error.response?.status === 401) {
-      // Token expired or invalid
-      localStorage.removeItem("token");
-      localStorage.removeItem("user");
-      window.location.href = "/login";
+  async (error) => {
+    const original = error.config;
+    if (error.response?.status === 401 && original && !original._retried) {
+      if (!localStorage.getItem("refresh_token")) {
+        clearSession();
+        return Promise.reject(error);
+      }
+
+      // Access token expired: refresh it and retry the request once. When
+      // another tab has already stored a newer token, that one is used.
+      original._retried = true;
+      try {
+        const sent = original.headers.Authorization;
+        const stored = localStorage.getItem("token");
+        const token =
+          stored && sent !== `Bearer ${stored}` ? stored : await refreshToken();
+        original.headers.Authorization = `Bearer ${token}`;
+        return api(original);
+      } catch (refreshError) {
+        clearSession();
+        return Promise.reject(refreshError);
+      }
     }
     return Promise.reject(error);
   }
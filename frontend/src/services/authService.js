@@ -16,9 +16,14 @@ export const authService = {
     return response.data;
   },
 
-  logout() {
-    localStorage.removeItem("token");
-    localStorage.removeItem("user");
+  async logout() {
+    try {
+      await api.post("/auth/logout");
+    } finally {
+      localStorage.removeItem("token");
+      localStorage.removeItem("refresh_token");
+      localStorage.removeItem("user");
+    }
   },
 };
 
@@ -8,8 +8,13 @@ export const reportService = {
     return response.data;
   },
 
-  async exportTransactions() {
-    const response = await api.get("/reports/export");
+  // Downloads a file; format is csv, xlsx, ofx or json and filters takes the
+  // same keys as the transaction list (start_date, end_date, wallet_id, ...)
+  async exportTransactions(format = "csv", filters = {}) {
+    const response = await api.get("/reports/export", {
+      params: { ...filters, format },
+      responseType: "blob",
+    });
     return response.data;
   },
 };
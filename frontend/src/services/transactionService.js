@@ -1,16 +1,37 @@
 import api from "./api";
 
 export const transactionService = {
-  async getTransactions(filters = {}) {
+  async getTransactions(filters = {}, cursor = "") {
     const params = new URLSearchParams();
-    if (filters.wallet_id) params.append("wallet_id", filters.wallet_id);
-    if (filters.start_date) params.append("start_date", filters.start_date);
-    if (filters.end_date) params.append("end_date", filters.end_date);
+    Object.entries(filters).forEach(([key, value]) => {
+      if (value !== undefined && value !== null && value !== "") {
+        params.append(key, value);
+      }
+    });
+    if (cursor) params.append("cursor", cursor);
 
     const response = await api.get(`/transactions?${params.toString()}`);
     return response.data;
   },
 
+  // Loads every transaction dated within the range (YYYY-MM-DD, inclusive),
+  // for views that total a period rather than list it
+  async getTransactionsBetween(startDate, endDate, filters = {}) {
+    const transactions = [];
+    let cursor = "";
+    do {
+      const response = await this.getTransactions(
+        { ...filters, limit: 200, start_date: startDate, end_date: endDate },
+        cursor
+      );
+      if (!response.success) return response;
+      transactions.push(...(response.data?.transactions || []));
+      cursor = response.data?.next_cursor || "";
+    } while (cursor);
+
+    return { success: true, data: transactions };
+  },
+
   async createTransaction(transactionData) {
     const response = await api.post("/transactions", transactionData);
     return response.data;
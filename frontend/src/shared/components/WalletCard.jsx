@@ -29,7 +29,7 @@ const WalletCard = ({
 
   const getUsagePercentage = () => {
     if (!wallet.budget || wallet.budget === 0) return 0;
-    return Math.min((wallet.balance / wallet.budget) * 100, 100);
+    return Math.min((Number(wallet.balance) / wallet.budget) * 100, 100);
   };
 
   const usagePercentage = getUsagePercentage();
@@ -8,14 +8,14 @@ import {
 
 // Calculate total balance from all wallets
 export const calculateTotalBalance = (wallets) => {
-  return wallets.reduce((total, wallet) => total + (wallet.balance || 0), 0);
+  return wallets.reduce((total, wallet) => total + Number(wallet.balance || 0), 0);
 };
 
 // Calculate total for a specific wallet type
 export const calculateBalanceByType = (wallets, type) => {
   return wallets
     .filter((wallet) => wallet.type === type)
-    .reduce((total, wallet) => total + (wallet.balance || 0), 0);
+    .reduce((total, wallet) => total + Number(wallet.balance || 0), 0);
 };
 
 // Filter transactions by date range
@@ -89,9 +89,9 @@ export const calculateTotals = (transactions) => {
   return transactions.reduce(
     (acc, transaction) => {
       if (transaction.type === "income") {
-        acc.income += transaction.amount;
+        acc.income += Number(transaction.amount);
       } else if (transaction.type === "expense") {
-        acc.expense += transaction.amount;
+        acc.expense += Number(transaction.amount);
       }
       return acc;
     },
@@ -116,7 +116,7 @@ export const groupTransactionsByWallet = (transactions, wallets) => {
       }
       grouped[walletId].transactions.push(transaction);
       if (transaction.type === "expense") {
-        grouped[walletId].total += transaction.amount;
+        grouped[walletId].total += Number(transaction.amount);
       }
     }
   });
@@ -193,9 +193,9 @@ export const generateMonthlyTrendData = (transactions) => {
     }
 
     if (transaction.type === "income") {
-      monthlyData[monthKey].income += transaction.amount;
+      monthlyData[monthKey].income += Number(transaction.amount);
     } else if (transaction.type === "expense") {
-      monthlyData[monthKey].expense += transaction.amount;
+      monthlyData[monthKey].expense += Number(transaction.amount);
     }
   });
 